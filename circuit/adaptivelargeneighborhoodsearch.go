@@ -0,0 +1,322 @@
+package circuit
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// DestroyOperator determines which vertices an AdaptiveLargeNeighborhoodSearch removes from its circuit in an iteration.
+type DestroyOperator int
+
+const (
+	// DestroyRandom removes random vertices.
+	DestroyRandom DestroyOperator = iota
+	// DestroyWorst removes the vertices whose removal shortens the circuit the most, with some randomization so that the same vertices are not always removed.
+	DestroyWorst
+	// DestroyRelated removes a random vertex, along with the vertices closest to it (i.e. Shaw removal), so that the repair can reorder that region of the circuit.
+	DestroyRelated
+	// DestroySegment removes a random segment of consecutive vertices.
+	DestroySegment
+)
+
+// RepairOperator determines how an AdaptiveLargeNeighborhoodSearch reinserts the removed vertices into its circuit.
+type RepairOperator int
+
+const (
+	// RepairCheapest reinserts the removed vertices with ClosestGreedy, which inserts the vertex that increases the length of the circuit the least.
+	RepairCheapest RepairOperator = iota
+	// RepairRegret reinserts the removed vertices with DisparityGreedy, which inserts the vertex with the largest difference between its two cheapest insertions (i.e. regret-2 insertion).
+	RepairRegret
+)
+
+// AdaptiveLargeNeighborhoodSearchDefaultMaxRemoved is the default maximum number of vertices that AdaptiveLargeNeighborhoodSearch removes in each iteration.
+const AdaptiveLargeNeighborhoodSearchDefaultMaxRemoved = 30
+
+// alnsMinRemoved is the minimum number of vertices that AdaptiveLargeNeighborhoodSearch removes in each iteration.
+const alnsMinRemoved = 2
+
+// alnsReactionFactor determines how quickly the operator weights react to their scores, from 0 (never) to 1 (each segment's scores replace the weights).
+const alnsReactionFactor = 0.1
+
+// alnsSegmentLength is the number of iterations between updates of the operator weights.
+const alnsSegmentLength = 100
+
+// The scores of the operators used in an iteration, depending on its circuit.
+// Accepting a longer circuit scores higher than accepting a shorter one, to reward operators that diversify the search.
+const (
+	alnsScoreBest     = 33.0
+	alnsScoreBetter   = 9.0
+	alnsScoreAccepted = 13.0
+)
+
+// alnsTemperatureFactor is the initial temperature of AcceptanceAnnealing, relative to the average length of an edge in the initial circuit.
+const alnsTemperatureFactor = 0.05
+
+// alnsWorstRandomness determines how strongly DestroyWorst prefers the vertices whose removal shortens the circuit the most; larger values are less random.
+const alnsWorstRandomness = 3.0
+
+// AdaptiveLargeNeighborhoodSearch implements [adaptive large neighborhood search](https://en.wikipedia.org/wiki/Large_neighborhood_search), which improves a completed circuit by repeatedly removing several vertices and reinserting them.
+// During each iteration (up to "maxIterations" times) this:
+// 1. Selects a destroy operator and a repair operator, with probabilities proportional to their weights.
+// 2. Removes between 2 and "maxRemoved" vertices from the current circuit, with the destroy operator (see DestroyOperator).
+// 3. Reinserts the removed vertices with the repair operator, which reuses the insertion logic of ClosestGreedy or DisparityGreedy, with the remaining circuit as its perimeter (see RepairOperator).
+// 4. Records the circuit if it is the shortest so far, and decides whether to continue from it based on its AcceptanceType (AcceptanceAnnealing by default).
+// 5. Scores the operators based on the result, and every 100 iterations updates the weight of each operator based on its average score in those iterations.
+//
+// GetAttachedVertices and GetLength return the shortest circuit found so far. Circuits with fewer than 5 vertices are too small to destroy and repair, so they are returned unchanged.
+// The operators are random, so SetSeed should be used for consistent results.
+type AdaptiveLargeNeighborhoodSearch struct {
+	acceptance     AcceptanceType
+	best           []model.CircuitVertex
+	bestLength     float64
+	current        []model.CircuitVertex
+	currentLength  float64
+	destroyScores  []float64
+	destroyUses    []int
+	destroyWeights []float64
+	maxIterations  int
+	maxRemoved     int
+	numIterations  int
+	random         *rand.Rand
+	repairScores   []float64
+	repairUses     []int
+	repairWeights  []float64
+	temperature    float64
+	vertices       []model.CircuitVertex
+}
+
+// NewAdaptiveLargeNeighborhoodSearch creates an AdaptiveLargeNeighborhoodSearch circuit that improves the supplied circuit, for the supplied number of iterations.
+// Duplicate references to the same vertex are ignored. Every operator starts with the same weight.
+func NewAdaptiveLargeNeighborhoodSearch(circuit []model.CircuitVertex, maxIterations int) *AdaptiveLargeNeighborhoodSearch {
+	vertices := uniqueVertices(circuit)
+	length := model.Length(vertices)
+	temperature := 0.0
+	if len(vertices) > 0 {
+		temperature = alnsTemperatureFactor * length / float64(len(vertices))
+	}
+	return &AdaptiveLargeNeighborhoodSearch{
+		acceptance:     AcceptanceAnnealing,
+		best:           vertices,
+		bestLength:     length,
+		current:        vertices,
+		currentLength:  length,
+		destroyScores:  make([]float64, 4),
+		destroyUses:    make([]int, 4),
+		destroyWeights: []float64{1.0, 1.0, 1.0, 1.0},
+		maxIterations:  maxIterations,
+		maxRemoved:     AdaptiveLargeNeighborhoodSearchDefaultMaxRemoved,
+		random:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		repairScores:   make([]float64, 2),
+		repairUses:     make([]int, 2),
+		repairWeights:  []float64{1.0, 1.0},
+		temperature:    temperature,
+		vertices:       vertices,
+	}
+}
+
+// NewAdaptiveLargeNeighborhoodSearchFromCircuit completes the supplied circuit, then creates an AdaptiveLargeNeighborhoodSearch circuit that improves it.
+func NewAdaptiveLargeNeighborhoodSearchFromCircuit(circuit model.Circuit, maxIterations int) *AdaptiveLargeNeighborhoodSearch {
+	return NewAdaptiveLargeNeighborhoodSearch(attachAll(circuit), maxIterations)
+}
+
+// FindNextVertexAndEdge returns (nil, nil) once every iteration is complete, otherwise it returns the first vertex in the circuit, which is ignored by Update.
+func (a *AdaptiveLargeNeighborhoodSearch) FindNextVertexAndEdge() (model.CircuitVertex, model.CircuitEdge) {
+	if a.numIterations >= a.maxIterations || len(a.vertices) < 5 {
+		return nil, nil
+	}
+	return a.current[0], nil
+}
+
+// GetAttachedVertices returns the shortest circuit found so far.
+func (a *AdaptiveLargeNeighborhoodSearch) GetAttachedVertices() []model.CircuitVertex {
+	return a.best
+}
+
+// GetDestroyWeights returns the current weight of each destroy operator, indexed by DestroyOperator.
+func (a *AdaptiveLargeNeighborhoodSearch) GetDestroyWeights() []float64 {
+	return append([]float64{}, a.destroyWeights...)
+}
+
+// GetLength returns the length of the shortest circuit found so far.
+func (a *AdaptiveLargeNeighborhoodSearch) GetLength() float64 {
+	return a.bestLength
+}
+
+// GetNumIterations returns the number of iterations that have been completed.
+func (a *AdaptiveLargeNeighborhoodSearch) GetNumIterations() int {
+	return a.numIterations
+}
+
+// GetRepairWeights returns the current weight of each repair operator, indexed by RepairOperator.
+func (a *AdaptiveLargeNeighborhoodSearch) GetRepairWeights() []float64 {
+	return append([]float64{}, a.repairWeights...)
+}
+
+func (a *AdaptiveLargeNeighborhoodSearch) GetUnattachedVertices() map[model.CircuitVertex]bool {
+	return make(map[model.CircuitVertex]bool)
+}
+
+// SetAcceptance sets whether each iteration's circuit is accepted.
+func (a *AdaptiveLargeNeighborhoodSearch) SetAcceptance(acceptance AcceptanceType) {
+	a.acceptance = acceptance
+}
+
+// SetMaxRemoved sets the maximum number of vertices that are removed in each iteration. This is limited to leave at least 3 vertices in the circuit, and values less than 2 are ignored.
+func (a *AdaptiveLargeNeighborhoodSearch) SetMaxRemoved(maxRemoved int) {
+	if maxRemoved >= alnsMinRemoved {
+		a.maxRemoved = maxRemoved
+	}
+}
+
+// SetSeed sets the seed used to select the operators, the vertices to remove, and whether to accept longer circuits. This is to facilitate consistent unit tests.
+func (a *AdaptiveLargeNeighborhoodSearch) SetSeed(seed int64) {
+	a.random = rand.New(rand.NewSource(seed))
+}
+
+// Update completes one iteration, by destroying and repairing the current circuit, then scoring the operators that it used. The supplied vertex and edge are ignored.
+func (a *AdaptiveLargeNeighborhoodSearch) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
+	if a.numIterations >= a.maxIterations || len(a.vertices) < 5 {
+		return
+	}
+	a.numIterations++
+
+	destroy := DestroyOperator(a.selectOperator(a.destroyWeights))
+	repair := RepairOperator(a.selectOperator(a.repairWeights))
+	maxRemoved := a.maxRemoved
+	if maxRemoved > len(a.vertices)-3 {
+		maxRemoved = len(a.vertices) - 3
+	}
+	removed := a.destroy(destroy, alnsMinRemoved+a.random.Intn(maxRemoved-alnsMinRemoved+1))
+
+	// The remaining vertices form the perimeter of the repair circuit, so that it only inserts the removed vertices.
+	remaining := make([]model.CircuitVertex, 0, len(a.current)-len(removed))
+	for _, v := range a.current {
+		if !removed[v] {
+			remaining = append(remaining, v)
+		}
+	}
+	perimeterBuilder := BuildPerimeterFromCircuit(&CompletedCircuit{Circuit: remaining})
+	var repaired model.Circuit
+	if repair == RepairRegret {
+		repaired = NewDisparityGreedy(a.vertices, perimeterBuilder, false)
+	} else {
+		repaired = NewClosestGreedy(a.vertices, perimeterBuilder, false)
+	}
+	circuit := attachAll(repaired)
+	length := repaired.GetLength()
+
+	score := 0.0
+	if length < a.bestLength-model.Threshold {
+		a.best, a.bestLength = circuit, length
+		score = alnsScoreBest
+	}
+	temperature := a.temperature * (1.0 - float64(a.numIterations)/float64(a.maxIterations))
+	if isAccepted(a.acceptance, length, a.currentLength, temperature, a.random) {
+		if score == 0 {
+			score = alnsScoreAccepted
+			if length < a.currentLength-model.Threshold {
+				score = alnsScoreBetter
+			}
+		}
+		a.current, a.currentLength = circuit, length
+	}
+
+	a.destroyScores[destroy] += score
+	a.destroyUses[destroy]++
+	a.repairScores[repair] += score
+	a.repairUses[repair]++
+	if a.numIterations%alnsSegmentLength == 0 {
+		updateOperatorWeights(a.destroyWeights, a.destroyScores, a.destroyUses)
+		updateOperatorWeights(a.repairWeights, a.repairScores, a.repairUses)
+	}
+}
+
+// selectOperator returns the index of a random operator, with probabilities proportional to the supplied weights (i.e. roulette wheel selection).
+func (a *AdaptiveLargeNeighborhoodSearch) selectOperator(weights []float64) int {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return a.random.Intn(len(weights))
+	}
+	target := a.random.Float64() * total
+	for i, w := range weights {
+		if target < w {
+			return i
+		}
+		target -= w
+	}
+	return len(weights) - 1
+}
+
+// updateOperatorWeights moves the weight of each operator that was used in the last segment towards its average score in that segment, then resets the scores and uses for the next segment.
+func updateOperatorWeights(weights []float64, scores []float64, uses []int) {
+	for i := range weights {
+		if uses[i] > 0 {
+			weights[i] = weights[i]*(1.0-alnsReactionFactor) + alnsReactionFactor*scores[i]/float64(uses[i])
+		}
+		scores[i] = 0
+		uses[i] = 0
+	}
+}
+
+// destroy returns the supplied number of vertices to remove from the current circuit, selected by the supplied operator.
+func (a *AdaptiveLargeNeighborhoodSearch) destroy(operator DestroyOperator, numRemoved int) map[model.CircuitVertex]bool {
+	numVertices := len(a.current)
+	removed := make(map[model.CircuitVertex]bool, numRemoved)
+	switch operator {
+	case DestroyWorst:
+		// Sort the vertices by how much removing each one would shorten the circuit, then repeatedly select from the start of the list, biased by alnsWorstRandomness.
+		gains := make([]float64, numVertices)
+		positions := make([]int, numVertices)
+		for i, v := range a.current {
+			prev, next := a.current[(i+numVertices-1)%numVertices], a.current[(i+1)%numVertices]
+			gains[i] = prev.DistanceTo(v) + v.DistanceTo(next) - prev.DistanceTo(next)
+			positions[i] = i
+		}
+		sort.SliceStable(positions, func(i, j int) bool {
+			return gains[positions[i]] > gains[positions[j]]
+		})
+		for len(removed) < numRemoved {
+			index := int(math.Pow(a.random.Float64(), alnsWorstRandomness) * float64(len(positions)))
+			removed[a.current[positions[index]]] = true
+			positions = append(positions[:index], positions[index+1:]...)
+		}
+	case DestroyRelated:
+		// Remove a random vertex, and the vertices closest to it.
+		seed := a.current[a.random.Intn(numVertices)]
+		distances := make([]float64, numVertices)
+		positions := make([]int, numVertices)
+		for i, v := range a.current {
+			distances[i] = seed.DistanceTo(v)
+			positions[i] = i
+		}
+		sort.SliceStable(positions, func(i, j int) bool {
+			return distances[positions[i]] < distances[positions[j]]
+		})
+		removed[seed] = true
+		for _, p := range positions {
+			if len(removed) >= numRemoved {
+				break
+			}
+			removed[a.current[p]] = true
+		}
+	case DestroySegment:
+		start := a.random.Intn(numVertices)
+		for i := 0; i < numRemoved; i++ {
+			removed[a.current[(start+i)%numVertices]] = true
+		}
+	default:
+		for _, p := range a.random.Perm(numVertices)[:numRemoved] {
+			removed[a.current[p]] = true
+		}
+	}
+	return removed
+}
+
+var _ model.Circuit = (*AdaptiveLargeNeighborhoodSearch)(nil)
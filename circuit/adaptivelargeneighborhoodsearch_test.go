@@ -0,0 +1,176 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptiveLargeNeighborhoodSearch(t *testing.T) {
+	assert := assert.New(t)
+
+	// The initial circuit crosses itself, between (0,0)-(10,10) and (10,0)-(0,10).
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(0, 10),
+		model2d.NewVertex2D(5, 0),
+	}
+	c := circuit.NewAdaptiveLargeNeighborhoodSearch(vertices, 20)
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(vertices), c.GetLength(), model.Threshold)
+	assert.Equal([]float64{1.0, 1.0, 1.0, 1.0}, c.GetDestroyWeights())
+	assert.Equal([]float64{1.0, 1.0}, c.GetRepairWeights())
+
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Equal(vertices[0], next)
+	assert.Nil(edge)
+	c.SetSeed(1)
+	for ; next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+
+	assert.Equal(20, c.GetNumIterations())
+	assert.InDelta(40.0, c.GetLength(), model.Threshold)
+	assert.InDelta(40.0, model.Length(c.GetAttachedVertices()), model.Threshold)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+}
+
+func TestAdaptiveLargeNeighborhoodSearch_ShouldMatchOptimalForSmallCircuits(t *testing.T) {
+	assert := assert.New(t)
+
+	for i := 0; i < 5; i++ {
+		vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(9))
+		c := circuit.NewAdaptiveLargeNeighborhoodSearch(vertices, 200)
+		c.SetSeed(int64(i))
+		solver.FindShortestPathCircuit(c)
+
+		_, optimalLength := solver.FindShortestPathNPHeap(vertices)
+		assert.Len(c.GetAttachedVertices(), len(vertices))
+		assert.Equal(200, c.GetNumIterations())
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+		assert.InDelta(optimalLength, c.GetLength(), model.Threshold)
+	}
+}
+
+func TestAdaptiveLargeNeighborhoodSearch_ShouldBeConsistentWithSeed(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(200))
+	initialLength := model.Length(vertices)
+
+	for _, acceptance := range []circuit.AcceptanceType{circuit.AcceptanceBetter, circuit.AcceptanceRandomWalk, circuit.AcceptanceAnnealing} {
+		var expected []model.CircuitVertex
+		for i := 0; i < 2; i++ {
+			c := circuit.NewAdaptiveLargeNeighborhoodSearch(vertices, 100)
+			c.SetAcceptance(acceptance)
+			c.SetSeed(3)
+			solver.FindShortestPathCircuit(c)
+			assert.Equal(100, c.GetNumIterations())
+			assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+			// The best circuit is retained, so the iterations can only shorten the circuit.
+			assert.LessOrEqual(c.GetLength(), initialLength+model.Threshold)
+			if expected == nil {
+				expected = c.GetAttachedVertices()
+			} else {
+				assert.Equal(expected, c.GetAttachedVertices())
+			}
+		}
+	}
+}
+
+func TestAdaptiveLargeNeighborhoodSearch_ShouldUpdateWeights(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(100))
+	c := circuit.NewAdaptiveLargeNeighborhoodSearch(vertices, 99)
+	c.SetMaxRemoved(10)
+	c.SetSeed(5)
+	solver.FindShortestPathCircuit(c)
+
+	// The weights are only updated at the end of each segment of 100 iterations.
+	assert.Equal([]float64{1.0, 1.0, 1.0, 1.0}, c.GetDestroyWeights())
+	assert.Equal([]float64{1.0, 1.0}, c.GetRepairWeights())
+
+	c = circuit.NewAdaptiveLargeNeighborhoodSearch(vertices, 100)
+	c.SetMaxRemoved(10)
+	c.SetSeed(5)
+	solver.FindShortestPathCircuit(c)
+
+	// Each iteration from a random initial circuit is likely to improve it, so every operator's score should exceed its initial weight.
+	for _, w := range c.GetDestroyWeights() {
+		assert.Greater(w, 1.0)
+	}
+	for _, w := range c.GetRepairWeights() {
+		assert.Greater(w, 1.0)
+	}
+	assert.Less(c.GetLength(), model.Length(vertices))
+}
+
+func TestAdaptiveLargeNeighborhoodSearch_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model3d.GenerateVertices(100)
+	initialLength := model.Length(vertices)
+	c := circuit.NewAdaptiveLargeNeighborhoodSearch(vertices, 50)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+}
+
+func TestAdaptiveLargeNeighborhoodSearch_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	// The graph and the search are seeded, since a random graph's initial circuit is occasionally already as short as 30 iterations can make it.
+	seed := int64(1)
+	gen := &graph.GraphGenerator{
+		MaxEdges:    5,
+		MinEdges:    2,
+		NumVertices: 30,
+		Seed:        &seed,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+	initialLength := model.Length(vertices)
+
+	c := circuit.NewAdaptiveLargeNeighborhoodSearch(vertices, 30)
+	c.SetMaxRemoved(5)
+	c.SetSeed(1)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Equal(30, c.GetNumIterations())
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+}
+
+func TestAdaptiveLargeNeighborhoodSearch_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	c := circuit.NewAdaptiveLargeNeighborhoodSearch([]model.CircuitVertex{}, 10)
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+	assert.Len(c.GetAttachedVertices(), 0)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(1, 2),
+		model2d.NewVertex2D(3, 2),
+		model2d.NewVertex2D(1, 5),
+		model2d.NewVertex2D(3, 5),
+	}
+	c = circuit.NewAdaptiveLargeNeighborhoodSearch(append(vertices, vertices[0]), 10)
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.Equal(0, c.GetNumIterations())
+}
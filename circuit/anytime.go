@@ -0,0 +1,142 @@
+package circuit
+
+import (
+	"sync"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// Anytime wraps a circuit so that its best circuit so far can be read by other goroutines, via Snapshot, while one goroutine solves it.
+// Circuits such as GeneticAlgorithm and SimulatedAnnealing return the slice that they mutate in Update, so reading them directly while updating them is a data race.
+// Anytime serializes access to the wrapped circuit, and retains its own copy of the shortest completed circuit, which is never modified once created.
+//
+// Only one goroutine should update the Anytime, the wrapped circuit must not be used directly once it is wrapped.
+// Each update also calls GetLength and GetUnattachedVertices on the wrapped circuit, and copies the circuit whenever a shorter completed circuit is found.
+// Any call to the wrapped circuit holds the write lock, since even its getters may modify it (e.g. ClosestGreedy pops its heap in FindNextVertexAndEdge, and the local searches cache their circuits),
+// so only snapshots of a completed circuit can be read concurrently.
+type Anytime struct {
+	best       []model.CircuitVertex
+	bestLength float64
+	circuit    model.Circuit
+	mutex      sync.RWMutex
+	numUpdates int
+}
+
+// AnytimeSnapshot is an immutable copy of an Anytime's best circuit, at the time the snapshot was taken.
+type AnytimeSnapshot struct {
+	// Circuit is the shortest completed circuit found so far or, if no circuit has been completed yet, the current partial circuit.
+	Circuit []model.CircuitVertex
+	// Length is the length of the circuit in the snapshot.
+	Length float64
+	// NumUnattached is the number of vertices missing from the circuit in the snapshot, which is 0 once a completed circuit has been found.
+	NumUnattached int
+	// NumUpdates is the number of times the Anytime has been updated.
+	NumUpdates int
+}
+
+// NewAnytime wraps the supplied circuit, so that its best circuit can be read while it is being solved.
+func NewAnytime(c model.Circuit) *Anytime {
+	a := &Anytime{
+		circuit: c,
+	}
+	a.updateBest()
+	return a
+}
+
+// FindNextVertexAndEdge returns the wrapped circuit's next vertex and edge. This holds the write lock, since the wrapped circuit may change its state when finding them.
+func (a *Anytime) FindNextVertexAndEdge() (model.CircuitVertex, model.CircuitEdge) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.circuit.FindNextVertexAndEdge()
+}
+
+// GetAttachedVertices returns a copy of the circuit in the current snapshot.
+func (a *Anytime) GetAttachedVertices() []model.CircuitVertex {
+	return a.Snapshot().Circuit
+}
+
+// GetLength returns the length of the circuit in the current snapshot.
+func (a *Anytime) GetLength() float64 {
+	return a.Snapshot().Length
+}
+
+// GetUnattachedVertices returns a copy of the wrapped circuit's unattached vertices, or an empty map once a completed circuit has been found.
+func (a *Anytime) GetUnattachedVertices() map[model.CircuitVertex]bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	unattached := make(map[model.CircuitVertex]bool)
+	if a.best == nil {
+		for v := range a.circuit.GetUnattachedVertices() {
+			unattached[v] = true
+		}
+	}
+	return unattached
+}
+
+// Snapshot returns a copy of the best circuit found so far, and its length. This is safe to call concurrently with Update.
+func (a *Anytime) Snapshot() *AnytimeSnapshot {
+	if snapshot := a.snapshotBest(); snapshot != nil {
+		return snapshot
+	}
+
+	// No circuit has been completed yet, so the snapshot is read from the wrapped circuit, which requires the write lock.
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.best != nil {
+		return a.copyBest()
+	}
+	attached := a.circuit.GetAttachedVertices()
+	snapshot := &AnytimeSnapshot{
+		Circuit:       make([]model.CircuitVertex, len(attached)),
+		Length:        a.circuit.GetLength(),
+		NumUnattached: len(a.circuit.GetUnattachedVertices()),
+		NumUpdates:    a.numUpdates,
+	}
+	copy(snapshot.Circuit, attached)
+	return snapshot
+}
+
+// snapshotBest returns a copy of the best completed circuit, or nil if no circuit has been completed yet. This only holds the read lock, so concurrent snapshots do not block each other.
+func (a *Anytime) snapshotBest() *AnytimeSnapshot {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if a.best == nil {
+		return nil
+	}
+	return a.copyBest()
+}
+
+// copyBest returns a copy of the best completed circuit. The caller must hold either lock.
+func (a *Anytime) copyBest() *AnytimeSnapshot {
+	snapshot := &AnytimeSnapshot{
+		Circuit:    make([]model.CircuitVertex, len(a.best)),
+		Length:     a.bestLength,
+		NumUpdates: a.numUpdates,
+	}
+	copy(snapshot.Circuit, a.best)
+	return snapshot
+}
+
+// Update updates the wrapped circuit, then retains a copy of its circuit if it is completed and shorter than the best circuit so far.
+func (a *Anytime) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.circuit.Update(vertexToAdd, edgeToSplit)
+	a.numUpdates++
+	a.updateBest()
+}
+
+// updateBest copies the wrapped circuit if it is completed and shorter than the best circuit so far. The caller must hold the write lock, or have exclusive access to the Anytime.
+func (a *Anytime) updateBest() {
+	if len(a.circuit.GetUnattachedVertices()) > 0 {
+		return
+	}
+	if length := a.circuit.GetLength(); a.best == nil || length < a.bestLength {
+		attached := a.circuit.GetAttachedVertices()
+		a.best = make([]model.CircuitVertex, len(attached))
+		copy(a.best, attached)
+		a.bestLength = length
+	}
+}
+
+var _ model.Circuit = (*Anytime)(nil)
@@ -0,0 +1,118 @@
+package circuit_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnytime_ClosestGreedy(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(30))
+	greedy := circuit.NewClosestGreedy(vertices, model2d.BuildPerimiter, false)
+	numUnattached := len(greedy.GetUnattachedVertices())
+	c := circuit.NewAnytime(greedy)
+
+	snapshot := c.Snapshot()
+	assert.Equal(numUnattached, snapshot.NumUnattached)
+	assert.Equal(0, snapshot.NumUpdates)
+	assert.Len(snapshot.Circuit, len(vertices)-numUnattached)
+	assert.Len(c.GetUnattachedVertices(), numUnattached)
+
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+
+	snapshot = c.Snapshot()
+	assert.Equal(0, snapshot.NumUnattached)
+	assert.Equal(numUnattached, snapshot.NumUpdates)
+	assert.Equal(greedy.GetAttachedVertices(), snapshot.Circuit)
+	assert.InDelta(greedy.GetLength(), snapshot.Length, model.Threshold)
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.Equal(snapshot.Circuit, c.GetAttachedVertices())
+	assert.Equal(snapshot.Length, c.GetLength())
+}
+
+func TestAnytime_ShouldRetainBestCircuit(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(30))
+	annealing := circuit.NewSimulatedAnnealing(vertices, 5000, false)
+	annealing.SetSeed(7)
+	c := circuit.NewAnytime(annealing)
+
+	shortest := annealing.GetLength()
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+		if annealing.GetLength() < shortest {
+			shortest = annealing.GetLength()
+		}
+	}
+
+	snapshot := c.Snapshot()
+	assert.Equal(5000, snapshot.NumUpdates)
+	assert.InDelta(shortest, snapshot.Length, model.Threshold)
+	assert.InDelta(model.Length(snapshot.Circuit), snapshot.Length, model.Threshold)
+	assert.LessOrEqual(snapshot.Length, annealing.GetLength())
+}
+
+func TestAnytime_ShouldAllowConcurrentSnapshots(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+	c := circuit.NewAnytime(circuit.NewSimulatedAnnealing(vertices, 20000, false))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+			c.Update(next, edge)
+		}
+	}()
+
+	previous := c.Snapshot()
+	for done := false; !done; {
+		snapshot := c.Snapshot()
+		assert.Len(snapshot.Circuit, len(vertices))
+		assert.InDelta(model.Length(snapshot.Circuit), snapshot.Length, model.Threshold)
+		assert.LessOrEqual(snapshot.Length, previous.Length)
+		assert.GreaterOrEqual(snapshot.NumUpdates, previous.NumUpdates)
+		// Modifying the snapshot must not affect the Anytime.
+		snapshot.Circuit[0], snapshot.Circuit[1] = snapshot.Circuit[1], snapshot.Circuit[0]
+		done = snapshot.NumUpdates == 20000
+		previous = c.Snapshot()
+	}
+	wg.Wait()
+}
+
+func TestAnytime_ShouldAllowConcurrentSnapshotsOfPartialCircuits(t *testing.T) {
+	assert := assert.New(t)
+
+	// Snapshots of a partial circuit read the wrapped circuit, which FindNextVertexAndEdge and Update modify (e.g. ClosestGreedy pops its heap), so they must be serialized.
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(300))
+	c := circuit.NewAnytime(circuit.NewClosestGreedy(vertices, model2d.BuildPerimiter, false))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+			c.Update(next, edge)
+		}
+	}()
+
+	for done := false; !done; {
+		snapshot := c.Snapshot()
+		assert.Len(snapshot.Circuit, len(vertices)-snapshot.NumUnattached)
+		assert.LessOrEqual(len(c.GetUnattachedVertices()), snapshot.NumUnattached)
+		done = snapshot.NumUnattached == 0
+	}
+	wg.Wait()
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+}
@@ -0,0 +1,281 @@
+package circuit
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+const (
+	TemperatureFunctionGeometric = "GEOMETRIC"
+	TemperatureFunctionLinear    = "LINEAR"
+)
+
+var temperatureFunctions = map[string]func(currentIteration float64, maxIterations float64) float64{
+	TemperatureFunctionGeometric: CalculateTemperatureGeometric,
+	TemperatureFunctionLinear:    CalculateTemperatureLinear,
+}
+var temperatureFunctionsMutex sync.RWMutex
+
+// RegisterTemperatureFunction associates a custom temperature function with an id, so that a SimulatedAnnealing using that function can be checkpointed and restored.
+// The linear and geometric temperature functions in this package are registered by default.
+func RegisterTemperatureFunction(id string, temperatureFunction func(currentIteration float64, maxIterations float64) float64) {
+	temperatureFunctionsMutex.Lock()
+	defer temperatureFunctionsMutex.Unlock()
+	temperatureFunctions[id] = temperatureFunction
+}
+
+// getTemperatureFunction returns the temperature function registered with the supplied id, or nil if there is no such function.
+func getTemperatureFunction(id string) func(currentIteration float64, maxIterations float64) float64 {
+	temperatureFunctionsMutex.RLock()
+	defer temperatureFunctionsMutex.RUnlock()
+	return temperatureFunctions[id]
+}
+
+// getTemperatureFunctionId returns the id of the supplied temperature function, or an error if it has not been registered.
+// Functions cannot be compared directly in Go, so this compares the address of each function's code.
+func getTemperatureFunctionId(temperatureFunction func(currentIteration float64, maxIterations float64) float64) (string, error) {
+	temperatureFunctionsMutex.RLock()
+	defer temperatureFunctionsMutex.RUnlock()
+	target := reflect.ValueOf(temperatureFunction).Pointer()
+	for id, f := range temperatureFunctions {
+		if reflect.ValueOf(f).Pointer() == target {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("temperature function is not registered, see RegisterTemperatureFunction")
+}
+
+// countingSource wraps the standard seeded random source, and counts how many values it has produced.
+// The state of the standard source cannot be exported, so this allows the state to be recreated by reseeding and discarding the same number of values.
+// It produces exactly the same values as rand.NewSource(seed), and creating a checkpoint does not change its state.
+type countingSource struct {
+	numDraws uint64
+	seed     int64
+	source   rand.Source64
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{
+		numDraws: 0,
+		seed:     seed,
+		source:   rand.NewSource(seed).(rand.Source64),
+	}
+}
+
+// newRandom creates a random number generator, along with the source that tracks its state for checkpoints.
+func newRandom(seed int64) (*rand.Rand, *countingSource) {
+	source := newCountingSource(seed)
+	return rand.New(source), source
+}
+
+func (s *countingSource) Int63() int64 {
+	s.numDraws++
+	return s.source.Int63()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.numDraws = 0
+	s.seed = seed
+	s.source.Seed(seed)
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.numDraws++
+	return s.source.Uint64()
+}
+
+// RandomCheckpoint is the serializable state of a random number generator.
+type RandomCheckpoint struct {
+	NumDraws uint64 `json:"numDraws"`
+	Seed     int64  `json:"seed"`
+}
+
+func (s *countingSource) checkpoint() *RandomCheckpoint {
+	return &RandomCheckpoint{
+		NumDraws: s.numDraws,
+		Seed:     s.seed,
+	}
+}
+
+// restoreRandom recreates a random number generator in the state it was in when the checkpoint was created.
+// Its complexity is O(d), where d is the number of values drawn prior to the checkpoint.
+func restoreRandom(checkpoint *RandomCheckpoint) (*rand.Rand, *countingSource, error) {
+	if checkpoint == nil {
+		return nil, nil, fmt.Errorf("checkpoint must contain the random number generator state")
+	}
+	random, source := newRandom(checkpoint.Seed)
+	for ; source.numDraws < checkpoint.NumDraws; source.numDraws++ {
+		source.source.Uint64()
+	}
+	return random, source, nil
+}
+
+// SimulatedAnnealingCheckpoint is the serializable state of a SimulatedAnnealing, with vertices referenced by their index in the original input.
+type SimulatedAnnealingCheckpoint struct {
+	Circuit              []int             `json:"circuit"`
+	FarthestDistance     float64           `json:"farthestDistance"`
+	MaxIterations        float64           `json:"maxIterations"`
+	NumIterations        float64           `json:"numIterations"`
+	PreferCloseNeighbors bool              `json:"preferCloseNeighbors"`
+	Random               *RandomCheckpoint `json:"random"`
+	TemperatureFunction  string            `json:"temperatureFunction"`
+}
+
+// Checkpoint captures the full state of the SimulatedAnnealing, so that it can be serialized (e.g. to JSON) and later restored with RestoreSimulatedAnnealing.
+// It does not modify the SimulatedAnnealing, so a checkpointed run produces the same results as an identically seeded run that was not checkpointed.
+// The supplied vertices must be the original input, in its original order; since SimulatedAnnealing reorders the array it is supplied, callers should retain a copy of that array.
+// An error is returned if the circuit contains a vertex that is not in the supplied vertices, or if the temperature function has not been registered with RegisterTemperatureFunction.
+func (s *SimulatedAnnealing) Checkpoint(vertices []model.CircuitVertex) (*SimulatedAnnealingCheckpoint, error) {
+	temperatureFunction, err := getTemperatureFunctionId(s.temperatureFunction)
+	if err != nil {
+		return nil, err
+	}
+	circuit, err := toIndices(s.circuit, vertices)
+	if err != nil {
+		return nil, err
+	}
+	return &SimulatedAnnealingCheckpoint{
+		Circuit:              circuit,
+		FarthestDistance:     s.farthestDistance,
+		MaxIterations:        s.maxIterations,
+		NumIterations:        s.numIterations,
+		PreferCloseNeighbors: s.preferCloseNeighbors,
+		Random:               s.source.checkpoint(),
+		TemperatureFunction:  temperatureFunction,
+	}, nil
+}
+
+// RestoreSimulatedAnnealing recreates a SimulatedAnnealing from a checkpoint, so that continuing to update it produces exactly the same results as the SimulatedAnnealing that was checkpointed.
+// The supplied vertices must be the same vertices, in the same order, that were supplied to Checkpoint.
+func RestoreSimulatedAnnealing(checkpoint *SimulatedAnnealingCheckpoint, vertices []model.CircuitVertex) (*SimulatedAnnealing, error) {
+	temperatureFunction := getTemperatureFunction(checkpoint.TemperatureFunction)
+	if temperatureFunction == nil {
+		return nil, fmt.Errorf("temperature function %q is not registered, see RegisterTemperatureFunction", checkpoint.TemperatureFunction)
+	}
+	circuit, err := fromIndices(checkpoint.Circuit, vertices)
+	if err != nil {
+		return nil, err
+	}
+	random, source, err := restoreRandom(checkpoint.Random)
+	if err != nil {
+		return nil, err
+	}
+	return &SimulatedAnnealing{
+		circuit:              circuit,
+		farthestDistance:     checkpoint.FarthestDistance,
+		maxIterations:        checkpoint.MaxIterations,
+		numIterations:        checkpoint.NumIterations,
+		preferCloseNeighbors: checkpoint.PreferCloseNeighbors,
+		random:               random,
+		source:               source,
+		temperatureFunction:  temperatureFunction,
+	}, nil
+}
+
+// GeneticAlgorithmCheckpoint is the serializable state of a GeneticAlgorithm, with vertices referenced by their index in the original input.
+type GeneticAlgorithmCheckpoint struct {
+	// Generation contains each circuit in the current generation, ordered from shortest to longest.
+	Generation    [][]int           `json:"generation"`
+	MaxCrossovers int               `json:"maxCrossovers"`
+	MaxIterations int               `json:"maxIterations"`
+	MutationRate  float64           `json:"mutationRate"`
+	NumChildren   int               `json:"numChildren"`
+	NumIterations int               `json:"numIterations"`
+	NumParents    int               `json:"numParents"`
+	Random        *RandomCheckpoint `json:"random"`
+}
+
+// Checkpoint captures the full state of the GeneticAlgorithm, so that it can be serialized (e.g. to JSON) and later restored with RestoreGeneticAlgorithm.
+// It does not modify the GeneticAlgorithm, so a checkpointed run produces the same results as an identically seeded run that was not checkpointed.
+// The supplied vertices must be the original input, in its original order.
+// An error is returned if any circuit contains a vertex that is not in the supplied vertices.
+func (g *GeneticAlgorithm) Checkpoint(vertices []model.CircuitVertex) (*GeneticAlgorithmCheckpoint, error) {
+	generation := make([][]int, len(g.currentGeneration))
+	for i, current := range g.currentGeneration {
+		circuit, err := toIndices(current.circuit, vertices)
+		if err != nil {
+			return nil, err
+		}
+		generation[i] = circuit
+	}
+	return &GeneticAlgorithmCheckpoint{
+		Generation:    generation,
+		MaxCrossovers: g.maxCrossovers,
+		MaxIterations: g.maxIterations,
+		MutationRate:  g.mutationRate,
+		NumChildren:   g.numChildren,
+		NumIterations: g.numIterations,
+		NumParents:    g.numParents,
+		Random:        g.source.checkpoint(),
+	}, nil
+}
+
+// RestoreGeneticAlgorithm recreates a GeneticAlgorithm from a checkpoint, so that continuing to update it produces exactly the same results as the GeneticAlgorithm that was checkpointed.
+// The supplied vertices must be the same vertices, in the same order, that were supplied to Checkpoint.
+func RestoreGeneticAlgorithm(checkpoint *GeneticAlgorithmCheckpoint, vertices []model.CircuitVertex) (*GeneticAlgorithm, error) {
+	if len(checkpoint.Generation) != checkpoint.NumParents {
+		return nil, fmt.Errorf("checkpoint must contain %d circuits in its generation, found %d", checkpoint.NumParents, len(checkpoint.Generation))
+	}
+	generation := make([]*geneticCircuit, len(checkpoint.Generation))
+	for i, indices := range checkpoint.Generation {
+		circuit, err := fromIndices(indices, vertices)
+		if err != nil {
+			return nil, err
+		}
+		generation[i] = &geneticCircuit{
+			circuit: circuit,
+		}
+		generation[i].setLength()
+	}
+	random, source, err := restoreRandom(checkpoint.Random)
+	if err != nil {
+		return nil, err
+	}
+	// The generation is not re-sorted, since it was sorted when checkpointed, and re-sorting could reorder circuits of equal length.
+	return &GeneticAlgorithm{
+		currentGeneration: generation,
+		maxCrossovers:     checkpoint.MaxCrossovers,
+		maxIterations:     checkpoint.MaxIterations,
+		mutationRate:      checkpoint.MutationRate,
+		numChildren:       checkpoint.NumChildren,
+		numIterations:     checkpoint.NumIterations,
+		numParents:        checkpoint.NumParents,
+		random:            random,
+		source:            source,
+	}, nil
+}
+
+// toIndices converts a circuit into the index of each of its vertices in the supplied array of vertices.
+func toIndices(circuit []model.CircuitVertex, vertices []model.CircuitVertex) ([]int, error) {
+	indices := make(map[model.CircuitVertex]int, len(vertices))
+	for i, v := range vertices {
+		if _, isDuplicate := indices[v]; !isDuplicate {
+			indices[v] = i
+		}
+	}
+	result := make([]int, len(circuit))
+	for i, v := range circuit {
+		index, okay := indices[v]
+		if !okay {
+			return nil, fmt.Errorf("circuit vertex %v is not in the supplied vertices", v)
+		}
+		result[i] = index
+	}
+	return result, nil
+}
+
+// fromIndices converts an array of indices into a circuit, using the supplied array of vertices.
+func fromIndices(indices []int, vertices []model.CircuitVertex) ([]model.CircuitVertex, error) {
+	circuit := make([]model.CircuitVertex, len(indices))
+	for i, index := range indices {
+		if index < 0 || index >= len(vertices) {
+			return nil, fmt.Errorf("vertex index %d is out of range [0,%d)", index, len(vertices))
+		}
+		circuit[i] = vertices[index]
+	}
+	return circuit, nil
+}
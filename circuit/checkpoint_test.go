@@ -0,0 +1,189 @@
+package circuit_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpoint_SimulatedAnnealing(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, preferCloseNeighbors := range []bool{false, true} {
+		vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(30))
+		original := append([]model.CircuitVertex{}, vertices...)
+
+		c := circuit.NewSimulatedAnnealing(vertices, 2000, preferCloseNeighbors)
+		c.SetSeed(123)
+		c.SetTemperatureFunction(circuit.CalculateTemperatureGeometric)
+		for i := 0; i < 1000; i++ {
+			c.Update(c.FindNextVertexAndEdge())
+		}
+
+		checkpoint, err := c.Checkpoint(original)
+		assert.Nil(err)
+		assert.Equal(circuit.TemperatureFunctionGeometric, checkpoint.TemperatureFunction)
+		assert.Equal(1000.0, checkpoint.NumIterations)
+		assert.Equal(int64(123), checkpoint.Random.Seed)
+		assert.Greater(checkpoint.Random.NumDraws, uint64(1000))
+
+		checkpointJson, err := json.Marshal(checkpoint)
+		assert.Nil(err)
+		var restoredCheckpoint *circuit.SimulatedAnnealingCheckpoint
+		assert.Nil(json.Unmarshal(checkpointJson, &restoredCheckpoint))
+
+		restored, err := circuit.RestoreSimulatedAnnealing(restoredCheckpoint, original)
+		assert.Nil(err)
+		assert.Equal(c.GetAttachedVertices(), restored.GetAttachedVertices())
+
+		// Both circuits should produce identical results for the remainder of the run.
+		for next, _ := c.FindNextVertexAndEdge(); next != nil; next, _ = c.FindNextVertexAndEdge() {
+			c.Update(next, nil)
+			restored.Update(restored.FindNextVertexAndEdge())
+			assert.Equal(c.GetAttachedVertices(), restored.GetAttachedVertices())
+		}
+		next, _ := restored.FindNextVertexAndEdge()
+		assert.Nil(next)
+		assert.Equal(c.GetLength(), restored.GetLength())
+	}
+}
+
+func TestCheckpoint_ShouldNotChangeTheRun(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(30))
+	annealing := circuit.NewSimulatedAnnealing(append([]model.CircuitVertex{}, vertices...), 2000, true)
+	annealing.SetSeed(123)
+	checkpointed := circuit.NewSimulatedAnnealing(append([]model.CircuitVertex{}, vertices...), 2000, true)
+	checkpointed.SetSeed(123)
+	for i := 0; i < 2000; i++ {
+		if i%100 == 0 {
+			_, err := checkpointed.Checkpoint(vertices)
+			assert.Nil(err)
+		}
+		annealing.Update(annealing.FindNextVertexAndEdge())
+		checkpointed.Update(checkpointed.FindNextVertexAndEdge())
+	}
+	assert.Equal(annealing.GetAttachedVertices(), checkpointed.GetAttachedVertices())
+
+	// The initial generation is created before the seed can be set, so both runs are restored from the same initial state.
+	initial := circuit.NewGeneticAlgorithmWithPerimeterBuilder(vertices, model2d.BuildPerimiter, 10, 15, 30)
+	initial.SetSeed(42)
+	initialCheckpoint, err := initial.Checkpoint(vertices)
+	assert.Nil(err)
+	genetic, err := circuit.RestoreGeneticAlgorithm(initialCheckpoint, vertices)
+	assert.Nil(err)
+	checkpointedGenetic, err := circuit.RestoreGeneticAlgorithm(initialCheckpoint, vertices)
+	assert.Nil(err)
+	for i := 0; i < 30; i++ {
+		_, err := checkpointedGenetic.Checkpoint(vertices)
+		assert.Nil(err)
+		genetic.Update(genetic.FindNextVertexAndEdge())
+		checkpointedGenetic.Update(checkpointedGenetic.FindNextVertexAndEdge())
+	}
+	assert.Equal(genetic.GetAttachedVertices(), checkpointedGenetic.GetAttachedVertices())
+}
+
+func TestCheckpoint_SimulatedAnnealing_CustomTemperatureFunction(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(10))
+	custom := func(currentIteration float64, maxIterations float64) float64 {
+		return 0.5
+	}
+	unregistered := func(currentIteration float64, maxIterations float64) float64 {
+		return 0.25
+	}
+	c := circuit.NewSimulatedAnnealing(append([]model.CircuitVertex{}, vertices...), 100, false)
+	c.SetTemperatureFunction(unregistered)
+
+	checkpoint, err := c.Checkpoint(vertices)
+	assert.Nil(checkpoint)
+	assert.EqualError(err, "temperature function is not registered, see RegisterTemperatureFunction")
+
+	circuit.RegisterTemperatureFunction("TEST_CUSTOM", custom)
+	c.SetTemperatureFunction(custom)
+	checkpoint, err = c.Checkpoint(vertices)
+	assert.Nil(err)
+	assert.Equal("TEST_CUSTOM", checkpoint.TemperatureFunction)
+
+	checkpoint.TemperatureFunction = "TEST_UNKNOWN"
+	restored, err := circuit.RestoreSimulatedAnnealing(checkpoint, vertices)
+	assert.Nil(restored)
+	assert.EqualError(err, `temperature function "TEST_UNKNOWN" is not registered, see RegisterTemperatureFunction`)
+}
+
+func TestCheckpoint_SimulatedAnnealing_InvalidVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(10))
+	c := circuit.NewSimulatedAnnealing(append([]model.CircuitVertex{}, vertices...), 100, false)
+
+	checkpoint, err := c.Checkpoint(vertices[1:])
+	assert.Nil(checkpoint)
+	assert.NotNil(err)
+
+	checkpoint, err = c.Checkpoint(vertices)
+	assert.Nil(err)
+
+	restored, err := circuit.RestoreSimulatedAnnealing(checkpoint, vertices[1:])
+	assert.Nil(restored)
+	assert.NotNil(err)
+
+	checkpoint.Random = nil
+	restored, err = circuit.RestoreSimulatedAnnealing(checkpoint, vertices)
+	assert.Nil(restored)
+	assert.EqualError(err, "checkpoint must contain the random number generator state")
+}
+
+func TestCheckpoint_GeneticAlgorithm(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(25))
+	c := circuit.NewGeneticAlgorithmWithPerimeterBuilder(vertices, model2d.BuildPerimiter, 10, 15, 60)
+	c.SetSeed(42)
+	c.SetMutationRate(0.2)
+	c.SetMaxCrossovers(4)
+	for i := 0; i < 30; i++ {
+		c.Update(c.FindNextVertexAndEdge())
+	}
+
+	checkpoint, err := c.Checkpoint(vertices)
+	assert.Nil(err)
+	assert.Len(checkpoint.Generation, 10)
+	assert.Equal(4, checkpoint.MaxCrossovers)
+	assert.Equal(60, checkpoint.MaxIterations)
+	assert.Equal(0.2, checkpoint.MutationRate)
+	assert.Equal(15, checkpoint.NumChildren)
+	assert.Equal(30, checkpoint.NumIterations)
+	assert.Equal(10, checkpoint.NumParents)
+	assert.Equal(int64(42), checkpoint.Random.Seed)
+
+	checkpointJson, err := json.Marshal(checkpoint)
+	assert.Nil(err)
+	var restoredCheckpoint *circuit.GeneticAlgorithmCheckpoint
+	assert.Nil(json.Unmarshal(checkpointJson, &restoredCheckpoint))
+
+	restored, err := circuit.RestoreGeneticAlgorithm(restoredCheckpoint, vertices)
+	assert.Nil(err)
+	assert.Equal(c.GetAttachedVertices(), restored.GetAttachedVertices())
+	assert.Equal(c.GetLength(), restored.GetLength())
+
+	for next, _ := c.FindNextVertexAndEdge(); next != nil; next, _ = c.FindNextVertexAndEdge() {
+		c.Update(next, nil)
+		restored.Update(restored.FindNextVertexAndEdge())
+		assert.Equal(c.GetAttachedVertices(), restored.GetAttachedVertices())
+		assert.Equal(c.GetLength(), restored.GetLength())
+	}
+	next, _ := restored.FindNextVertexAndEdge()
+	assert.Nil(next)
+
+	restoredCheckpoint.Generation = restoredCheckpoint.Generation[1:]
+	restored, err = circuit.RestoreGeneticAlgorithm(restoredCheckpoint, vertices)
+	assert.Nil(restored)
+	assert.EqualError(err, "checkpoint must contain 10 circuits in its generation, found 9")
+}
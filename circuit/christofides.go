@@ -0,0 +1,132 @@
+package circuit
+
+import (
+	"fmt"
+
+	"github.com/heustis/tsp-solver-go/matching"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/mst"
+)
+
+// Christofides implements the [Christofides algorithm](https://en.wikipedia.org/wiki/Christofides_algorithm), which produces a circuit that is at most 1.5 times the length of the optimal circuit, for metric distances.
+// Distances between 2D and 3D vertices are metric, as are the distances between graph vertices, since those are the shortest paths between the vertices.
+// The guarantee only applies to symmetric distances; for asymmetric graphs the matching uses the distance from the lower-indexed vertex to the higher-indexed vertex.
+//
+// When it is created, the Christofides circuit:
+// 1. Computes a minimum spanning tree of the vertices, see mst.NewTree.
+// 2. Computes the minimum-weight perfect matching of the vertices that have an odd degree in the tree, using the blossom algorithm.
+// 3. Combines the tree and matching into a multigraph in which every vertex has an even degree, and computes an Euler tour of it, starting from the first vertex.
+// 4. Shortcuts the Euler tour, by skipping vertices that were already visited, to produce the circuit.
+//
+// The first vertex of the circuit is attached when this is created, then each call to FindNextVertexAndEdge and Update attaches the next vertex of that circuit, so that the algorithm can be observed and stopped like the other algorithms.
+//
+// This is O(n^3), due to the matching, although only the vertices with an odd degree in the tree are matched, which is typically less than half of the vertices.
+// The matching also uses O(n^2) memory, so this is limited to ChristofidesMaxVertices vertices.
+type Christofides struct {
+	*tourCircuit
+	matching []int
+	tree     *mst.Tree
+}
+
+// ChristofidesMaxVertices is the largest number of unique vertices that NewChristofides accepts.
+// Matching 1,000 odd-degree vertices, which is typical for this many vertices, takes approximately 10 seconds.
+const ChristofidesMaxVertices = 2000
+
+// NewChristofides creates a Christofides circuit, and computes the order in which it will attach the supplied vertices.
+// Duplicate references to the same vertex are ignored.
+// This panics if there are more than ChristofidesMaxVertices unique vertices, rather than computing the matching for an unbounded time; use a faster construction (e.g. GreedyEdge) for more vertices.
+func NewChristofides(vertices []model.CircuitVertex) *Christofides {
+	tree := mst.NewTree(vertices)
+	unique := tree.GetVertices()
+	if len(unique) > ChristofidesMaxVertices {
+		panic(fmt.Errorf("christofides supports at most %d vertices, found %d", ChristofidesMaxVertices, len(unique)))
+	}
+
+	adjacency := tree.GetAdjacency()
+	odd := []int{}
+	for i, neighbors := range adjacency {
+		if len(neighbors)%2 == 1 {
+			odd = append(odd, i)
+		}
+	}
+	mate := matching.MinWeightPerfectMatching(len(odd), func(i int, j int) float64 {
+		return unique[odd[i]].DistanceTo(unique[odd[j]])
+	})
+	matched := make([]int, len(unique))
+	for i := range matched {
+		matched[i] = -1
+	}
+	for i, j := range mate {
+		if j >= 0 {
+			matched[odd[i]] = odd[j]
+			// Each matched pair is only added to the multigraph once.
+			if i < j {
+				adjacency[odd[i]] = append(adjacency[odd[i]], odd[j])
+				adjacency[odd[j]] = append(adjacency[odd[j]], odd[i])
+			}
+		}
+	}
+
+	return &Christofides{
+		tourCircuit: newTourCircuit(shortcutEulerTour(unique, adjacency)),
+		matching:    matched,
+		tree:        tree,
+	}
+}
+
+// GetMatching returns, for each vertex in the spanning tree, the index of the vertex it is matched to, or -1 if it has an even degree in the tree and is unmatched.
+func (c *Christofides) GetMatching() []int {
+	return c.matching
+}
+
+// GetTree returns the minimum spanning tree used to construct the circuit.
+// Its length is a lower bound on the length of the optimal circuit, for symmetric distances.
+func (c *Christofides) GetTree() *mst.Tree {
+	return c.tree
+}
+
+// shortcutEulerTour computes an Euler tour of the supplied multigraph using Hierholzer's algorithm, starting from the first vertex,
+// and returns the vertices in the order they are first visited by the tour. Every vertex in the multigraph must have an even degree.
+func shortcutEulerTour(vertices []model.CircuitVertex, adjacency [][]int) []model.CircuitVertex {
+	tour := make([]model.CircuitVertex, 0, len(vertices))
+	if len(vertices) == 0 {
+		return tour
+	}
+
+	// Track how many times each undirected edge remains, so that traversing an edge in one direction also removes it in the other direction.
+	type pair struct{ a, b int }
+	remaining := make(map[pair]int)
+	for a, neighbors := range adjacency {
+		for _, b := range neighbors {
+			remaining[pair{a, b}]++
+		}
+	}
+	next := make([]int, len(adjacency))
+	visited := make([]bool, len(vertices))
+
+	stack := []int{0}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		neighbors := adjacency[current]
+		for next[current] < len(neighbors) && remaining[pair{current, neighbors[next[current]]}] == 0 {
+			next[current]++
+		}
+		if next[current] < len(neighbors) {
+			neighbor := neighbors[next[current]]
+			remaining[pair{current, neighbor}]--
+			remaining[pair{neighbor, current}]--
+			stack = append(stack, neighbor)
+			continue
+		}
+		// The vertex has no unused edges, so it is the next vertex of the Euler tour.
+		// The tour is produced in reverse, starting from the first vertex, which is equally valid since the multigraph is undirected.
+		stack = stack[:len(stack)-1]
+		if !visited[current] {
+			visited[current] = true
+			tour = append(tour, vertices[current])
+		}
+	}
+	return tour
+}
+
+var _ model.Circuit = (*Christofides)(nil)
@@ -0,0 +1,135 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChristofides(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(0, 10),
+		model2d.NewVertex2D(5, 5),
+	}
+	c := circuit.NewChristofides(vertices)
+	assert.Equal([]model.CircuitVertex{vertices[0]}, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 4)
+	assert.InDelta(0.0, c.GetLength(), model.Threshold)
+
+	// The tree is a star centered on (5,5), so every corner has an odd degree and is matched to an adjacent corner.
+	assert.Len(c.GetTree().GetEdges(), 4)
+	assert.InDelta(4*vertices[0].DistanceTo(vertices[4]), c.GetTree().GetLength(), model.Threshold)
+	matching := c.GetMatching()
+	assert.Equal(-1, matching[4])
+	for i := 0; i < 4; i++ {
+		assert.Equal(i, matching[matching[i]])
+		assert.InDelta(10.0, vertices[i].DistanceTo(vertices[matching[i]]), model.Threshold)
+	}
+
+	next, edge := c.FindNextVertexAndEdge()
+	assert.NotNil(next)
+	assert.True(vertices[0].EdgeTo(vertices[0]).Equals(edge))
+	// Finding the next vertex does not modify the circuit, so repeated calls produce the same result.
+	repeated, _ := c.FindNextVertexAndEdge()
+	assert.Equal(next, repeated)
+
+	for ; next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	// The optimal circuit is the perimeter plus a detour through the center; the shortcut Euler tour finds it.
+	assert.InDelta(30+2*vertices[0].DistanceTo(vertices[4]), c.GetLength(), model.Threshold)
+
+	// Attached vertices are ignored.
+	c.Update(vertices[1], nil)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+}
+
+func TestChristofides_ApproximationRatio(t *testing.T) {
+	assert := assert.New(t)
+
+	for i := 0; i < 5; i++ {
+		vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(9))
+		c := circuit.NewChristofides(vertices)
+		for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+			c.Update(next, edge)
+		}
+		_, optimalLength := solver.FindShortestPathNPHeap(vertices)
+		assert.LessOrEqual(c.GetLength(), 1.5*optimalLength+model.Threshold)
+	}
+}
+
+func TestChristofides_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model3d.GenerateVertices(200)
+	c := circuit.NewChristofides(vertices)
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	// Twice the tree is an upper bound for the shortcut Euler tour of the tree alone, and the matching can only improve on that.
+	assert.LessOrEqual(c.GetLength(), 2*c.GetTree().GetLength()+model.Threshold)
+}
+
+func TestChristofides_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    5,
+		MinEdges:    2,
+		NumVertices: 25,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+
+	c := circuit.NewChristofides(vertices)
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+}
+
+func TestChristofides_ShouldPanicForTooManyVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := make([]model.CircuitVertex, circuit.ChristofidesMaxVertices+1)
+	for i := range vertices {
+		vertices[i] = model2d.NewVertex2D(float64(i%50), float64(i/50))
+	}
+	assert.PanicsWithError("christofides supports at most 2000 vertices, found 2001", func() { circuit.NewChristofides(vertices) })
+}
+
+func TestChristofides_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	c := circuit.NewChristofides([]model.CircuitVertex{})
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+	assert.Len(c.GetAttachedVertices(), 0)
+
+	v := model2d.NewVertex2D(1, 2)
+	c = circuit.NewChristofides([]model.CircuitVertex{v, v})
+	next, _ = c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Equal([]model.CircuitVertex{v}, c.GetAttachedVertices())
+}
@@ -26,6 +26,7 @@ const maxClones uint16 = 1000
 type DisparityClonable struct {
 	significance     float64
 	maxClones        uint16
+	numClones        int
 	perimeterBuilder model.PerimeterBuilder
 	circuits         []*disparityClonableCircuit
 }
@@ -92,6 +93,11 @@ func (c *DisparityClonable) GetLength() float64 {
 	return 0.0
 }
 
+// GetNumClones returns the number of clones that have been created, including any that were later discarded due to the maximum number of clones.
+func (c *DisparityClonable) GetNumClones() int {
+	return c.numClones
+}
+
 func (c *DisparityClonable) GetUnattachedVertices() map[model.CircuitVertex]bool {
 	unattachedVertices := make(map[model.CircuitVertex]bool)
 	if len(c.circuits) > 0 {
@@ -129,6 +135,7 @@ func (c *DisparityClonable) Update(ignoredVertex model.CircuitVertex, ignoredEdg
 			}
 			updatedCircuits = append(updatedCircuits, circuit)
 			updatedCircuits = append(updatedCircuits, clones...)
+			c.numClones += len(clones)
 		}
 	}
 	if useUpdated {
@@ -47,6 +47,28 @@ func TestNewDisparityClonable(t *testing.T) {
 	assert.True(unattached[vertices[2]])
 	assert.True(unattached[vertices[3]])
 	assert.True(unattached[vertices[5]])
+
+	assert.Equal(0, c.GetNumClones())
+}
+
+func TestGetNumClones_DisparityClonable(t *testing.T) {
+	assert := assert.New(t)
+	vertices := []model.CircuitVertex{}
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			vertices = append(vertices, model2d.NewVertex2D(float64(x*10), float64(y*10+x)))
+		}
+	}
+	c := circuit.NewDisparityClonable(model2d.DeduplicateVertices(vertices), model2d.BuildPerimiter)
+	assert.Equal(0, c.GetNumClones())
+
+	previous := 0
+	for len(c.GetUnattachedVertices()) > 0 {
+		c.Update(c.FindNextVertexAndEdge())
+		assert.GreaterOrEqual(c.GetNumClones(), previous)
+		previous = c.GetNumClones()
+	}
+	assert.Greater(c.GetNumClones(), 0)
 }
 
 func TestUpdate_DisparityClonable(t *testing.T) {
@@ -0,0 +1,45 @@
+package circuit
+
+import (
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/mst"
+)
+
+// DoubleTree implements the double-tree algorithm, which produces a circuit that is at most twice the length of the optimal circuit, for metric distances.
+// It is simpler and faster than Christofides, which replaces the doubled edges with a matching, but its circuits are typically longer (e.g. 45% longer than optimal for usa13509, see BenchmarkUsa13509 in the tsplib package).
+// For graphs, the tree treats each edge as undirected, using the shorter of its two directions (see mst.NewGraphKruskal), so the guarantee does not apply to asymmetric distances.
+//
+// When it is created, the DoubleTree circuit:
+// 1. Computes a minimum spanning tree of the vertices, see mst.NewTree.
+// 2. Doubles every edge of the tree, so that every vertex has an even degree, and computes an Euler tour of the result, starting from the first vertex.
+// 3. Shortcuts the Euler tour, by skipping vertices that were already visited, to produce the circuit.
+//
+// The first vertex of the circuit is attached when this is created, then each call to FindNextVertexAndEdge and Update attaches the next vertex of that circuit.
+//
+// This is O(n^2) for up to mst.PrimMaxVertices 2D or 3D vertices, and O(n*log(n)) for more, in addition to the cost of the distances for graphs.
+type DoubleTree struct {
+	*tourCircuit
+	tree *mst.Tree
+}
+
+// NewDoubleTree creates a DoubleTree circuit, and computes the order in which it will attach the supplied vertices.
+// Duplicate references to the same vertex are ignored.
+func NewDoubleTree(vertices []model.CircuitVertex) *DoubleTree {
+	tree := mst.NewTree(vertices)
+	adjacency := tree.GetAdjacency()
+	for i, neighbors := range adjacency {
+		adjacency[i] = append(neighbors, neighbors...)
+	}
+	return &DoubleTree{
+		tourCircuit: newTourCircuit(shortcutEulerTour(tree.GetVertices(), adjacency)),
+		tree:        tree,
+	}
+}
+
+// GetTree returns the minimum spanning tree used to construct the circuit.
+// Its length is a lower bound on the length of the optimal circuit, for symmetric distances.
+func (d *DoubleTree) GetTree() *mst.Tree {
+	return d.tree
+}
+
+var _ model.Circuit = (*DoubleTree)(nil)
@@ -0,0 +1,109 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/mst"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoubleTree(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(3, 0),
+		model2d.NewVertex2D(1, 0),
+		model2d.NewVertex2D(2, 0),
+		model2d.NewVertex2D(1, 4),
+	}
+	c := circuit.NewDoubleTree(vertices)
+	assert.Equal([]model.CircuitVertex{vertices[0]}, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 4)
+	assert.InDelta(0.0, c.GetLength(), model.Threshold)
+	assert.Len(c.GetTree().GetEdges(), 4)
+	assert.InDelta(7.0, c.GetTree().GetLength(), model.Threshold)
+
+	next, edge := c.FindNextVertexAndEdge()
+	assert.NotNil(next)
+	assert.True(vertices[0].EdgeTo(vertices[0]).Equals(edge))
+	for ; next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	// Shortcutting the doubled tree can never be longer than twice the tree.
+	assert.LessOrEqual(c.GetLength(), 2*c.GetTree().GetLength()+model.Threshold)
+}
+
+func TestDoubleTree_ApproximationRatio(t *testing.T) {
+	assert := assert.New(t)
+
+	for i := 0; i < 5; i++ {
+		vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(9))
+		c := circuit.NewDoubleTree(vertices)
+		for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+			c.Update(next, edge)
+		}
+		_, optimalLength := solver.FindShortestPathNPHeap(vertices)
+		assert.LessOrEqual(c.GetLength(), 2*optimalLength+model.Threshold)
+	}
+}
+
+func TestDoubleTree_Large(t *testing.T) {
+	assert := assert.New(t)
+
+	// Above mst.PrimMaxVertices the tree is computed with Kruskal's algorithm over the nearest neighbors.
+	vertices := model3d.GenerateVertices(mst.PrimMaxVertices + 500)
+	c := circuit.NewDoubleTree(vertices)
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.LessOrEqual(c.GetLength(), 2*c.GetTree().GetLength()+model.Threshold)
+}
+
+func TestDoubleTree_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    5,
+		MinEdges:    2,
+		NumVertices: 25,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+
+	c := circuit.NewDoubleTree(vertices)
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+}
+
+func TestDoubleTree_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	c := circuit.NewDoubleTree([]model.CircuitVertex{})
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+	assert.Len(c.GetAttachedVertices(), 0)
+
+	v := model2d.NewVertex2D(1, 2)
+	c = circuit.NewDoubleTree([]model.CircuitVertex{v, v})
+	next, _ = c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Equal([]model.CircuitVertex{v}, c.GetAttachedVertices())
+}
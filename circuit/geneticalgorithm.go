@@ -14,7 +14,7 @@ import (
 // Returning the best circuit found during this process.
 //
 // The detailed breakdown of how this works is:
-// 1. Initialization - a random set of parent circuits are created. By default these are random circuits, but users can optionally have the circuits based on the optimum convex hull.
+// 1. Initialization - a random set of parent circuits are created. By default these are random circuits, but users can optionally have the circuits based on the optimum convex hull, or on a circuit produced by another algorithm.
 // 2. Child circuits are created by:
 //     a. Randomly selecting two parent circuits.
 //     b. Using crossover to blend the parent circuits into a new circuit.
@@ -33,6 +33,7 @@ type GeneticAlgorithm struct {
 	numChildren       int
 	numIterations     int
 	random            *rand.Rand
+	source            *countingSource
 }
 
 type geneticCircuit struct {
@@ -59,7 +60,7 @@ func (g *geneticCircuit) setLength() {
 func NewGeneticAlgorithm(initCircuit []model.CircuitVertex, numParents int, numChildren int, maxIterations int) *GeneticAlgorithm {
 	circuitLen := len(initCircuit)
 	initGeneration := make([]*geneticCircuit, numParents)
-	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	random, source := newRandom(time.Now().UnixNano())
 
 	// Create an initial generation of random parents.
 	for genIndex := 0; genIndex < numParents; genIndex++ {
@@ -86,6 +87,7 @@ func NewGeneticAlgorithm(initCircuit []model.CircuitVertex, numParents int, numC
 		numChildren:       numChildren,
 		numIterations:     0,
 		random:            random,
+		source:            source,
 	}
 	g.sortGeneration()
 	return g
@@ -95,7 +97,7 @@ func NewGeneticAlgorithmWithPerimeterBuilder(initCircuit []model.CircuitVertex,
 	initEdges, interiorVertices := perimeterBuilder(initCircuit)
 	circuitLen := len(interiorVertices)
 	initGeneration := make([]*geneticCircuit, numParents)
-	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	random, source := newRandom(time.Now().UnixNano())
 
 	// Create an initial generation of random parents.
 	for genIndex := 0; genIndex < numParents; genIndex++ {
@@ -125,6 +127,45 @@ func NewGeneticAlgorithmWithPerimeterBuilder(initCircuit []model.CircuitVertex,
 		numChildren:       numChildren,
 		numIterations:     0,
 		random:            random,
+		source:            source,
+	}
+	g.sortGeneration()
+	return g
+}
+
+// NewGeneticAlgorithmFromCircuit completes the supplied circuit, then uses it to create the initial generation of parents.
+// The first parent is the completed circuit, and each other parent is a copy of it that has been mutated (using the default mutation rate), so that the generation is not identical.
+func NewGeneticAlgorithmFromCircuit(circuit model.Circuit, numParents int, numChildren int, maxIterations int) *GeneticAlgorithm {
+	for nextVertex, nextEdge := circuit.FindNextVertexAndEdge(); nextVertex != nil; nextVertex, nextEdge = circuit.FindNextVertexAndEdge() {
+		circuit.Update(nextVertex, nextEdge)
+	}
+
+	initCircuit := circuit.GetAttachedVertices()
+	circuitLen := len(initCircuit)
+	random, source := newRandom(time.Now().UnixNano())
+
+	g := &GeneticAlgorithm{
+		currentGeneration: make([]*geneticCircuit, numParents),
+		maxCrossovers:     circuitLen - 2,
+		maxIterations:     maxIterations,
+		mutationRate:      0.1,
+		numParents:        numParents,
+		numChildren:       numChildren,
+		numIterations:     0,
+		random:            random,
+		source:            source,
+	}
+
+	for genIndex := 0; genIndex < numParents; genIndex++ {
+		current := &geneticCircuit{
+			circuit: make([]model.CircuitVertex, circuitLen),
+		}
+		copy(current.circuit, initCircuit)
+		if genIndex > 0 {
+			g.mutate(current.circuit)
+		}
+		current.setLength()
+		g.currentGeneration[genIndex] = current
 	}
 	g.sortGeneration()
 	return g
@@ -169,7 +210,7 @@ func (g *GeneticAlgorithm) SetMutationRate(mutationRate float64) {
 // SetSeed sets the seed used by the GeneticAlgorithm for random number generation.
 // This is to facilitate consistent unit tests.
 func (s *GeneticAlgorithm) SetSeed(seed int64) {
-	s.random = rand.New(rand.NewSource(seed))
+	s.random, s.source = newRandom(seed)
 }
 
 func (g *GeneticAlgorithm) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
@@ -252,7 +293,11 @@ func (g *GeneticAlgorithm) fixMissingAndDuplicateVertices(toFix []model.CircuitV
 	}
 
 	// Add each missing vertex to the array in place of a random duplicate.
-	for missingVertex := range missingVertices {
+	// Iterate over the vertices in the order of the supplied array, rather than over the map, so that the result only depends on the random number generator.
+	for _, missingVertex := range allVertices {
+		if !missingVertices[missingVertex] {
+			continue
+		}
 		duplicateIndex := g.random.Intn(len(duplicateIndices))
 		vertexIndex := duplicateIndices[duplicateIndex]
 		toFix[vertexIndex] = missingVertex
@@ -61,6 +61,31 @@ func TestNewGeneticAlgorithmWithPerimeterBuilder(t *testing.T) {
 	assert.Nil(nextEdge)
 }
 
+func TestNewGeneticAlgorithmFromCircuit(t *testing.T) {
+	assert := assert.New(t)
+
+	initVertices := model2d.DeduplicateVertices(model2d.GenerateVertices(20))
+	precursor := circuit.NewClosestGreedy(initVertices, model2d.BuildPerimiter, false)
+
+	c := circuit.NewGeneticAlgorithmFromCircuit(precursor, 10, 50, 10)
+	assert.NotNil(c)
+	assert.Len(precursor.GetUnattachedVertices(), 0)
+
+	// The precursor's circuit is one of the parents, and the other parents are mutations of it, so the best parent cannot be longer than the precursor.
+	initCircuit := c.GetAttachedVertices()
+	assert.Len(initCircuit, len(initVertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.LessOrEqual(c.GetLength(), precursor.GetLength()+model.Threshold)
+	assert.InDelta(model.Length(initCircuit), c.GetLength(), model.Threshold)
+
+	c.SetSeed(8)
+	for nextVertex, nextEdge := c.FindNextVertexAndEdge(); nextVertex != nil; nextVertex, nextEdge = c.FindNextVertexAndEdge() {
+		c.Update(nextVertex, nextEdge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(initVertices))
+	assert.LessOrEqual(c.GetLength(), precursor.GetLength()+model.Threshold)
+}
+
 func TestUpdate_GeneticAlgorithm(t *testing.T) {
 	assert := assert.New(t)
 
@@ -0,0 +1,247 @@
+package circuit
+
+import (
+	"math"
+	"sort"
+
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/mst"
+	"github.com/heustis/tsp-solver-go/spatial"
+)
+
+// GreedyEdgeDefaultNeighbors is the number of nearest neighbors of each vertex that are used as candidate edges, for large sets of 2D and 3D vertices.
+const GreedyEdgeDefaultNeighbors = 10
+
+// GreedyEdgeMaxCompleteVertices is the largest number of vertices for which NewGreedyEdge uses every pair of vertices as a candidate edge.
+// Larger sets of 2D and 3D vertices only use each vertex's nearest neighbors, since sorting every pair of vertices is O(n^2*log(n)).
+const GreedyEdgeMaxCompleteVertices = 1000
+
+// GreedyEdge implements the [greedy edge](https://en.wikipedia.org/wiki/Travelling_salesman_problem#Constructive_heuristics) (also called greedy matching) construction heuristic.
+// Rather than growing a single circuit, it builds the circuit from its edges, shortest first:
+// 1. Sorts the candidate edges from shortest to longest.
+// 2. Adds each candidate edge to the circuit, provided that neither of its vertices already has two edges, and that it would not close a loop that excludes some vertices.
+//     * Loops are detected with a disjoint set (union-find), so each check is effectively O(1).
+// 3. If the candidate edges are exhausted before the circuit is complete (because only the nearest neighbors were candidates), the resulting paths are joined in the same way,
+//    using the nearest endpoints of other paths as the candidate edges of each path's endpoints, until a single path remains.
+// 4. Closes the circuit, and rotates it to start from the first vertex.
+//
+// The circuit is typically 15-20% longer than optimal (e.g. 20% for pcb442, 18% for pr2392, and 16% for usa13509), which is usually better than the convex-concave greedy algorithms on clustered points, at a fraction of the cost.
+// Distances are treated as symmetric (using the distance from the earlier vertex to the later vertex), so the circuit may not be a good approximation for asymmetric graphs.
+//
+// The entire circuit is computed when this is created, then each call to FindNextVertexAndEdge and Update attaches the next vertex of that circuit.
+type GreedyEdge struct {
+	*tourCircuit
+	numNeighbors int
+}
+
+// NewGreedyEdge creates a GreedyEdge circuit, using every pair of vertices as candidate edges for up to GreedyEdgeMaxCompleteVertices vertices,
+// and the GreedyEdgeDefaultNeighbors nearest neighbors of each vertex for larger sets of 2D and 3D vertices.
+// Duplicate references to the same vertex are ignored, and are not counted towards GreedyEdgeMaxCompleteVertices.
+func NewGreedyEdge(vertices []model.CircuitVertex) *GreedyEdge {
+	unique := uniqueVertices(vertices)
+	numNeighbors := 0
+	if len(unique) > GreedyEdgeMaxCompleteVertices {
+		numNeighbors = GreedyEdgeDefaultNeighbors
+	}
+	return NewGreedyEdgeWithNeighbors(unique, numNeighbors)
+}
+
+// NewGreedyEdgeWithNeighbors creates a GreedyEdge circuit that uses the supplied number of nearest neighbors of each vertex as candidate edges.
+// If the number of neighbors is less than 1, or the vertices are not all 2D or all 3D vertices (e.g. graphs), every pair of vertices is a candidate edge.
+// Duplicate references to the same vertex are ignored.
+func NewGreedyEdgeWithNeighbors(vertices []model.CircuitVertex, numNeighbors int) *GreedyEdge {
+	unique := uniqueVertices(vertices)
+
+	var candidates []mst.Edge
+	if numNeighbors > 0 {
+		if tree, okay := spatial.NewKDTree(unique); okay {
+			candidates = mst.FindNeighborEdges(unique, tree, numNeighbors)
+		}
+	}
+	if candidates == nil {
+		numNeighbors = 0
+		candidates = mst.FindAllEdges(unique)
+	}
+
+	return &GreedyEdge{
+		tourCircuit:  newTourCircuit(buildGreedyEdgeTour(unique, candidates, numNeighbors)),
+		numNeighbors: numNeighbors,
+	}
+}
+
+// GetNumNeighbors returns the number of nearest neighbors of each vertex that were used as candidate edges, or 0 if every pair of vertices was a candidate edge.
+func (g *GreedyEdge) GetNumNeighbors() int {
+	return g.numNeighbors
+}
+
+// buildGreedyEdgeTour adds the candidate edges, shortest first, then joins the resulting paths into a circuit that starts at the first vertex.
+// If the candidates are the nearest neighbors of each vertex, the paths are joined by the shortest edges between their endpoints, see joinEndpoints.
+func buildGreedyEdgeTour(vertices []model.CircuitVertex, candidates []mst.Edge, numNeighbors int) []model.CircuitVertex {
+	if len(vertices) == 0 {
+		return []model.CircuitVertex{}
+	}
+
+	paths := newGreedyPaths(len(vertices))
+	paths.addEdges(sortEdgesByLength(candidates))
+	if numNeighbors > 0 {
+		paths.joinEndpoints(vertices, numNeighbors)
+	}
+	order := joinPaths(vertices, paths.adjacency)
+
+	// Rotate the circuit to start from the first vertex, for consistency with the other algorithms.
+	for i, index := range order {
+		if index == 0 {
+			order = append(order[i:], order[:i]...)
+			break
+		}
+	}
+	return toVertices(vertices, order)
+}
+
+// sortEdgesByLength sorts the supplied edges from shortest to longest, and returns them.
+func sortEdgesByLength(edges []mst.Edge) []mst.Edge {
+	// A stable sort ensures that ties are resolved consistently, since the candidates are generated in a consistent order.
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].Length < edges[j].Length
+	})
+	return edges
+}
+
+// greedyPaths tracks the paths created by greedily adding edges, where each vertex has at most two neighbors, and there are no loops.
+type greedyPaths struct {
+	adjacency  [][]int
+	components *mst.DisjointSet
+	numEdges   int
+}
+
+func newGreedyPaths(numVertices int) *greedyPaths {
+	return &greedyPaths{
+		adjacency:  make([][]int, numVertices),
+		components: mst.NewDisjointSet(numVertices),
+		numEdges:   0,
+	}
+}
+
+// addEdges adds each of the supplied edges, in order, unless one of its vertices already has two edges, or it would create a loop.
+func (p *greedyPaths) addEdges(sortedEdges []mst.Edge) {
+	for _, e := range sortedEdges {
+		if p.isComplete() {
+			return
+		}
+		if len(p.adjacency[e.From]) < 2 && len(p.adjacency[e.To]) < 2 && p.components.Union(e.From, e.To) {
+			p.adjacency[e.From] = append(p.adjacency[e.From], e.To)
+			p.adjacency[e.To] = append(p.adjacency[e.To], e.From)
+			p.numEdges++
+		}
+	}
+}
+
+// isComplete returns true once the edges form a single path through every vertex.
+func (p *greedyPaths) isComplete() bool {
+	return p.numEdges >= len(p.adjacency)-1
+}
+
+// joinEndpoints joins the paths into a single path, by greedily adding the shortest edges between the endpoints of different paths (unconnected vertices are paths with one vertex).
+// Each round uses the nearest endpoints of each endpoint as candidates, and doubles the number of neighbors whenever a round cannot join any paths,
+// so it always completes, since a round that considers every pair of endpoints joins at least two paths.
+// The vertices must all be 2D or all be 3D vertices.
+func (p *greedyPaths) joinEndpoints(vertices []model.CircuitVertex, numNeighbors int) {
+	for !p.isComplete() {
+		endpoints := []model.CircuitVertex{}
+		indices := []int{}
+		for i, neighbors := range p.adjacency {
+			if len(neighbors) < 2 {
+				endpoints = append(endpoints, vertices[i])
+				indices = append(indices, i)
+			}
+		}
+
+		tree, _ := spatial.NewKDTree(endpoints)
+		candidates := mst.FindNeighborEdges(endpoints, tree, numNeighbors)
+		for i, e := range candidates {
+			candidates[i].From, candidates[i].To = indices[e.From], indices[e.To]
+		}
+
+		numEdges := p.numEdges
+		p.addEdges(sortEdgesByLength(candidates))
+		if p.numEdges == numEdges {
+			numNeighbors *= 2
+		}
+	}
+}
+
+// joinPaths converts the paths in the supplied adjacency lists (where each vertex has at most two neighbors, and there are no loops) into a single ordering of the vertices.
+// Starting from the path containing the first vertex, it repeatedly appends the path with the closest endpoint to the end of the ordering. Unconnected vertices are paths with one vertex.
+func joinPaths(vertices []model.CircuitVertex, adjacency [][]int) []int {
+	numVertices := len(vertices)
+	pathOf := make([]int, numVertices)
+	for i := range pathOf {
+		pathOf[i] = -1
+	}
+	paths := [][]int{}
+	for start := range vertices {
+		// Each path is traversed from one of its endpoints, so only vertices with fewer than two neighbors start a path.
+		if pathOf[start] >= 0 || len(adjacency[start]) == 2 {
+			continue
+		}
+		path := []int{}
+		for previous, current := -1, start; current >= 0; {
+			path = append(path, current)
+			pathOf[current] = len(paths)
+			next := -1
+			for _, neighbor := range adjacency[current] {
+				if neighbor != previous {
+					next = neighbor
+				}
+			}
+			previous, current = current, next
+		}
+		paths = append(paths, path)
+	}
+
+	order := make([]int, 0, numVertices)
+	joined := make([]bool, len(paths))
+	current := paths[pathOf[0]]
+	for numJoined := 0; ; numJoined++ {
+		joined[pathOf[current[0]]] = true
+		order = append(order, current...)
+		if numJoined == len(paths)-1 {
+			return order
+		}
+
+		last := vertices[order[len(order)-1]]
+		var closest []int
+		closestDistance := math.MaxFloat64
+		for i, path := range paths {
+			if joined[i] {
+				continue
+			}
+			if distance := last.DistanceTo(vertices[path[0]]); distance < closestDistance {
+				closest, closestDistance = path, distance
+			}
+			if distance := last.DistanceTo(vertices[path[len(path)-1]]); distance < closestDistance {
+				closest, closestDistance = reverseIndices(path), distance
+			}
+		}
+		current = closest
+	}
+}
+
+// toVertices returns the vertices at the supplied indices, in order.
+func toVertices(vertices []model.CircuitVertex, order []int) []model.CircuitVertex {
+	tour := make([]model.CircuitVertex, len(order))
+	for i, index := range order {
+		tour[i] = vertices[index]
+	}
+	return tour
+}
+
+func reverseIndices(indices []int) []int {
+	reversed := make([]int, len(indices))
+	for i, index := range indices {
+		reversed[len(indices)-1-i] = index
+	}
+	return reversed
+}
+
+var _ model.Circuit = (*GreedyEdge)(nil)
@@ -0,0 +1,173 @@
+package circuit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/tsplib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGreedyEdge(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(1, 0),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(0, 10),
+		model2d.NewVertex2D(10, 9),
+	}
+	c := circuit.NewGreedyEdge(vertices)
+	assert.Equal(0, c.GetNumNeighbors())
+	assert.Equal([]model.CircuitVertex{vertices[0]}, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 5)
+
+	next, edge := c.FindNextVertexAndEdge()
+	assert.NotNil(next)
+	assert.True(vertices[0].EdgeTo(vertices[0]).Equals(edge))
+	for ; next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+
+	// The shortest edges (0,0)-(1,0) and (10,10)-(10,9) are added first, then (1,0)-(10,0) and (10,9)-(10,0).
+	// (0,0)-(10,0) is rejected, since (10,0) already has two edges, so (0,0)-(0,10) completes the path, which is then closed.
+	assert.Equal([]model.CircuitVertex{vertices[0], vertices[4], vertices[1], vertices[5], vertices[3], vertices[2]}, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(40.0, c.GetLength(), model.Threshold)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+}
+
+func TestGreedyEdge_Neighbors(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(500))
+	complete := circuit.NewGreedyEdge(vertices)
+	neighbors := circuit.NewGreedyEdgeWithNeighbors(vertices, 5)
+	assert.Equal(5, neighbors.GetNumNeighbors())
+
+	for _, c := range []*circuit.GreedyEdge{complete, neighbors} {
+		for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+			c.Update(next, edge)
+		}
+		assert.Len(c.GetAttachedVertices(), len(vertices))
+		assert.Len(c.GetUnattachedVertices(), 0)
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	}
+	// Restricting the candidates to the nearest neighbors should have little effect on the length of the circuit.
+	assert.InDelta(complete.GetLength(), neighbors.GetLength(), 0.1*complete.GetLength())
+}
+
+func TestGreedyEdge_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model3d.GenerateVertices(200)
+	c := circuit.NewGreedyEdgeWithNeighbors(vertices, 8)
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+}
+
+func TestGreedyEdge_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	// Unidirectional edges can leave some vertices unable to reach the others, so this uses a seed that produces a connected graph.
+	seed := int64(1)
+	gen := &graph.GraphGenerator{
+		EnableAsymetricDistances:  true,
+		EnableUnidirectionalEdges: true,
+		MaxEdges:                  5,
+		MinEdges:                  2,
+		NumVertices:               25,
+		Seed:                      &seed,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+
+	// Graphs do not support nearest neighbor queries, so every pair of vertices is a candidate edge.
+	c := circuit.NewGreedyEdgeWithNeighbors(vertices, 5)
+	assert.Equal(0, c.GetNumNeighbors())
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+}
+
+func TestGreedyEdge_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	c := circuit.NewGreedyEdge([]model.CircuitVertex{})
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+
+	v := model2d.NewVertex2D(1, 2)
+	c = circuit.NewGreedyEdge([]model.CircuitVertex{v, v})
+	next, _ = c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Equal([]model.CircuitVertex{v}, c.GetAttachedVertices())
+}
+
+func TestGreedyEdge_Usa13509(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large data set in short mode")
+	}
+	assert := assert.New(t)
+
+	data, err := tsplib.NewData("../test-data/tsplib/usa13509.tsp")
+	assert.Nil(err)
+	vertices := data.GetVertices()
+
+	start := time.Now()
+	c := circuit.NewGreedyEdge(vertices)
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	duration := time.Since(start)
+	t.Logf("usa13509 took %v", duration)
+
+	assert.Equal(circuit.GreedyEdgeDefaultNeighbors, c.GetNumNeighbors())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	// The optimal length of usa13509 is 19,982,859, and greedy edge is typically within 20% of it.
+	assert.Less(c.GetLength(), 1.2*19982859)
+	assert.Less(duration, 10*time.Second)
+}
+
+// With nearest neighbor candidates, many paths remain once the candidates are exhausted, so joining them greedily rather than in order has a large effect on the length of the circuit.
+func TestGreedyEdge_ShouldJoinPathsByShortestEdges(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := tsplib.NewData("../test-data/tsplib/pr2392.tsp")
+	assert.Nil(err)
+	c := circuit.NewGreedyEdge(data.GetVertices())
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+
+	assert.Equal(circuit.GreedyEdgeDefaultNeighbors, c.GetNumNeighbors())
+	assert.Len(c.GetAttachedVertices(), data.GetNumPoints())
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), 1.2*data.GetBestRouteLength())
+}
+
+func TestGreedyEdge_ShouldIgnoreDuplicatesWhenChoosingCandidates(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(circuit.GreedyEdgeMaxCompleteVertices * 3 / 4))
+	duplicated := append(append([]model.CircuitVertex{}, vertices...), vertices...)
+	assert.Greater(len(duplicated), circuit.GreedyEdgeMaxCompleteVertices)
+
+	c := circuit.NewGreedyEdge(duplicated)
+	assert.Equal(0, c.GetNumNeighbors())
+	assert.Len(c.GetUnattachedVertices(), len(vertices)-1)
+}
@@ -0,0 +1,169 @@
+package circuit
+
+import (
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// GuidedLocalSearchDefaultPenaltyFactor is the default penalty factor of GuidedLocalSearch, see GuidedLocalSearch.SetPenaltyFactor.
+const GuidedLocalSearchDefaultPenaltyFactor = 0.3
+
+// GuidedLocalSearch implements [guided local search](https://en.wikipedia.org/wiki/Guided_Local_Search), which deterministically escapes the local optima of 2-opt and Or-opt by penalizing the edges of each local optimum.
+// Rather than minimizing the length of the circuit, the moves minimize an augmented cost, in which the cost of each edge is its length plus (lambda * the number of times it has been penalized).
+//
+// This:
+// 1. Applies 2-opt and Or-opt moves, with the augmented cost, until no move improves the circuit (i.e. a local optimum), then saves the circuit if it is the shortest so far (by its actual length).
+// 2. Computes the utility of each edge in the circuit, which is its length / (1 + the number of times it has been penalized), and penalizes the edges with the highest utility.
+//     * Lambda is set once, at the first local optimum, to the penalty factor multiplied by the average length of an edge in that circuit.
+// 3. Adds the vertices of the penalized edges to the queue, so that the local search only revisits the parts of the circuit that are affected by the penalties.
+// 4. Repeats steps 1-3 until the edges have been penalized "maxIterations" times, then completes one last local search.
+//
+// Long edges are penalized first, but each penalty reduces the utility of penalizing that edge again, so the search is gradually pushed away from the features of the local optima it has already found.
+// No random numbers are used, so the same circuit always produces the same result. GetAttachedVertices and GetLength return the shortest circuit found so far, and its actual length.
+type GuidedLocalSearch struct {
+	*localSearchCircuit
+	best          []int
+	bestCircuit   []model.CircuitVertex
+	bestLength    float64
+	isComplete    bool
+	lambda        float64
+	maxIterations int
+	numIterations int
+	penalties     map[int]int
+	penaltyFactor float64
+}
+
+// NewGuidedLocalSearch creates a GuidedLocalSearch circuit that improves the supplied circuit, for the supplied number of penalty iterations, considering moves to the supplied number of candidate neighbors of each vertex.
+// If the number of neighbors is less than 1, every vertex is a candidate. Duplicate references to the same vertex are ignored.
+func NewGuidedLocalSearch(circuit []model.CircuitVertex, numNeighbors int, maxIterations int) *GuidedLocalSearch {
+	search := newLocalSearch(circuit, numNeighbors)
+	g := &GuidedLocalSearch{
+		best:          append([]int{}, search.tour...),
+		bestLength:    search.getLength(),
+		isComplete:    len(search.tour) < 4,
+		maxIterations: maxIterations,
+		penalties:     make(map[int]int),
+		penaltyFactor: GuidedLocalSearchDefaultPenaltyFactor,
+	}
+	g.localSearchCircuit = &localSearchCircuit{
+		improve: func(a int) bool {
+			return search.improveTwoOpt(a) || search.improveOrOpt(a, OrOptDefaultMaxSegmentLength)
+		},
+		search: search,
+	}
+
+	// The penalties are added to the cost of the edges, so the costs are no longer the distances between the vertices, and the candidates are no longer sorted by cost.
+	distance := search.cost
+	search.cost = func(a int, b int) float64 {
+		if penalty, okay := g.penalties[search.edgeKey(a, b)]; okay {
+			return distance(a, b) + g.lambda*float64(penalty)
+		}
+		return distance(a, b)
+	}
+	search.candidatesSortedByCost = false
+	search.costIsDistance = false
+	return g
+}
+
+// NewGuidedLocalSearchFromCircuit completes the supplied circuit, then creates a GuidedLocalSearch circuit that improves it.
+func NewGuidedLocalSearchFromCircuit(circuit model.Circuit, numNeighbors int, maxIterations int) *GuidedLocalSearch {
+	return NewGuidedLocalSearch(attachAll(circuit), numNeighbors, maxIterations)
+}
+
+// FindNextVertexAndEdge returns (nil, nil) once the edges have been penalized "maxIterations" times and the final local search is complete, otherwise it returns the first vertex in the circuit, which is ignored by Update.
+func (g *GuidedLocalSearch) FindNextVertexAndEdge() (model.CircuitVertex, model.CircuitEdge) {
+	if g.isComplete {
+		return nil, nil
+	}
+	return g.search.vertices[g.search.tour[0]], nil
+}
+
+// GetAttachedVertices returns the shortest circuit found so far.
+func (g *GuidedLocalSearch) GetAttachedVertices() []model.CircuitVertex {
+	if g.bestCircuit == nil {
+		g.bestCircuit = toVertices(g.search.vertices, g.best)
+	}
+	return g.bestCircuit
+}
+
+// GetLength returns the actual length of the shortest circuit found so far, excluding any penalties.
+func (g *GuidedLocalSearch) GetLength() float64 {
+	return g.bestLength
+}
+
+// GetNumIterations returns the number of times that the edges of a local optimum have been penalized.
+func (g *GuidedLocalSearch) GetNumIterations() int {
+	return g.numIterations
+}
+
+// GetPenalty returns the number of times that the edge between the supplied vertices has been penalized.
+func (g *GuidedLocalSearch) GetPenalty(a model.CircuitVertex, b model.CircuitVertex) int {
+	indexA, indexB := -1, -1
+	for i, v := range g.search.vertices {
+		if v == a {
+			indexA = i
+		}
+		if v == b {
+			indexB = i
+		}
+	}
+	if indexA < 0 || indexB < 0 {
+		return 0
+	}
+	return g.penalties[g.search.edgeKey(indexA, indexB)]
+}
+
+// GetPenaltyFactor returns the factor used to compute lambda, see SetPenaltyFactor.
+func (g *GuidedLocalSearch) GetPenaltyFactor() float64 {
+	return g.penaltyFactor
+}
+
+// SetPenaltyFactor sets the factor used to compute lambda, the amount that each penalty adds to the cost of an edge, relative to the average length of an edge in the first local optimum.
+// This must be called before the first local optimum is reached for it to have an effect.
+func (g *GuidedLocalSearch) SetPenaltyFactor(penaltyFactor float64) {
+	g.penaltyFactor = penaltyFactor
+}
+
+// Update checks the next vertex in the queue for an improving move, and applies it if one is found.
+// Once the queue is empty, the circuit is a local optimum, so this saves it if it is the shortest circuit so far, then penalizes its edges with the highest utility. The supplied vertex and edge are ignored.
+func (g *GuidedLocalSearch) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
+	if g.search.queueLen() > 0 {
+		g.localSearchCircuit.Update(vertexToAdd, edgeToSplit)
+		return
+	} else if g.isComplete {
+		return
+	}
+
+	s := g.search
+	if length := s.getLength(); length < g.bestLength-model.Threshold {
+		g.best = append(g.best[:0], s.tour...)
+		g.bestCircuit = nil
+		g.bestLength = length
+	}
+	if g.numIterations >= g.maxIterations {
+		g.isComplete = true
+		return
+	}
+	if g.numIterations == 0 {
+		g.lambda = g.penaltyFactor * s.getLength() / float64(len(s.tour))
+	}
+	g.numIterations++
+
+	// Ties are penalized together, so the result does not depend on where the circuit starts.
+	maxUtility, penalized := 0.0, []int{}
+	for i, a := range s.tour {
+		b := s.tour[(i+1)%len(s.tour)]
+		utility := s.distance(a, b) / float64(1+g.penalties[s.edgeKey(a, b)])
+		if utility > maxUtility+model.Threshold {
+			maxUtility, penalized = utility, append(penalized[:0], a, b)
+		} else if utility > maxUtility-model.Threshold {
+			penalized = append(penalized, a, b)
+		}
+	}
+	for i := 0; i < len(penalized); i += 2 {
+		g.penalties[s.edgeKey(penalized[i], penalized[i+1])]++
+		s.push(penalized[i])
+		s.push(penalized[i+1])
+	}
+}
+
+var _ model.Circuit = (*GuidedLocalSearch)(nil)
@@ -0,0 +1,186 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuidedLocalSearch(t *testing.T) {
+	assert := assert.New(t)
+
+	// The initial circuit crosses itself, between (0,0)-(10,10) and (10,0)-(0,10).
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(0, 10),
+	}
+	c := circuit.NewGuidedLocalSearch(vertices, 0, 3)
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(vertices), c.GetLength(), model.Threshold)
+	assert.Equal(circuit.GuidedLocalSearchDefaultPenaltyFactor, c.GetPenaltyFactor())
+
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Equal(vertices[0], next)
+	assert.Nil(edge)
+	for ; next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+
+	// Every edge of the square has the same length, so the first two iterations penalize all four edges.
+	// The penalized square is then more expensive than a circuit with two diagonals, so the third iteration penalizes both diagonals.
+	assert.Equal(3, c.GetNumIterations())
+	assert.Equal(2, c.GetPenalty(vertices[0], vertices[2]))
+	assert.Equal(2, c.GetPenalty(vertices[2], vertices[0]))
+	assert.Equal(1, c.GetPenalty(vertices[0], vertices[1]))
+	assert.Equal(1, c.GetPenalty(vertices[2], vertices[3]))
+	assert.InDelta(40.0, c.GetLength(), model.Threshold)
+	assert.InDelta(40.0, model.Length(c.GetAttachedVertices()), model.Threshold)
+	assert.Len(c.GetAttachedVertices(), 4)
+	assert.Len(c.GetUnattachedVertices(), 0)
+}
+
+func TestGuidedLocalSearch_ShouldMatchOptimalForSmallCircuits(t *testing.T) {
+	assert := assert.New(t)
+
+	for i := 0; i < 5; i++ {
+		vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(9))
+		c := circuit.NewGuidedLocalSearch(vertices, 0, 50)
+		solver.FindShortestPathCircuit(c)
+
+		_, optimalLength := solver.FindShortestPathNPHeap(vertices)
+		assert.Len(c.GetAttachedVertices(), len(vertices))
+		assert.Equal(50, c.GetNumIterations())
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+		assert.InDelta(optimalLength, c.GetLength(), model.Threshold)
+	}
+}
+
+// The candidates are sorted by distance rather than by their penalized cost, so when the edge to a nearer candidate is penalized, the moves must still check the candidates after it.
+// On these vertices, stopping at the first penalized candidate leaves a circuit of length 315.74, rather than the optimal 315.43.
+func TestGuidedLocalSearch_ShouldCheckCandidatesAfterPenalizedEdge(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(1, 72),
+		model2d.NewVertex2D(7, 8),
+		model2d.NewVertex2D(17, 14),
+		model2d.NewVertex2D(24, 75),
+		model2d.NewVertex2D(40, 45),
+		model2d.NewVertex2D(41, 5),
+		model2d.NewVertex2D(54, 88),
+		model2d.NewVertex2D(69, 81),
+		model2d.NewVertex2D(74, 26),
+		model2d.NewVertex2D(77, 6),
+	}
+	_, optimalLength := solver.FindShortestPathNPHeap(append([]model.CircuitVertex{}, vertices...))
+
+	for _, numNeighbors := range []int{3, 4} {
+		c := circuit.NewGuidedLocalSearch(append([]model.CircuitVertex{}, vertices...), numNeighbors, 10)
+		c.SetPenaltyFactor(0.1)
+		solver.FindShortestPathCircuit(c)
+		assert.Equal(10, c.GetNumIterations())
+		assert.InDelta(optimalLength, c.GetLength(), model.Threshold)
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	}
+}
+
+func TestGuidedLocalSearch_ShouldBeDeterministic(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(200))
+	initial := circuit.NewGuidedLocalSearch(vertices, 8, 0)
+	solver.FindShortestPathCircuit(initial)
+	assert.Equal(0, initial.GetNumIterations())
+
+	var expected []model.CircuitVertex
+	for i := 0; i < 2; i++ {
+		c := circuit.NewGuidedLocalSearch(vertices, 8, 300)
+		solver.FindShortestPathCircuit(c)
+		assert.Equal(300, c.GetNumIterations())
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+		// The best circuit is retained, so the penalties can only shorten the circuit.
+		assert.LessOrEqual(c.GetLength(), initial.GetLength()+model.Threshold)
+		if expected == nil {
+			expected = c.GetAttachedVertices()
+		} else {
+			assert.Equal(expected, c.GetAttachedVertices())
+		}
+	}
+}
+
+func TestGuidedLocalSearch_PenaltyFactor(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(100))
+	for _, factor := range []float64{0.1, 0.5, 1.0} {
+		c := circuit.NewGuidedLocalSearch(vertices, 6, 100)
+		c.SetPenaltyFactor(factor)
+		assert.Equal(factor, c.GetPenaltyFactor())
+		solver.FindShortestPathCircuit(c)
+		assert.Len(c.GetAttachedVertices(), len(vertices))
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+		assert.Less(c.GetLength(), model.Length(vertices))
+	}
+}
+
+func TestGuidedLocalSearch_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model3d.GenerateVertices(100)
+	initialLength := model.Length(vertices)
+	c := circuit.NewGuidedLocalSearch(vertices, 8, 100)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+}
+
+func TestGuidedLocalSearch_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    5,
+		MinEdges:    2,
+		NumVertices: 30,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+	initialLength := model.Length(vertices)
+
+	c := circuit.NewGuidedLocalSearch(vertices, 5, 50)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Equal(50, c.GetNumIterations())
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+}
+
+func TestGuidedLocalSearch_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	c := circuit.NewGuidedLocalSearch([]model.CircuitVertex{}, 5, 10)
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+	assert.Len(c.GetAttachedVertices(), 0)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(1, 2),
+		model2d.NewVertex2D(3, 2),
+		model2d.NewVertex2D(1, 5),
+	}
+	c = circuit.NewGuidedLocalSearch(append(vertices, vertices[0]), 5, 10)
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.Equal(0, c.GetNumIterations())
+}
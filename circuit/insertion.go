@@ -0,0 +1,298 @@
+package circuit
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// InsertionType determines how an Insertion selects the next vertex to attach to its circuit.
+type InsertionType int
+
+const (
+	// InsertionCheapest selects the vertex and edge that increase the length of the circuit the least.
+	InsertionCheapest InsertionType = iota
+	// InsertionFarthest selects the vertex that is farthest from the circuit (i.e. whose closest attached vertex is the farthest away).
+	InsertionFarthest
+	// InsertionNearest selects the vertex that is closest to the circuit.
+	InsertionNearest
+	// InsertionRandom selects a random vertex.
+	InsertionRandom
+)
+
+// Insertion implements the classic family of [insertion heuristics](https://en.wikipedia.org/wiki/Travelling_salesman_problem#Heuristic_and_approximation_algorithms), which do not build a convex hull.
+// This allows them to be used with asymmetric graphs, and to be compared against the results in the literature. Each variant:
+// 1. Starts with a seed pair of vertices: the first supplied vertex, and either the vertex closest to it (cheapest and nearest insertion) or the vertex farthest from it (farthest and random insertion).
+//     * Random insertion uses the farthest vertex, rather than a random vertex, so that its results only depend on the seed supplied to SetSeed.
+// 2. Selects an unattached vertex, based on its InsertionType.
+// 3. Attaches the vertex to the edge that increases the length of the circuit the least, by splitting that edge.
+// 4. Updates the distance from each unattached vertex to the circuit (and for cheapest insertion, the closest edge to each unattached vertex).
+// 5. Repeats steps 2-4 until all vertices are attached.
+//
+// All variants are O(n^2), except cheapest insertion, which is O(n^2) in practice but O(n^3) in the worst case, since an unattached vertex must check every edge when its closest edge is split.
+type Insertion struct {
+	attached      []bool
+	circuitEdges  []model.CircuitEdge
+	closestEdges  []*model.DistanceToEdge
+	distances     []float64
+	indices       map[model.CircuitVertex]int
+	insertionType InsertionType
+	length        float64
+	next          *model.DistanceToEdge
+	random        *rand.Rand
+	remaining     []int
+	source        *countingSource
+	unattached    map[model.CircuitVertex]bool
+	vertices      []model.CircuitVertex
+}
+
+// NewCheapestInsertion creates an Insertion that attaches the vertex that increases the length of the circuit the least.
+func NewCheapestInsertion(vertices []model.CircuitVertex) *Insertion {
+	return NewInsertion(vertices, InsertionCheapest)
+}
+
+// NewFarthestInsertion creates an Insertion that attaches the vertex that is farthest from the circuit.
+func NewFarthestInsertion(vertices []model.CircuitVertex) *Insertion {
+	return NewInsertion(vertices, InsertionFarthest)
+}
+
+// NewNearestInsertion creates an Insertion that attaches the vertex that is closest to the circuit.
+func NewNearestInsertion(vertices []model.CircuitVertex) *Insertion {
+	return NewInsertion(vertices, InsertionNearest)
+}
+
+// NewRandomInsertion creates an Insertion that attaches a random vertex. Use SetSeed for consistent results.
+func NewRandomInsertion(vertices []model.CircuitVertex) *Insertion {
+	return NewInsertion(vertices, InsertionRandom)
+}
+
+// NewInsertion creates an Insertion of the supplied type, and attaches its seed pair of vertices.
+// Duplicate references to the same vertex are ignored. If there are fewer than 2 unique vertices, the circuit only contains those vertices.
+func NewInsertion(vertices []model.CircuitVertex, insertionType InsertionType) *Insertion {
+	unique := make([]model.CircuitVertex, 0, len(vertices))
+	indices := make(map[model.CircuitVertex]int, len(vertices))
+	for _, v := range vertices {
+		if _, isDuplicate := indices[v]; !isDuplicate {
+			indices[v] = len(unique)
+			unique = append(unique, v)
+		}
+	}
+
+	random, source := newRandom(time.Now().UnixNano())
+	ins := &Insertion{
+		attached:      make([]bool, len(unique)),
+		circuitEdges:  []model.CircuitEdge{},
+		closestEdges:  make([]*model.DistanceToEdge, len(unique)),
+		distances:     make([]float64, len(unique)),
+		indices:       indices,
+		insertionType: insertionType,
+		length:        0.0,
+		random:        random,
+		remaining:     make([]int, 0, len(unique)),
+		source:        source,
+		unattached:    make(map[model.CircuitVertex]bool, len(unique)),
+		vertices:      unique,
+	}
+
+	if len(unique) == 0 {
+		return ins
+	}
+
+	first := unique[0]
+	ins.attached[0] = true
+	for i := 1; i < len(unique); i++ {
+		ins.remaining = append(ins.remaining, i)
+		ins.unattached[unique[i]] = true
+		ins.distances[i] = first.DistanceTo(unique[i])
+	}
+	if len(unique) == 1 {
+		return ins
+	}
+
+	// The seed pair is the first vertex and the vertex that cheapest, farthest, or nearest insertion would select when the first vertex is the only vertex in the circuit.
+	seedType := insertionType
+	if seedType == InsertionRandom {
+		seedType = InsertionFarthest
+	}
+	second := unique[ins.selectIndex(seedType)]
+	ins.attachSeed(first, second)
+	return ins
+}
+
+// FindNextVertexAndEdge returns the vertex selected by this Insertion's type, and the edge that it should split.
+// The selection is retained until Update is called, so calling this repeatedly does not select a different (e.g. random) vertex.
+func (ins *Insertion) FindNextVertexAndEdge() (model.CircuitVertex, model.CircuitEdge) {
+	if len(ins.remaining) == 0 {
+		return nil, nil
+	}
+	if ins.next == nil {
+		index := ins.selectIndex(ins.insertionType)
+		if ins.insertionType == InsertionCheapest {
+			ins.next = ins.closestEdges[index]
+		} else {
+			v := ins.vertices[index]
+			closest := model.FindClosestEdge(v, ins.circuitEdges)
+			ins.next = &model.DistanceToEdge{
+				Vertex:   v,
+				Edge:     closest,
+				Distance: closest.DistanceIncrease(v),
+			}
+		}
+	}
+	return ins.next.Vertex, ins.next.Edge
+}
+
+// GetAttachedEdges returns the edges in the circuit.
+func (ins *Insertion) GetAttachedEdges() []model.CircuitEdge {
+	return ins.circuitEdges
+}
+
+func (ins *Insertion) GetAttachedVertices() []model.CircuitVertex {
+	if len(ins.circuitEdges) == 0 {
+		if len(ins.vertices) > 0 {
+			return []model.CircuitVertex{ins.vertices[0]}
+		}
+		return []model.CircuitVertex{}
+	}
+	vertices := make([]model.CircuitVertex, len(ins.circuitEdges))
+	for i, edge := range ins.circuitEdges {
+		vertices[i] = edge.GetStart()
+	}
+	return vertices
+}
+
+// GetInsertionType returns how this Insertion selects the next vertex to attach.
+func (ins *Insertion) GetInsertionType() InsertionType {
+	return ins.insertionType
+}
+
+func (ins *Insertion) GetLength() float64 {
+	return ins.length
+}
+
+func (ins *Insertion) GetUnattachedVertices() map[model.CircuitVertex]bool {
+	return ins.unattached
+}
+
+// SetSeed sets the seed used by random insertion to select vertices. This is to facilitate consistent unit tests.
+func (ins *Insertion) SetSeed(seed int64) {
+	ins.random, ins.source = newRandom(seed)
+	ins.next = nil
+}
+
+// Update attaches the supplied vertex to the circuit by splitting the supplied edge, then updates the distance from each unattached vertex to the circuit.
+// If the vertex is already attached, or the edge is not in the circuit, the circuit is not changed.
+func (ins *Insertion) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
+	index, okay := ins.indices[vertexToAdd]
+	if !okay || ins.attached[index] {
+		return
+	}
+	var edgeIndex int
+	if ins.circuitEdges, edgeIndex = model.SplitEdge(ins.circuitEdges, edgeToSplit, vertexToAdd); edgeIndex < 0 {
+		return
+	}
+	ins.length += edgeToSplit.DistanceIncrease(vertexToAdd)
+	ins.markAttached(index)
+	ins.updateRemaining(vertexToAdd, edgeToSplit, ins.circuitEdges[edgeIndex], ins.circuitEdges[edgeIndex+1])
+}
+
+// attachSeed creates the initial circuit from the seed pair of vertices.
+func (ins *Insertion) attachSeed(first model.CircuitVertex, second model.CircuitVertex) {
+	ins.circuitEdges = []model.CircuitEdge{first.EdgeTo(second), second.EdgeTo(first)}
+	ins.length = ins.circuitEdges[0].GetLength() + ins.circuitEdges[1].GetLength()
+	ins.markAttached(ins.indices[second])
+	ins.updateRemaining(second, nil, ins.circuitEdges[0], ins.circuitEdges[1])
+
+	// Cheapest insertion needs the closest edge for every vertex, rather than only those affected by the seed.
+	if ins.insertionType == InsertionCheapest {
+		for _, i := range ins.remaining {
+			ins.closestEdges[i] = ins.findClosestEdge(ins.vertices[i])
+		}
+	}
+}
+
+func (ins *Insertion) findClosestEdge(v model.CircuitVertex) *model.DistanceToEdge {
+	closest := model.FindClosestEdge(v, ins.circuitEdges)
+	return &model.DistanceToEdge{
+		Vertex:   v,
+		Edge:     closest,
+		Distance: closest.DistanceIncrease(v),
+	}
+}
+
+// markAttached removes the vertex at the supplied index from the unattached vertices.
+func (ins *Insertion) markAttached(index int) {
+	ins.attached[index] = true
+	delete(ins.unattached, ins.vertices[index])
+	for i, remainingIndex := range ins.remaining {
+		if remainingIndex == index {
+			ins.remaining = append(ins.remaining[:i], ins.remaining[i+1:]...)
+			break
+		}
+	}
+	ins.next = nil
+}
+
+// selectIndex returns the index of the next vertex to attach, based on the supplied insertion type.
+// Vertices are checked in the order they were supplied, so that ties are resolved consistently.
+func (ins *Insertion) selectIndex(insertionType InsertionType) int {
+	if insertionType == InsertionRandom {
+		return ins.remaining[ins.random.Intn(len(ins.remaining))]
+	}
+
+	selected := -1
+	selectedValue := math.MaxFloat64
+	for _, i := range ins.remaining {
+		var value float64
+		switch insertionType {
+		case InsertionCheapest:
+			if ins.closestEdges[i] == nil {
+				// Prior to the seed pair being attached, the cheapest vertex is the closest vertex.
+				value = ins.distances[i]
+			} else {
+				value = ins.closestEdges[i].Distance
+			}
+		case InsertionFarthest:
+			value = -ins.distances[i]
+		default:
+			value = ins.distances[i]
+		}
+		if value < selectedValue {
+			selected = i
+			selectedValue = value
+		}
+	}
+	return selected
+}
+
+// updateRemaining updates the distance from each unattached vertex to the circuit, now that the supplied vertex has been attached.
+// For cheapest insertion it also updates the closest edge to each unattached vertex, now that the split edge has been replaced by edgeA and edgeB.
+func (ins *Insertion) updateRemaining(attachedVertex model.CircuitVertex, splitEdge model.CircuitEdge, edgeA model.CircuitEdge, edgeB model.CircuitEdge) {
+	for _, i := range ins.remaining {
+		v := ins.vertices[i]
+		ins.distances[i] = math.Min(ins.distances[i], attachedVertex.DistanceTo(v))
+
+		closest := ins.closestEdges[i]
+		if ins.insertionType != InsertionCheapest || closest == nil {
+			continue
+		}
+		if closest.Edge.Equals(splitEdge) {
+			ins.closestEdges[i] = ins.findClosestEdge(v)
+			continue
+		}
+		for _, e := range []model.CircuitEdge{edgeA, edgeB} {
+			if distance := e.DistanceIncrease(v); distance < closest.Distance {
+				closest = &model.DistanceToEdge{
+					Vertex:   v,
+					Edge:     e,
+					Distance: distance,
+				}
+			}
+		}
+		ins.closestEdges[i] = closest
+	}
+}
+
+var _ model.Circuit = (*Insertion)(nil)
@@ -0,0 +1,213 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertion_Farthest(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(0, 10),
+		model2d.NewVertex2D(5, 4),
+		model2d.NewVertex2D(1, 1),
+	}
+	c := circuit.NewFarthestInsertion(vertices)
+	assert.Equal(circuit.InsertionFarthest, c.GetInsertionType())
+	// The seed pair is the first vertex and the vertex farthest from it.
+	assert.Equal([]model.CircuitVertex{vertices[0], vertices[2]}, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 4)
+	assert.InDelta(2*vertices[0].DistanceTo(vertices[2]), c.GetLength(), model.Threshold)
+
+	// Both (10,0) and (0,10) are 10 units from the circuit, so the first supplied vertex is selected.
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Equal(vertices[1], next)
+	assert.True(vertices[0].EdgeTo(vertices[2]).Equals(edge))
+	// Finding the next vertex does not modify the circuit, so repeated calls produce the same result.
+	next, edge = c.FindNextVertexAndEdge()
+	assert.Equal(vertices[1], next)
+	c.Update(next, edge)
+	assert.Equal([]model.CircuitVertex{vertices[0], vertices[1], vertices[2]}, c.GetAttachedVertices())
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+
+	next, edge = c.FindNextVertexAndEdge()
+	assert.Equal(vertices[3], next)
+	assert.True(vertices[2].EdgeTo(vertices[0]).Equals(edge))
+	c.Update(next, edge)
+
+	next, edge = c.FindNextVertexAndEdge()
+	assert.Equal(vertices[4], next)
+
+	for ; next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+
+	// Attached vertices are ignored.
+	c.Update(vertices[4], c.GetAttachedEdges()[0])
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+}
+
+func TestInsertion_Nearest(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(0, 10),
+		model2d.NewVertex2D(5, 4),
+		model2d.NewVertex2D(1, 1),
+	}
+	c := circuit.NewNearestInsertion(vertices)
+	assert.Equal(circuit.InsertionNearest, c.GetInsertionType())
+	// The seed pair is the first vertex and the vertex closest to it.
+	assert.Equal([]model.CircuitVertex{vertices[0], vertices[5]}, c.GetAttachedVertices())
+
+	// (5,4) is 5 units from (1,1), which is closer than any other vertex is to the circuit.
+	next, _ := c.FindNextVertexAndEdge()
+	assert.Equal(vertices[4], next)
+
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+}
+
+func TestInsertion_Cheapest(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(0, 10),
+		model2d.NewVertex2D(5, 4),
+		model2d.NewVertex2D(1, 1),
+		model2d.NewVertex2D(5, 0),
+	}
+	c := circuit.NewCheapestInsertion(vertices)
+	assert.Equal(circuit.InsertionCheapest, c.GetInsertionType())
+	assert.Equal([]model.CircuitVertex{vertices[0], vertices[5]}, c.GetAttachedVertices())
+
+	// (5,0) increases the length of the circuit the least, even though (5,4) is closer to (1,1).
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Equal(vertices[6], next)
+	assert.InDelta(vertices[0].DistanceTo(vertices[6])+vertices[6].DistanceTo(vertices[5])-vertices[0].DistanceTo(vertices[5]), edge.DistanceIncrease(next), model.Threshold)
+
+	for ; next != nil; next, edge = c.FindNextVertexAndEdge() {
+		// Each selection must be the cheapest insertion across all unattached vertices and all edges.
+		for v := range c.GetUnattachedVertices() {
+			closest := model.FindClosestEdge(v, c.GetAttachedEdges())
+			assert.LessOrEqual(edge.DistanceIncrease(next), closest.DistanceIncrease(v)+model.Threshold)
+		}
+		c.Update(next, edge)
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+}
+
+func TestInsertion_Random(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(100))
+
+	solve := func() *circuit.Insertion {
+		c := circuit.NewRandomInsertion(vertices)
+		c.SetSeed(7)
+		for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+			c.Update(next, edge)
+		}
+		return c
+	}
+
+	c := solve()
+	assert.Equal(circuit.InsertionRandom, c.GetInsertionType())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+
+	// The seed pair is the first vertex and the vertex farthest from it, so the circuit only depends on the seed.
+	assert.Equal(vertices[0], c.GetAttachedVertices()[0])
+	other := solve()
+	assert.Equal(c.GetAttachedVertices(), other.GetAttachedVertices())
+	assert.InDelta(c.GetLength(), other.GetLength(), model.Threshold)
+}
+
+func TestInsertion_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model3d.GenerateVertices(150)
+	for _, insertionType := range []circuit.InsertionType{circuit.InsertionCheapest, circuit.InsertionFarthest, circuit.InsertionNearest, circuit.InsertionRandom} {
+		c := circuit.NewInsertion(vertices, insertionType)
+		for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+			c.Update(next, edge)
+		}
+		assert.Len(c.GetAttachedVertices(), len(vertices), insertionType)
+		assert.Len(c.GetUnattachedVertices(), 0, insertionType)
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold, insertionType)
+	}
+}
+
+func TestInsertion_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	// Unidirectional edges can leave some vertices unable to reach the others, so this uses a seed that produces a connected graph.
+	seed := int64(1)
+	gen := &graph.GraphGenerator{
+		EnableAsymetricDistances:  true,
+		EnableUnidirectionalEdges: true,
+		MaxEdges:                  5,
+		MinEdges:                  2,
+		NumVertices:               25,
+		Seed:                      &seed,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+
+	for _, insertionType := range []circuit.InsertionType{circuit.InsertionCheapest, circuit.InsertionFarthest, circuit.InsertionNearest, circuit.InsertionRandom} {
+		c := circuit.NewInsertion(vertices, insertionType)
+		for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+			c.Update(next, edge)
+		}
+		assert.Len(c.GetAttachedVertices(), len(vertices), insertionType)
+		assert.Len(c.GetUnattachedVertices(), 0, insertionType)
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold, insertionType)
+	}
+}
+
+func TestInsertion_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	c := circuit.NewCheapestInsertion([]model.CircuitVertex{})
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+	assert.Len(c.GetAttachedVertices(), 0)
+	assert.InDelta(0.0, c.GetLength(), model.Threshold)
+
+	v := model2d.NewVertex2D(1, 2)
+	c = circuit.NewFarthestInsertion([]model.CircuitVertex{v, v})
+	next, _ = c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Equal([]model.CircuitVertex{v}, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(0.0, c.GetLength(), model.Threshold)
+}
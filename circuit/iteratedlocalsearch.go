@@ -0,0 +1,330 @@
+package circuit
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// PerturbationType determines how an IteratedLocalSearch perturbs its circuit at the start of each iteration.
+type PerturbationType int
+
+const (
+	// PerturbationDoubleBridge reorders three short adjacent segments of the circuit (A-B-C-D becomes A-D-C-B), which 2-opt and Or-opt are unlikely to simply undo.
+	PerturbationDoubleBridge PerturbationType = iota
+	// PerturbationSegmentReversal reverses a short random segment of the circuit.
+	PerturbationSegmentReversal
+)
+
+// AcceptanceType determines whether an IteratedLocalSearch (or AdaptiveLargeNeighborhoodSearch) continues from the circuit produced by an iteration, or returns to the circuit it started the iteration from.
+type AcceptanceType int
+
+const (
+	// AcceptanceBetter accepts circuits that are not longer than the current circuit.
+	AcceptanceBetter AcceptanceType = iota
+	// AcceptanceRandomWalk accepts every circuit.
+	AcceptanceRandomWalk
+	// AcceptanceAnnealing accepts circuits that are not longer than the current circuit, and accepts longer circuits with a probability of exp(-increase/temperature),
+	// where the temperature decreases linearly to 0 over the course of the search (or remains constant, if neither the number of iterations nor the duration is limited).
+	AcceptanceAnnealing
+)
+
+// iteratedLocalSearchTemperatureFactor is the initial temperature of AcceptanceAnnealing, relative to the average length of an edge in the first local optimum.
+const iteratedLocalSearchTemperatureFactor = 0.05
+
+// IteratedLocalSearch implements [iterated local search](https://en.wikipedia.org/wiki/Iterated_local_search), which alternates between a local search and a perturbation of the resulting local optimum.
+// This:
+// 1. Applies 2-opt and Or-opt moves until no move improves the circuit (i.e. a local optimum), and records the circuit if it is the shortest so far.
+//     * Alternatively, an improver (e.g. SimulatedAnnealing) can be supplied via SetImprover, which is run to completion from the perturbed circuit instead.
+// 2. Decides, based on its AcceptanceType, whether to continue from the new local optimum or from the circuit it started the iteration from.
+// 3. Perturbs the circuit based on its PerturbationType, and adds the vertices whose edges changed to the queue, so that the local search only revisits the parts of the circuit affected by the perturbation.
+// 4. Repeats steps 1-3 until "maxIterations" perturbations have been applied, or the time limit (see SetMaxDuration) or stagnation limit (see SetMaxStagnation) is reached.
+//     * If "maxIterations" is 0 or less, the number of iterations is not limited, so the search continues until the time or stagnation limit is reached.
+//     * If none of the limits are set, the search completes at the first local optimum, rather than running indefinitely.
+//
+// GetAttachedVertices and GetLength return the shortest circuit found so far. Circuits with fewer than 8 vertices are too small to perturb, so they are only improved by 2-opt and Or-opt.
+// The perturbations are random, so SetSeed should be used for consistent results.
+type IteratedLocalSearch struct {
+	*localSearchCircuit
+	accepted       []int
+	acceptedLength float64
+	acceptance     AcceptanceType
+	best           []int
+	bestCircuit    []model.CircuitVertex
+	bestLength     float64
+	improver       func(circuit []model.CircuitVertex) model.Circuit
+	indices        map[model.CircuitVertex]int
+	inner          model.Circuit
+	isComplete     bool
+	isDescending   bool
+	maxDuration    time.Duration
+	maxIterations  int
+	maxStagnation  int
+	numAccepted    int
+	numIterations  int
+	numStagnant    int
+	perturbation   PerturbationType
+	random         *rand.Rand
+	startTime      time.Time
+	temperature    float64
+}
+
+// NewIteratedLocalSearch creates an IteratedLocalSearch circuit that improves the supplied circuit, considering moves to the supplied number of candidate neighbors of each vertex, for up to the supplied number of iterations.
+// If the number of neighbors is less than 1, every vertex is a candidate. If the number of iterations is 0 or less, the number of iterations is not limited (see IteratedLocalSearch). Duplicate references to the same vertex are ignored.
+// By default, this uses PerturbationDoubleBridge and AcceptanceBetter, and has no time or stagnation limit.
+func NewIteratedLocalSearch(circuit []model.CircuitVertex, numNeighbors int, maxIterations int) *IteratedLocalSearch {
+	search := newLocalSearch(circuit, numNeighbors)
+	i := &IteratedLocalSearch{
+		acceptance:    AcceptanceBetter,
+		isComplete:    len(search.tour) == 0,
+		isDescending:  true,
+		maxIterations: maxIterations,
+		perturbation:  PerturbationDoubleBridge,
+		random:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	i.localSearchCircuit = &localSearchCircuit{
+		improve: func(a int) bool {
+			return search.improveTwoOpt(a) || search.improveOrOpt(a, OrOptDefaultMaxSegmentLength)
+		},
+		search: search,
+	}
+	return i
+}
+
+// NewIteratedLocalSearchFromCircuit completes the supplied circuit, then creates an IteratedLocalSearch circuit that improves it.
+func NewIteratedLocalSearchFromCircuit(circuit model.Circuit, numNeighbors int, maxIterations int) *IteratedLocalSearch {
+	return NewIteratedLocalSearch(attachAll(circuit), numNeighbors, maxIterations)
+}
+
+// FindNextVertexAndEdge returns (nil, nil) once the search has reached one of its limits, otherwise it returns the first vertex in the circuit, which is ignored by Update.
+func (i *IteratedLocalSearch) FindNextVertexAndEdge() (model.CircuitVertex, model.CircuitEdge) {
+	if i.isComplete {
+		return nil, nil
+	}
+	return i.search.vertices[i.search.tour[0]], nil
+}
+
+// GetAttachedVertices returns the shortest circuit found so far.
+func (i *IteratedLocalSearch) GetAttachedVertices() []model.CircuitVertex {
+	if i.best == nil {
+		return i.localSearchCircuit.GetAttachedVertices()
+	} else if i.bestCircuit == nil {
+		i.bestCircuit = toVertices(i.search.vertices, i.best)
+	}
+	return i.bestCircuit
+}
+
+// GetLength returns the length of the shortest circuit found so far.
+func (i *IteratedLocalSearch) GetLength() float64 {
+	if i.best == nil {
+		return i.search.getLength()
+	}
+	return i.bestLength
+}
+
+// GetNumAccepted returns the number of iterations whose circuit was accepted.
+func (i *IteratedLocalSearch) GetNumAccepted() int {
+	return i.numAccepted
+}
+
+// GetNumIterations returns the number of perturbations that have been applied.
+func (i *IteratedLocalSearch) GetNumIterations() int {
+	return i.numIterations
+}
+
+// SetAcceptance sets whether each iteration's circuit is accepted.
+func (i *IteratedLocalSearch) SetAcceptance(acceptance AcceptanceType) {
+	i.acceptance = acceptance
+}
+
+// SetImprover replaces the 2-opt and Or-opt local search with circuits created by the supplied function, such as SimulatedAnnealing.
+// In each iteration, the function is supplied the perturbed circuit, and the circuit it returns is computed to completion, one step per call to Update.
+// The returned circuit must contain the same vertices as the supplied circuit; if its completed circuit omits or duplicates any vertex, it is discarded, and the iteration continues from the perturbed circuit.
+// This must be called before the first call to Update.
+func (i *IteratedLocalSearch) SetImprover(improver func(circuit []model.CircuitVertex) model.Circuit) {
+	i.improver = improver
+	i.indices = make(map[model.CircuitVertex]int, len(i.search.vertices))
+	for index, v := range i.search.vertices {
+		i.indices[v] = index
+	}
+}
+
+// SetMaxDuration sets the time limit of the search, which starts at the first call to Update. Once the limit is reached, the search completes after the current iteration. If the duration is 0 or less, the search is not time limited.
+func (i *IteratedLocalSearch) SetMaxDuration(maxDuration time.Duration) {
+	i.maxDuration = maxDuration
+}
+
+// SetMaxStagnation sets the number of consecutive iterations that may fail to find a shorter circuit before the search completes. If the number is 0 or less, the search continues until its other limits are reached.
+func (i *IteratedLocalSearch) SetMaxStagnation(maxStagnation int) {
+	i.maxStagnation = maxStagnation
+}
+
+// SetPerturbation sets how the circuit is perturbed at the start of each iteration.
+func (i *IteratedLocalSearch) SetPerturbation(perturbation PerturbationType) {
+	i.perturbation = perturbation
+}
+
+// SetSeed sets the seed used to perturb the circuit and to decide whether to accept longer circuits. This is to facilitate consistent unit tests.
+func (i *IteratedLocalSearch) SetSeed(seed int64) {
+	i.random = rand.New(rand.NewSource(seed))
+}
+
+// Update advances the local search of the current iteration by one step. Once the circuit is a local optimum, this completes the iteration, and the next call to Update perturbs the circuit to start the next iteration.
+// The supplied vertex and edge are ignored.
+func (i *IteratedLocalSearch) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
+	if i.isComplete {
+		return
+	} else if i.startTime.IsZero() {
+		i.startTime = time.Now()
+	}
+
+	if !i.isDescending {
+		i.perturb()
+	} else if !i.descend(vertexToAdd, edgeToSplit) {
+		i.completeIteration()
+	}
+}
+
+// descend advances the local search of the current iteration by one step, and returns false once the circuit is a local optimum.
+func (i *IteratedLocalSearch) descend(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) bool {
+	s := i.search
+	if i.improver == nil || len(s.tour) < 8 {
+		if s.queueLen() == 0 {
+			return false
+		}
+		i.localSearchCircuit.Update(vertexToAdd, edgeToSplit)
+		return true
+	}
+
+	if i.inner == nil {
+		i.inner = i.improver(s.getCircuit())
+	}
+	if next, edge := i.inner.FindNextVertexAndEdge(); next != nil {
+		i.inner.Update(next, edge)
+		return true
+	}
+	improved := i.inner.GetAttachedVertices()
+	i.inner = nil
+	if tour := i.toTour(improved); tour != nil {
+		s.setTour(tour, model.Length(improved))
+	}
+	return false
+}
+
+// toTour converts the improver's circuit into vertex indices, or returns nil if it is not a permutation of the search's vertices, in which case the current tour is retained.
+func (i *IteratedLocalSearch) toTour(improved []model.CircuitVertex) []int {
+	if len(improved) != len(i.search.tour) {
+		return nil
+	}
+	tour := make([]int, len(improved))
+	isVisited := make([]bool, len(improved))
+	for p, v := range improved {
+		index, okay := i.indices[v]
+		if !okay || isVisited[index] {
+			return nil
+		}
+		isVisited[index] = true
+		tour[p] = index
+	}
+	return tour
+}
+
+// completeIteration records the local optimum if it is the shortest circuit so far, decides whether to continue from it, and checks whether the search has reached any of its limits.
+func (i *IteratedLocalSearch) completeIteration() {
+	s := i.search
+	length := s.getLength()
+	if i.best == nil {
+		// The initial local optimum is always accepted, and determines the scale of the temperature.
+		i.temperature = iteratedLocalSearchTemperatureFactor * length / float64(len(s.tour))
+		i.best, i.bestLength = append([]int{}, s.tour...), length
+		i.accepted, i.acceptedLength = append([]int{}, s.tour...), length
+	} else {
+		if length < i.bestLength-model.Threshold {
+			i.best, i.bestLength = append(i.best[:0], s.tour...), length
+			i.bestCircuit = nil
+			i.numStagnant = 0
+		} else {
+			i.numStagnant++
+		}
+
+		if i.accept(length) {
+			i.accepted, i.acceptedLength = append(i.accepted[:0], s.tour...), length
+			i.numAccepted++
+		} else {
+			s.setTour(i.accepted, i.acceptedLength)
+		}
+	}
+
+	i.circuit = nil
+	i.isDescending = false
+	// Without any limits the search would never complete, so it completes at the first local optimum instead.
+	isUnlimited := i.maxIterations <= 0 && i.maxStagnation <= 0 && i.maxDuration <= 0
+	i.isComplete = isUnlimited || (i.maxIterations > 0 && i.numIterations >= i.maxIterations) || len(s.tour) < 8 ||
+		(i.maxStagnation > 0 && i.numStagnant >= i.maxStagnation) ||
+		(i.maxDuration > 0 && time.Since(i.startTime) >= i.maxDuration)
+}
+
+// perturb perturbs the accepted circuit, and adds the vertices whose edges changed to the queue.
+func (i *IteratedLocalSearch) perturb() {
+	i.numIterations++
+	if i.perturbation == PerturbationSegmentReversal {
+		i.applySegmentReversal()
+	} else {
+		i.search.applyDoubleBridge(i.random)
+	}
+	i.circuit = nil
+	i.isDescending = true
+}
+
+// applySegmentReversal reverses a random segment of the circuit, containing between 2 and doubleBridgeMaxSegmentLength vertices, and adds the vertices whose edges changed to the queue.
+func (i *IteratedLocalSearch) applySegmentReversal() {
+	s := i.search
+	numVertices := len(s.tour)
+	maxSegmentLength := doubleBridgeMaxSegmentLength
+	if numVertices-2 < maxSegmentLength {
+		maxSegmentLength = numVertices - 2
+	}
+	segmentLength := 2 + i.random.Intn(maxSegmentLength-1)
+	start := i.random.Intn(numVertices)
+	a, b := s.tour[(start+numVertices-1)%numVertices], s.tour[start]
+	c, d := s.tour[(start+segmentLength-1)%numVertices], s.tour[(start+segmentLength)%numVertices]
+	s.applyTwoOpt(a, b, c, d, true)
+}
+
+// accept returns true if the search should continue from a local optimum with the supplied length, rather than returning to the accepted circuit.
+func (i *IteratedLocalSearch) accept(length float64) bool {
+	return isAccepted(i.acceptance, length, i.acceptedLength, i.temperature*(1.0-i.getProgress()), i.random)
+}
+
+// isAccepted returns true if a search with the supplied acceptance should continue from a circuit with the supplied length, rather than returning to its current circuit.
+// The temperature is only used by AcceptanceAnnealing, which rejects every longer circuit once the temperature reaches 0.
+func isAccepted(acceptance AcceptanceType, length float64, currentLength float64, temperature float64, random *rand.Rand) bool {
+	switch acceptance {
+	case AcceptanceRandomWalk:
+		return true
+	case AcceptanceAnnealing:
+		if length < currentLength+model.Threshold {
+			return true
+		}
+		return temperature > 0 && random.Float64() < math.Exp((currentLength-length)/temperature)
+	default:
+		return length < currentLength+model.Threshold
+	}
+}
+
+// getProgress returns the fraction of the search that has been completed, based on the number of iterations and the time limit, whichever is closer to being reached.
+// If neither is limited, the progress is always 0, since the search only ends once it stagnates.
+func (i *IteratedLocalSearch) getProgress() float64 {
+	progress := 0.0
+	if i.maxIterations > 0 {
+		progress = float64(i.numIterations) / float64(i.maxIterations)
+	}
+	if i.maxDuration > 0 {
+		progress = math.Max(progress, float64(time.Since(i.startTime))/float64(i.maxDuration))
+	}
+	return math.Min(progress, 1.0)
+}
+
+var _ model.Circuit = (*IteratedLocalSearch)(nil)
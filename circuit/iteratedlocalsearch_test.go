@@ -0,0 +1,280 @@
+package circuit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIteratedLocalSearch(t *testing.T) {
+	assert := assert.New(t)
+
+	// The initial circuit crosses itself, between (0,0)-(10,10) and (10,0)-(0,10).
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(0, 10),
+	}
+	c := circuit.NewIteratedLocalSearch(vertices, 0, 10)
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(vertices), c.GetLength(), model.Threshold)
+
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Equal(vertices[0], next)
+	assert.Nil(edge)
+	for ; next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+
+	// Perturbations require at least 8 vertices, so no iterations are run.
+	assert.Equal(1, c.GetNumImprovements())
+	assert.Equal(0, c.GetNumIterations())
+	assert.InDelta(40.0, c.GetLength(), model.Threshold)
+	assert.InDelta(40.0, model.Length(c.GetAttachedVertices()), model.Threshold)
+	assert.Len(c.GetUnattachedVertices(), 0)
+}
+
+func TestIteratedLocalSearch_ShouldMatchOptimalForSmallCircuits(t *testing.T) {
+	assert := assert.New(t)
+
+	for i := 0; i < 5; i++ {
+		vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(9))
+		c := circuit.NewIteratedLocalSearch(vertices, 0, 50)
+		c.SetSeed(int64(i))
+		solver.FindShortestPathCircuit(c)
+
+		_, optimalLength := solver.FindShortestPathNPHeap(vertices)
+		assert.Len(c.GetAttachedVertices(), len(vertices))
+		assert.Equal(50, c.GetNumIterations())
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+		assert.InDelta(optimalLength, c.GetLength(), model.Threshold)
+	}
+}
+
+func TestIteratedLocalSearch_ShouldBeConsistentWithSeed(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(200))
+	// The time limit is checked at the end of each iteration, so this stops at the first local optimum.
+	initial := circuit.NewIteratedLocalSearch(vertices, 8, 0)
+	initial.SetMaxDuration(time.Nanosecond)
+	solver.FindShortestPathCircuit(initial)
+	assert.Equal(0, initial.GetNumIterations())
+
+	for _, perturbation := range []circuit.PerturbationType{circuit.PerturbationDoubleBridge, circuit.PerturbationSegmentReversal} {
+		for _, acceptance := range []circuit.AcceptanceType{circuit.AcceptanceBetter, circuit.AcceptanceRandomWalk, circuit.AcceptanceAnnealing} {
+			var expected []model.CircuitVertex
+			for i := 0; i < 2; i++ {
+				c := circuit.NewIteratedLocalSearch(vertices, 8, 100)
+				c.SetAcceptance(acceptance)
+				c.SetPerturbation(perturbation)
+				c.SetSeed(3)
+				solver.FindShortestPathCircuit(c)
+				assert.Equal(100, c.GetNumIterations())
+				assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+				// The best circuit is retained, so the iterations can only shorten the circuit.
+				assert.LessOrEqual(c.GetLength(), initial.GetLength()+model.Threshold)
+				if expected == nil {
+					expected = c.GetAttachedVertices()
+				} else {
+					assert.Equal(expected, c.GetAttachedVertices())
+				}
+			}
+		}
+	}
+}
+
+func TestIteratedLocalSearch_Acceptance(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(100))
+
+	c := circuit.NewIteratedLocalSearch(vertices, 8, 50)
+	c.SetAcceptance(circuit.AcceptanceRandomWalk)
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(50, c.GetNumAccepted())
+
+	c = circuit.NewIteratedLocalSearch(vertices, 8, 50)
+	c.SetAcceptance(circuit.AcceptanceBetter)
+	solver.FindShortestPathCircuit(c)
+	assert.LessOrEqual(c.GetNumAccepted(), 50)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+}
+
+func TestIteratedLocalSearch_Limits(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(100))
+
+	c := circuit.NewIteratedLocalSearch(vertices, 8, 100000)
+	c.SetMaxStagnation(5)
+	solver.FindShortestPathCircuit(c)
+	assert.GreaterOrEqual(c.GetNumIterations(), 5)
+	assert.Less(c.GetNumIterations(), 100000)
+
+	c = circuit.NewIteratedLocalSearch(vertices, 8, 100000000)
+	c.SetMaxDuration(20 * time.Millisecond)
+	start := time.Now()
+	solver.FindShortestPathCircuit(c)
+	assert.Less(time.Since(start), 5*time.Second)
+	assert.Greater(c.GetNumIterations(), 0)
+	assert.Less(c.GetNumIterations(), 100000000)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+}
+
+func TestIteratedLocalSearch_ShouldOnlyBeLimitedByTime(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(100))
+	initial := circuit.NewIteratedLocalSearch(vertices, 8, 0)
+	initial.SetMaxDuration(time.Nanosecond)
+	solver.FindShortestPathCircuit(initial)
+	assert.Equal(0, initial.GetNumIterations())
+
+	// Without an iteration limit, the annealing temperature decreases based on the time limit alone.
+	for _, maxIterations := range []int{0, -1} {
+		c := circuit.NewIteratedLocalSearch(vertices, 8, maxIterations)
+		c.SetAcceptance(circuit.AcceptanceAnnealing)
+		c.SetMaxDuration(50 * time.Millisecond)
+		start := time.Now()
+		solver.FindShortestPathCircuit(c)
+		assert.GreaterOrEqual(time.Since(start), 50*time.Millisecond)
+		assert.Less(time.Since(start), 5*time.Second)
+		assert.Greater(c.GetNumIterations(), 1)
+		assert.LessOrEqual(c.GetLength(), initial.GetLength()+model.Threshold)
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	}
+}
+
+func TestIteratedLocalSearch_SimulatedAnnealing(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(100))
+	initialLength := model.Length(vertices)
+
+	numImprovers := 0
+	c := circuit.NewIteratedLocalSearch(vertices, 8, 10)
+	c.SetImprover(func(circuitVertices []model.CircuitVertex) model.Circuit {
+		numImprovers++
+		annealing := circuit.NewSimulatedAnnealing(circuitVertices, 2000, true)
+		annealing.SetSeed(int64(numImprovers))
+		return annealing
+	})
+	c.SetSeed(1)
+	solver.FindShortestPathCircuit(c)
+
+	// The initial circuit is also improved by the improver, rather than by 2-opt and Or-opt.
+	assert.Equal(11, numImprovers)
+	assert.Equal(0, c.GetNumImprovements())
+	assert.Equal(10, c.GetNumIterations())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+}
+
+func TestIteratedLocalSearch_ShouldDiscardInvalidImproverCircuits(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+	for _, invalid := range []func([]model.CircuitVertex) []model.CircuitVertex{
+		func(cv []model.CircuitVertex) []model.CircuitVertex { return cv[1:] },
+		func(cv []model.CircuitVertex) []model.CircuitVertex {
+			return append(append([]model.CircuitVertex{}, cv...), cv[0])
+		},
+		func(cv []model.CircuitVertex) []model.CircuitVertex {
+			return append([]model.CircuitVertex{cv[1]}, cv[1:]...)
+		},
+		func(cv []model.CircuitVertex) []model.CircuitVertex {
+			return append([]model.CircuitVertex{model2d.NewVertex2D(-1, -1)}, cv[1:]...)
+		},
+	} {
+		c := circuit.NewIteratedLocalSearch(vertices, 8, 5)
+		c.SetImprover(func(circuitVertices []model.CircuitVertex) model.Circuit {
+			improved := invalid(append([]model.CircuitVertex{}, circuitVertices...))
+			return &circuit.CompletedCircuit{Circuit: improved, Length: model.Length(improved)}
+		})
+		c.SetSeed(1)
+		solver.FindShortestPathCircuit(c)
+
+		assert.Equal(5, c.GetNumIterations())
+		assert.ElementsMatch(vertices, c.GetAttachedVertices())
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	}
+}
+
+func TestIteratedLocalSearch_ShouldCompleteWithoutLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(100))
+	initialLength := model.Length(vertices)
+	for _, maxIterations := range []int{0, -1} {
+		c := circuit.NewIteratedLocalSearch(vertices, 8, maxIterations)
+		solver.FindShortestPathCircuit(c)
+		assert.Equal(0, c.GetNumIterations())
+		assert.Less(c.GetLength(), initialLength)
+	}
+}
+
+func TestIteratedLocalSearch_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model3d.GenerateVertices(100)
+	initialLength := model.Length(vertices)
+	c := circuit.NewIteratedLocalSearch(vertices, 8, 50)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+}
+
+func TestIteratedLocalSearch_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    5,
+		MinEdges:    2,
+		NumVertices: 30,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+	initialLength := model.Length(vertices)
+
+	c := circuit.NewIteratedLocalSearch(vertices, 5, 30)
+	c.SetAcceptance(circuit.AcceptanceAnnealing)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Equal(30, c.GetNumIterations())
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+}
+
+func TestIteratedLocalSearch_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	c := circuit.NewIteratedLocalSearch([]model.CircuitVertex{}, 5, 10)
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+	assert.Len(c.GetAttachedVertices(), 0)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(1, 2),
+		model2d.NewVertex2D(3, 2),
+		model2d.NewVertex2D(1, 5),
+	}
+	c = circuit.NewIteratedLocalSearch(append(vertices, vertices[0]), 5, 10)
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.Equal(0, c.GetNumImprovements())
+	assert.Equal(0, c.GetNumIterations())
+}
@@ -0,0 +1,270 @@
+package circuit
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// linKernighanBreadth is the number of alternatives for the first added edge of each move that LinKernighan tries, before giving up on a vertex.
+const linKernighanBreadth = 5
+
+// linKernighanMaxDepth is the maximum number of edges that LinKernighan replaces in a single move.
+const linKernighanMaxDepth = 50
+
+// LinKernighan implements a variant of the [Lin-Kernighan heuristic](https://en.wikipedia.org/wiki/Lin%E2%80%93Kernighan_heuristic), which deterministically improves a completed circuit with variable-depth (k-opt) moves,
+// and then repeatedly perturbs the circuit with double-bridge kicks to escape its local optimum (i.e. Chained Lin-Kernighan).
+//
+// Each move starts by removing an edge (t1,t2) from the circuit, then repeatedly adds an edge from t2 to one of its candidates t3 and removes an edge (t3,t4) from t3, so that reconnecting t4 to t1 produces a circuit (i.e. a 2-opt move).
+// The move continues from t4, as long as the total length of the removed edges exceeds the total length of the added edges (the gain criterion), and an edge that was added by the move is never removed by it.
+// Once no edge can be added, or linKernighanMaxDepth edges have been replaced, the move is undone back to the shortest circuit that it produced, if it is shorter than the original circuit.
+// Each edge added after the first is the one that maximizes the length of the edge it removes minus the length of the edge it adds; if no move improves the circuit, linKernighanBreadth alternatives are tried for the first added edge.
+//
+// Like TwoOpt, moves only add edges to the nearest neighbors (candidates) of each vertex, and don't-look bits limit each pass to the parts of the circuit that changed.
+// Once no move improves the circuit, each trial applies a double-bridge kick, which reorders three short adjacent segments of the circuit (A-B-C-D becomes A-D-C-B), then improves the circuit from the vertices that the kick affected.
+// The double-bridge replaces four edges, in a way that is not a sequential move, so the moves are unlikely to simply undo it. If a trial produces a longer circuit, it is undone.
+//
+// Each call to Update either checks one vertex for an improving move, or starts or completes a trial. The circuit is complete (FindNextVertexAndEdge returns nil) once every trial is complete.
+// For asymmetric graphs, moves are evaluated as though the distances are symmetric, but trials are only kept if they do not increase the actual length of the circuit.
+type LinKernighan struct {
+	*localSearchCircuit
+	inTrial          bool
+	journal          []reversal
+	maxTrials        int
+	numTrials        int
+	numTrialsKept    int
+	random           *rand.Rand
+	touched          []int
+	trialJournalLen  int
+	trialStartLength float64
+}
+
+// linKernighanStep is a candidate step of a Lin-Kernighan move, which adds the edge (t2,t3) and removes the edge (t3,t4).
+type linKernighanStep struct {
+	gain float64
+	t3   int
+	t4   int
+}
+
+// NewLinKernighan creates a LinKernighan circuit that improves the supplied circuit, considering edges to the supplied number of candidate neighbors of each vertex, for the supplied number of trials (kicks).
+// If the number of neighbors is less than 1, LocalSearchDefaultNeighbors are used, and if the number of trials is negative, one trial per vertex is used. Duplicate references to the same vertex are ignored.
+// Use SetSeed for consistent results.
+func NewLinKernighan(circuit []model.CircuitVertex, numNeighbors int, maxTrials int) *LinKernighan {
+	if numNeighbors < 1 {
+		numNeighbors = LocalSearchDefaultNeighbors
+	}
+	search := newLocalSearch(circuit, numNeighbors)
+	if maxTrials < 0 {
+		maxTrials = len(search.vertices)
+	}
+	l := &LinKernighan{
+		maxTrials: maxTrials,
+		random:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	l.localSearchCircuit = &localSearchCircuit{
+		improve: l.improve,
+		search:  search,
+	}
+	return l
+}
+
+// NewLinKernighanFromCircuit completes the supplied circuit, then creates a LinKernighan circuit that improves it.
+func NewLinKernighanFromCircuit(circuit model.Circuit, numNeighbors int, maxTrials int) *LinKernighan {
+	return NewLinKernighan(attachAll(circuit), numNeighbors, maxTrials)
+}
+
+// FindNextVertexAndEdge returns (nil, nil) once every trial is complete, otherwise it returns the first vertex in the circuit, which is ignored by Update.
+func (l *LinKernighan) FindNextVertexAndEdge() (model.CircuitVertex, model.CircuitEdge) {
+	if l.search.queueLen() == 0 && !l.inTrial && (l.numTrials >= l.maxTrials || len(l.search.tour) < 8) {
+		return nil, nil
+	}
+	return l.search.vertices[l.search.tour[0]], nil
+}
+
+// GetMaxTrials returns the number of double-bridge kicks that this applies once no move improves the circuit.
+func (l *LinKernighan) GetMaxTrials() int {
+	return l.maxTrials
+}
+
+// GetNumTrials returns the number of trials that have been started.
+func (l *LinKernighan) GetNumTrials() int {
+	return l.numTrials
+}
+
+// GetNumTrialsKept returns the number of trials that did not increase the length of the circuit, and were kept.
+func (l *LinKernighan) GetNumTrialsKept() int {
+	return l.numTrialsKept
+}
+
+// SetSeed sets the seed used to select the segments of each double-bridge kick. This is to facilitate consistent unit tests.
+func (l *LinKernighan) SetSeed(seed int64) {
+	l.random = rand.New(rand.NewSource(seed))
+}
+
+// Update checks the next vertex in the queue for an improving move, and applies it if one is found.
+// Once the queue is empty, this either completes the current trial, undoing it if it increased the length of the circuit, or starts the next trial. The supplied vertex and edge are ignored.
+func (l *LinKernighan) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
+	if l.search.queueLen() > 0 {
+		l.localSearchCircuit.Update(vertexToAdd, edgeToSplit)
+		// Moves only need to be undone during a trial, so the journal is discarded otherwise.
+		if !l.inTrial {
+			l.journal = l.journal[:0]
+		}
+	} else if l.inTrial {
+		l.completeTrial()
+	} else if l.numTrials < l.maxTrials && len(l.search.tour) >= 8 {
+		l.startTrial()
+	}
+}
+
+// completeTrial keeps the current circuit if it is not longer than the circuit at the start of the trial, otherwise it undoes every reversal since the start of the trial.
+func (l *LinKernighan) completeTrial() {
+	if l.search.getLength() < l.trialStartLength+model.Threshold {
+		l.numTrialsKept++
+	} else {
+		l.undo(l.trialJournalLen)
+	}
+	l.circuit = nil
+	l.inTrial = false
+	l.journal = l.journal[:0]
+}
+
+// startTrial applies a double-bridge kick to the circuit, and records its reversals so that the trial can be undone.
+func (l *LinKernighan) startTrial() {
+	l.inTrial = true
+	l.numTrials++
+	l.trialJournalLen = len(l.journal)
+	l.trialStartLength = l.search.getLength()
+	l.journal = append(l.journal, l.search.applyDoubleBridge(l.random)...)
+	l.circuit = nil
+}
+
+// undo undoes the reversals in the journal, from the most recent, until the journal only contains the supplied number of reversals.
+func (l *LinKernighan) undo(journalLen int) {
+	for len(l.journal) > journalLen {
+		r := l.journal[len(l.journal)-1]
+		l.journal = l.journal[:len(l.journal)-1]
+		l.search.reverseExactly(r.i, r.j)
+		l.search.length -= r.lengthChange
+	}
+}
+
+// improve looks for a Lin-Kernighan move that starts by removing either edge of the supplied vertex, and applies the first one that reduces the cost of the circuit.
+func (l *LinKernighan) improve(t1 int) bool {
+	s := l.search
+	if len(s.tour) < 4 {
+		return false
+	}
+	for _, t2 := range []int{s.next(t1), s.previous(t1)} {
+		gain := s.cost(t1, t2)
+		steps := l.findSteps(t1, t2, gain, nil)
+		if len(steps) > linKernighanBreadth {
+			steps = steps[:linKernighanBreadth]
+		}
+		for _, step := range steps {
+			if l.applyMove(t1, t2, step) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyMove applies the supplied first step of a move, then repeatedly applies the best next step, until no step satisfies the gain criterion or linKernighanMaxDepth steps have been applied.
+// It then undoes the steps after the shortest circuit that was produced, and returns true if that circuit is shorter than the original circuit, otherwise it undoes every step and returns false.
+func (l *LinKernighan) applyMove(t1 int, t2 int, step linKernighanStep) bool {
+	s := l.search
+	journalLen := len(l.journal)
+	bestGain, bestJournalLen, bestTouchedLen := model.Threshold, -1, 0
+	added := [][2]int{}
+	l.touched = append(l.touched[:0], t1)
+	for depth := 1; ; depth++ {
+		l.applyStep(t1, t2, step.t3, step.t4)
+		added = append(added, [2]int{t2, step.t3})
+		l.touched = append(l.touched, t2, step.t3, step.t4)
+		if closedGain := step.gain - s.cost(step.t4, t1); closedGain > bestGain {
+			bestGain, bestJournalLen, bestTouchedLen = closedGain, len(l.journal), len(l.touched)
+		}
+		if depth >= linKernighanMaxDepth {
+			break
+		}
+		t2 = step.t4
+		steps := l.findSteps(t1, t2, step.gain, added)
+		if len(steps) == 0 {
+			break
+		}
+		step = steps[0]
+	}
+
+	if bestJournalLen < 0 {
+		l.undo(journalLen)
+		return false
+	}
+	l.undo(bestJournalLen)
+	for _, v := range l.touched[:bestTouchedLen] {
+		s.push(v)
+	}
+	l.circuit = nil
+	return true
+}
+
+// applyStep replaces the edges (t1,t2) and (t3,t4) with the edges (t2,t3) and (t4,t1), by reversing the part of the circuit from t2 to t4.
+func (l *LinKernighan) applyStep(t1 int, t2 int, t3 int, t4 int) {
+	s := l.search
+	lengthChange := 0.0
+	if s.symmetric {
+		lengthChange = s.distance(t2, t3) + s.distance(t4, t1) - s.distance(t1, t2) - s.distance(t3, t4)
+		s.length += lengthChange
+	}
+	var i, j int
+	if t2 == s.next(t1) {
+		i, j = s.reverse(s.position[t2], s.position[t4])
+	} else {
+		i, j = s.reverse(s.position[t4], s.position[t2])
+	}
+	l.journal = append(l.journal, reversal{i: i, j: j, lengthChange: lengthChange})
+}
+
+// findSteps returns the steps from t2 that satisfy the gain criterion, sorted from best to worst.
+// The edge (t1,t2) is the edge that the previous step added to close the circuit, and the supplied gain is the total cost of the edges removed by the move, minus the total cost of the edges it added, excluding (t1,t2).
+// The edge (t3,t4) removed by each step must not have been added by the move, and t4 is the neighbor of t3 such that reconnecting t4 to t1 produces a circuit.
+func (l *LinKernighan) findSteps(t1 int, t2 int, gain float64, added [][2]int) []linKernighanStep {
+	s := l.search
+	forward := t2 == s.next(t1)
+	steps := []linKernighanStep{}
+	for _, t3 := range s.candidates[t2] {
+		remainingGain := gain - s.cost(t2, t3)
+		if remainingGain <= model.Threshold {
+			break
+		}
+		if t3 == t1 || t3 == s.next(t2) || t3 == s.previous(t2) {
+			continue
+		}
+		t4 := s.next(t3)
+		if forward {
+			t4 = s.previous(t3)
+		}
+		if containsEdge(added, t3, t4) {
+			continue
+		}
+		steps = append(steps, linKernighanStep{gain: remainingGain + s.cost(t3, t4), t3: t3, t4: t4})
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].gain > steps[j].gain
+	})
+	return steps
+}
+
+// containsEdge returns true if the edge between a and b is in the supplied list of edges, in either direction.
+func containsEdge(edges [][2]int, a int, b int) bool {
+	for _, edge := range edges {
+		if (edge[0] == a && edge[1] == b) || (edge[0] == b && edge[1] == a) {
+			return true
+		}
+	}
+	return false
+}
+
+var _ model.Circuit = (*LinKernighan)(nil)
@@ -0,0 +1,165 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/heustis/tsp-solver-go/tsplib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinKernighan(t *testing.T) {
+	assert := assert.New(t)
+
+	// The initial circuit crosses itself, between (0,0)-(10,10) and (10,0)-(0,10).
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(0, 10),
+	}
+	c := circuit.NewLinKernighan(vertices, 2, -1)
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(vertices), c.GetLength(), model.Threshold)
+	assert.Equal(4, c.GetMaxTrials())
+
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Equal(vertices[0], next)
+	assert.Nil(edge)
+	for ; next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+
+	// Kicks require at least 8 vertices, so no trials are run.
+	assert.Equal(1, c.GetNumImprovements())
+	assert.Equal(0, c.GetNumTrials())
+	assert.InDelta(40.0, c.GetLength(), model.Threshold)
+	assert.InDelta(40.0, model.Length(c.GetAttachedVertices()), model.Threshold)
+	assert.Len(c.GetUnattachedVertices(), 0)
+}
+
+func TestLinKernighan_ShouldMatchOptimalForSmallCircuits(t *testing.T) {
+	assert := assert.New(t)
+
+	for i := 0; i < 5; i++ {
+		vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(9))
+		c := circuit.NewLinKernighan(vertices, 0, 20)
+		c.SetSeed(int64(i))
+		solver.FindShortestPathCircuit(c)
+
+		_, optimalLength := solver.FindShortestPathNPHeap(vertices)
+		assert.Len(c.GetAttachedVertices(), len(vertices))
+		assert.Equal(20, c.GetNumTrials())
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+		assert.InDelta(optimalLength, c.GetLength(), model.Threshold)
+	}
+}
+
+func TestLinKernighan_ShouldBeConsistentWithSeed(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(200))
+	initial := circuit.NewLinKernighan(vertices, 8, 0)
+	solver.FindShortestPathCircuit(initial)
+	assert.Equal(0, initial.GetNumTrials())
+
+	var expected []model.CircuitVertex
+	for i := 0; i < 2; i++ {
+		c := circuit.NewLinKernighan(vertices, 8, 100)
+		c.SetSeed(3)
+		solver.FindShortestPathCircuit(c)
+		assert.Equal(100, c.GetNumTrials())
+		assert.Greater(c.GetNumTrialsKept(), 0)
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+		// Trials that increase the length are undone, so the kicks can only shorten the circuit.
+		assert.LessOrEqual(c.GetLength(), initial.GetLength()+model.Threshold)
+		if expected == nil {
+			expected = c.GetAttachedVertices()
+		} else {
+			assert.Equal(expected, c.GetAttachedVertices())
+		}
+	}
+}
+
+func TestLinKernighan_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model3d.GenerateVertices(200)
+	initialLength := model.Length(vertices)
+	c := circuit.NewLinKernighan(vertices, 8, 50)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+}
+
+func TestLinKernighan_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    5,
+		MinEdges:    2,
+		NumVertices: 30,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+	initialLength := model.Length(vertices)
+
+	c := circuit.NewLinKernighan(vertices, 5, 30)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Equal(30, c.GetNumTrials())
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+}
+
+func TestLinKernighan_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	c := circuit.NewLinKernighan([]model.CircuitVertex{}, 5, 10)
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+	assert.Len(c.GetAttachedVertices(), 0)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(1, 2),
+		model2d.NewVertex2D(3, 2),
+		model2d.NewVertex2D(1, 5),
+	}
+	c = circuit.NewLinKernighan(append(vertices, vertices[0]), 5, 10)
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.Equal(0, c.GetNumImprovements())
+	assert.Equal(0, c.GetNumTrials())
+}
+
+func TestLinKernighan_TsplibOptimalTours(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large data sets in short mode")
+	}
+	assert := assert.New(t)
+
+	for _, name := range []string{"pcb442", "pr2392"} {
+		data, err := tsplib.NewData("../test-data/tsplib/" + name + ".tsp")
+		assert.Nil(err)
+		vertices := data.GetVertices()
+
+		c := circuit.NewLinKernighanFromCircuit(circuit.NewGreedyEdge(vertices), circuit.LocalSearchDefaultNeighbors, -1)
+		c.SetSeed(1)
+		solver.FindShortestPathCircuit(c)
+		t.Logf("%s: %d trials, %d kept, %.2f%% above optimal", name, c.GetNumTrials(), c.GetNumTrialsKept(), 100*(c.GetLength()/data.GetBestRouteLength()-1))
+
+		assert.Len(c.GetAttachedVertices(), len(vertices))
+		assert.Equal(len(vertices), c.GetNumTrials())
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), 1e-6*c.GetLength())
+		assert.Less(c.GetLength(), 1.02*data.GetBestRouteLength(), name)
+	}
+}
@@ -0,0 +1,585 @@
+package circuit
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/spatial"
+)
+
+// LocalSearchDefaultNeighbors is the number of candidate neighbors of each vertex that the local search circuits (e.g. TwoOpt) consider when looking for an improving move.
+const LocalSearchDefaultNeighbors = 16
+
+// doubleBridgeMaxSegmentLength is the maximum number of vertices in each of the segments that a double-bridge kick reorders.
+const doubleBridgeMaxSegmentLength = 50
+
+// localSearch contains the state shared by the local search circuits, which repeatedly apply improving moves to a completed circuit until no move improves it.
+// Vertices are referenced by their index in vertices, and the circuit is stored as the order of those indices (tour), along with the position of each vertex in the tour,
+// so that the neighbors of a vertex in the circuit are found in O(1).
+//
+// Moves are only evaluated between a vertex and its candidate neighbors (see findCandidatesWithTree), and [don't-look bits](https://en.wikipedia.org/wiki/2-opt) limit the search to the vertices in the queue:
+// a vertex is removed from the queue once no move from it improves the circuit, and is only added back once one of its edges in the circuit changes.
+type localSearch struct {
+	candidates [][]int
+	// candidatesSortedByCost is true if each vertex's candidates are sorted by the cost of the edge to them, so that the moves can stop checking candidates once an edge is too expensive.
+	// This is false once the cost is overridden (e.g. by guided local search), since the candidates are sorted by the original cost.
+	candidatesSortedByCost bool
+	// cost returns the cost of the edge between two vertices, which the moves try to minimize. This is the distance between the vertices, unless overridden (e.g. by guided local search).
+	cost func(a int, b int) float64
+	// costIsDistance is true if the cost of each edge is the distance between its vertices, so that insertions can be evaluated with model.CircuitEdge.DistanceIncrease.
+	costIsDistance bool
+	inQueue        []bool
+	length         float64
+	// lengthIsStale is true if the length needs to be recomputed, because the distances are asymmetric, so the change in length is not tracked for each move.
+	lengthIsStale bool
+	position      []int
+	queue         []int
+	queueStart    int
+	symmetric     bool
+	tour          []int
+	vertices      []model.CircuitVertex
+}
+
+// reversal records a reversal of the tour, and the change in length it caused, so that it can be undone.
+type reversal struct {
+	i            int
+	j            int
+	lengthChange float64
+}
+
+// localSearchCircuit implements model.Circuit for the local search circuits, which differ only in the moves that they apply (improve).
+// Each call to Update checks the next vertex in the queue for an improving move, and the circuit is complete once the queue is empty.
+type localSearchCircuit struct {
+	circuit         []model.CircuitVertex
+	improve         func(a int) bool
+	numImprovements int
+	search          *localSearch
+}
+
+// attachAll attaches any unattached vertices of the supplied circuit, and returns its attached vertices, so that a local search can start from it.
+func attachAll(circuit model.Circuit) []model.CircuitVertex {
+	for nextVertex, nextEdge := circuit.FindNextVertexAndEdge(); nextVertex != nil; nextVertex, nextEdge = circuit.FindNextVertexAndEdge() {
+		circuit.Update(nextVertex, nextEdge)
+	}
+	return circuit.GetAttachedVertices()
+}
+
+// FindNextVertexAndEdge returns (nil, nil) once no vertex has an improving move, otherwise it returns the first vertex in the circuit, which is ignored by Update.
+func (l *localSearchCircuit) FindNextVertexAndEdge() (model.CircuitVertex, model.CircuitEdge) {
+	if l.search.queueLen() == 0 {
+		return nil, nil
+	}
+	return l.search.vertices[l.search.tour[0]], nil
+}
+
+func (l *localSearchCircuit) GetAttachedVertices() []model.CircuitVertex {
+	if l.circuit == nil {
+		l.circuit = l.search.getCircuit()
+	}
+	return l.circuit
+}
+
+func (l *localSearchCircuit) GetLength() float64 {
+	return l.search.getLength()
+}
+
+// GetNumImprovements returns the number of moves that have been applied to the circuit.
+func (l *localSearchCircuit) GetNumImprovements() int {
+	return l.numImprovements
+}
+
+func (l *localSearchCircuit) GetUnattachedVertices() map[model.CircuitVertex]bool {
+	return make(map[model.CircuitVertex]bool)
+}
+
+// Update checks the next vertex in the queue for an improving move, and applies it if one is found. The supplied vertex and edge are ignored.
+func (l *localSearchCircuit) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
+	if a, okay := l.search.pop(); okay && l.improve(a) {
+		l.numImprovements++
+		l.circuit = nil
+	}
+}
+
+// newLocalSearch creates the local search state for the supplied circuit, with the supplied number of neighbors of each vertex as candidates.
+// If the number of neighbors is less than 1, every other vertex is a candidate. Duplicate references to the same vertex are ignored.
+//
+// 2D and 3D vertices find their candidates with a k-d tree (see findCandidatesWithTree). Other vertices (e.g. graphs) compare every pair of vertices to find their neighbors, and since their distances may be asymmetric,
+// moves are evaluated with the distance from the lower-indexed vertex to the higher-indexed vertex, so moves that reverse part of the circuit may not reduce its length for asymmetric graphs.
+func newLocalSearch(circuit []model.CircuitVertex, numNeighbors int) *localSearch {
+	vertices := uniqueVertices(circuit)
+	numVertices := len(vertices)
+	s := &localSearch{
+		inQueue:  make([]bool, numVertices),
+		position: make([]int, numVertices),
+		queue:    make([]int, 0, numVertices),
+		tour:     make([]int, numVertices),
+		vertices: vertices,
+	}
+	for i := range vertices {
+		s.tour[i] = i
+		s.position[i] = i
+		s.push(i)
+	}
+
+	tree, okay := spatial.NewKDTree(vertices)
+	s.symmetric = okay
+	s.candidatesSortedByCost = true
+	s.cost = s.distance
+	s.costIsDistance = okay
+	if numNeighbors < 1 || numNeighbors >= numVertices {
+		numNeighbors = numVertices - 1
+	}
+	if okay {
+		s.candidates = findCandidatesWithTree(vertices, tree, numNeighbors)
+	} else {
+		s.cost = s.symmetricDistance
+		s.candidates = s.findCandidates(numNeighbors)
+	}
+
+	s.length = model.Length(vertices)
+	return s
+}
+
+// distance returns the distance from the vertex at index a to the vertex at index b.
+func (s *localSearch) distance(a int, b int) float64 {
+	return s.vertices[a].DistanceTo(s.vertices[b])
+}
+
+// symmetricDistance returns the distance from the lower-indexed vertex to the higher-indexed vertex, so that it is the same in both directions.
+func (s *localSearch) symmetricDistance(a int, b int) float64 {
+	if a > b {
+		a, b = b, a
+	}
+	return s.vertices[a].DistanceTo(s.vertices[b])
+}
+
+// findCandidatesWithTree returns the candidates of each vertex, from closest to farthest, using the supplied k-d tree.
+// For 2D vertices, the candidates are the nearest numNeighbors/5 neighbors in each quadrant around the vertex (3 of the default 16), from among its nearest 2*numNeighbors neighbors,
+// then the nearest remaining neighbors until there are numNeighbors candidates. Otherwise, the candidates are the nearest neighbors of each vertex.
+//
+// Quadrant neighbors matter for structured instances (e.g. drilling grids), in which the nearest neighbors of a vertex are often collinear, or all on one side of it,
+// so the moves could never connect it to the other side.
+// Searching each quadrant of the k-d tree separately would be O(n) for every vertex with an empty quadrant (e.g. on the convex hull, or in collinear input), since empty quadrants cannot be pruned from the search.
+func findCandidatesWithTree(vertices []model.CircuitVertex, tree *spatial.KDTree, numNeighbors int) [][]int {
+	indices := make(map[model.CircuitVertex]int, len(vertices))
+	for i, v := range vertices {
+		indices[v] = i
+	}
+	perQuadrant := numNeighbors / 5
+	numNearest := 2 * numNeighbors
+	if numNeighbors >= len(vertices)-1 {
+		perQuadrant = 0
+		numNearest = numNeighbors
+	}
+	candidates := make([][]int, len(vertices))
+	for i, v := range vertices {
+		candidates[i] = make([]int, 0, numNeighbors)
+		// Each vertex is its own nearest neighbor, so it is excluded.
+		nearest := make([]int, 0, numNearest)
+		for _, neighbor := range tree.KNearest(v, numNearest+1) {
+			if j := indices[neighbor]; j != i {
+				nearest = append(nearest, j)
+			}
+		}
+
+		isCandidate := make(map[int]bool, numNeighbors)
+		if v2d, is2D := v.(*model2d.Vertex2D); is2D && perQuadrant > 0 {
+			var numInQuadrant [4]int
+			for _, j := range nearest {
+				if q := getQuadrant(v2d, vertices[j].(*model2d.Vertex2D)); q >= 0 && numInQuadrant[q] < perQuadrant {
+					numInQuadrant[q]++
+					candidates[i] = append(candidates[i], j)
+					isCandidate[j] = true
+				}
+			}
+		}
+		for _, j := range nearest {
+			if len(candidates[i]) >= numNeighbors {
+				break
+			} else if !isCandidate[j] {
+				candidates[i] = append(candidates[i], j)
+				isCandidate[j] = true
+			}
+		}
+
+		sort.SliceStable(candidates[i], func(x, y int) bool {
+			return v.DistanceTo(vertices[candidates[i][x]]) < v.DistanceTo(vertices[candidates[i][y]])
+		})
+	}
+	return candidates
+}
+
+// getQuadrant returns the quadrant (0-3, counterclockwise from the positive X axis) of the neighbor around the vertex, or -1 if they are at the same position.
+// Each quadrant includes one of its bounding half-axes, so that neighbors in the 4 directions along the axes (e.g. in a grid) are in different quadrants.
+func getQuadrant(v *model2d.Vertex2D, neighbor *model2d.Vertex2D) int {
+	dx, dy := neighbor.X-v.X, neighbor.Y-v.Y
+	switch {
+	case dx > 0 && dy >= 0:
+		return 0
+	case dx <= 0 && dy > 0:
+		return 1
+	case dx < 0 && dy <= 0:
+		return 2
+	case dx >= 0 && dy < 0:
+		return 3
+	default:
+		return -1
+	}
+}
+
+// findCandidates returns the indices of the nearest neighbors of each vertex, from closest to farthest, by comparing the cost of every pair of vertices.
+func (s *localSearch) findCandidates(numNeighbors int) [][]int {
+	numVertices := len(s.vertices)
+	candidates := make([][]int, numVertices)
+	for i := range s.vertices {
+		others := make([]int, 0, numVertices-1)
+		for j := range s.vertices {
+			if j != i {
+				others = append(others, j)
+			}
+		}
+		sort.SliceStable(others, func(x, y int) bool {
+			return s.cost(i, others[x]) < s.cost(i, others[y])
+		})
+		candidates[i] = others[:numNeighbors]
+	}
+	return candidates
+}
+
+// edgeKey returns a key that identifies the edge between the vertices at indices a and b, regardless of its direction.
+func (s *localSearch) edgeKey(a int, b int) int {
+	if a > b {
+		a, b = b, a
+	}
+	return a*len(s.vertices) + b
+}
+
+// getCircuit returns the vertices in the order of the tour.
+func (s *localSearch) getCircuit() []model.CircuitVertex {
+	return toVertices(s.vertices, s.tour)
+}
+
+// getLength returns the length of the circuit, using the actual distances between the vertices rather than the cost of the edges.
+func (s *localSearch) getLength() float64 {
+	if s.lengthIsStale {
+		s.length = model.Length(s.getCircuit())
+		s.lengthIsStale = false
+	}
+	return s.length
+}
+
+// setTour replaces the tour with the supplied order of vertex indices, whose actual length is supplied, and updates the position of each vertex.
+func (s *localSearch) setTour(tour []int, length float64) {
+	copy(s.tour, tour)
+	for i, a := range s.tour {
+		s.position[a] = i
+	}
+	s.length = length
+	s.lengthIsStale = false
+}
+
+// next returns the vertex after the supplied vertex in the tour.
+func (s *localSearch) next(a int) int {
+	return s.tour[(s.position[a]+1)%len(s.tour)]
+}
+
+// previous returns the vertex before the supplied vertex in the tour.
+func (s *localSearch) previous(a int) int {
+	return s.tour[(s.position[a]+len(s.tour)-1)%len(s.tour)]
+}
+
+// push adds the supplied vertex to the end of the queue, unless it is already in the queue.
+func (s *localSearch) push(a int) {
+	if !s.inQueue[a] {
+		s.inQueue[a] = true
+		s.queue = append(s.queue, a)
+	}
+}
+
+// pop removes and returns the vertex at the start of the queue, or returns false if the queue is empty.
+func (s *localSearch) pop() (int, bool) {
+	if s.queueStart >= len(s.queue) {
+		return -1, false
+	}
+	a := s.queue[s.queueStart]
+	s.queueStart++
+	s.inQueue[a] = false
+	// Reclaim the consumed part of the queue once it is at least half of the queue, so that the queue does not grow indefinitely.
+	if s.queueStart*2 >= len(s.queue) {
+		s.queue = append(s.queue[:0], s.queue[s.queueStart:]...)
+		s.queueStart = 0
+	}
+	return a, true
+}
+
+// queueLen returns the number of vertices in the queue.
+func (s *localSearch) queueLen() int {
+	return len(s.queue) - s.queueStart
+}
+
+// resetQueue adds every vertex to the queue, so that every vertex is checked for improving moves again (e.g. after the circuit or the costs are changed).
+func (s *localSearch) resetQueue() {
+	for _, a := range s.tour {
+		s.push(a)
+	}
+}
+
+// reverse reverses the part of the tour from the vertex at position i to the vertex at position j, inclusive, wrapping around the end of the tour if j < i.
+// Reversing either side of the tour produces the same circuit (in opposite directions), so this reverses whichever side is shorter, and returns the positions of the side that it reversed.
+func (s *localSearch) reverse(i int, j int) (int, int) {
+	numVertices := len(s.tour)
+	if inside := (j-i+numVertices)%numVertices + 1; 2*inside > numVertices {
+		i, j = (j+1)%numVertices, (i+numVertices-1)%numVertices
+	}
+	s.reverseExactly(i, j)
+	return i, j
+}
+
+// reverseExactly reverses the part of the tour from the vertex at position i to the vertex at position j, inclusive, wrapping around the end of the tour if j < i.
+// Unlike reverse, this always reverses the requested side, so that the positions of the other vertices are unchanged, and reversing the same positions again undoes it.
+func (s *localSearch) reverseExactly(i int, j int) {
+	numVertices := len(s.tour)
+	inside := (j-i+numVertices)%numVertices + 1
+	if !s.symmetric {
+		s.lengthIsStale = true
+	}
+	for k := 0; k < inside/2; k++ {
+		a, b := s.tour[i], s.tour[j]
+		s.tour[i], s.tour[j] = b, a
+		s.position[b], s.position[a] = i, j
+		i = (i + 1) % numVertices
+		j = (j + numVertices - 1) % numVertices
+	}
+}
+
+// applyDoubleBridge applies a double-bridge kick to three adjacent segments (B, C, and D) that start at a random position in the circuit, each containing up to doubleBridgeMaxSegmentLength vertices,
+// and adds the eight vertices whose edges changed to the queue. This requires at least 4 vertices, and returns the reversals that it applied, in order, so that the kick can be undone.
+func (s *localSearch) applyDoubleBridge(random *rand.Rand) []reversal {
+	numVertices := len(s.tour)
+	maxSegmentLength := doubleBridgeMaxSegmentLength
+	if (numVertices-1)/3 < maxSegmentLength {
+		maxSegmentLength = (numVertices - 1) / 3
+	}
+	lengths := [3]int{}
+	for i := range lengths {
+		lengths[i] = 1 + random.Intn(maxSegmentLength)
+	}
+	position := func(offset int) int {
+		return offset % numVertices
+	}
+	start := random.Intn(numVertices)
+	bStart, cStart, dStart := start+1, start+1+lengths[0], start+1+lengths[0]+lengths[1]
+	dEnd := dStart + lengths[2] - 1
+	a, b1, b2, c1, c2, d1, d2, e := s.tour[position(start)], s.tour[position(bStart)], s.tour[position(cStart-1)], s.tour[position(cStart)],
+		s.tour[position(dStart-1)], s.tour[position(dStart)], s.tour[position(dEnd)], s.tour[position(dEnd+1)]
+
+	lengthChange := 0.0
+	if s.symmetric {
+		lengthChange = s.distance(a, d1) + s.distance(d2, c1) + s.distance(c2, b1) + s.distance(b2, e) -
+			s.distance(a, b1) - s.distance(b2, c1) - s.distance(c2, d1) - s.distance(d2, e)
+		s.length += lengthChange
+	}
+	// Reversing B-C-D produces D'-C'-B', then reversing each segment restores its orientation, producing D-C-B.
+	reversals := []reversal{{i: position(bStart), j: position(dEnd), lengthChange: lengthChange}}
+	for _, segment := range [][2]int{{bStart, bStart + lengths[2] - 1}, {bStart + lengths[2], bStart + lengths[2] + lengths[1] - 1}, {bStart + lengths[2] + lengths[1], dEnd}} {
+		reversals = append(reversals, reversal{i: position(segment[0]), j: position(segment[1])})
+	}
+	for _, r := range reversals {
+		s.reverseExactly(r.i, r.j)
+	}
+	for _, v := range []int{a, b1, b2, c1, c2, d1, d2, e} {
+		s.push(v)
+	}
+	return reversals
+}
+
+// improveTwoOpt looks for 2-opt moves, from the supplied vertex to each of its candidates, that reduce the cost of the circuit, and applies the one that reduces it the most.
+// A 2-opt move replaces the edges (a,b) and (c,d) with the edges (a,c) and (b,d), by reversing the part of the circuit between them.
+// If the candidates are sorted by cost, the search stops once the new edge (a,c) costs at least as much as the edge (a,b) it replaces,
+// since any improving move with a more expensive edge (a,c) is found from one of the other vertices instead. Otherwise, such candidates are skipped.
+func (s *localSearch) improveTwoOpt(a int) bool {
+	if len(s.tour) < 4 {
+		return false
+	}
+	bestGain, bestB, bestC, bestD, bestForward := model.Threshold, -1, -1, -1, false
+	for _, forward := range []bool{true, false} {
+		b := s.previous(a)
+		if forward {
+			b = s.next(a)
+		}
+		costAB := s.cost(a, b)
+		for _, c := range s.candidates[a] {
+			gainAC := costAB - s.cost(a, c)
+			if gainAC <= model.Threshold {
+				if s.candidatesSortedByCost {
+					break
+				}
+				continue
+			}
+			d := s.previous(c)
+			if forward {
+				d = s.next(c)
+			}
+			if c == b || d == a {
+				continue
+			}
+			if gain := gainAC + s.cost(c, d) - s.cost(b, d); gain > bestGain {
+				bestGain, bestB, bestC, bestD, bestForward = gain, b, c, d, forward
+			}
+		}
+	}
+	if bestB < 0 {
+		return false
+	}
+	s.applyTwoOpt(a, bestB, bestC, bestD, bestForward)
+	return true
+}
+
+// applyTwoOpt replaces the edges (a,b) and (c,d) with the edges (a,c) and (b,d). If forward is true, b follows a and d follows c in the tour, otherwise b precedes a and d precedes c.
+func (s *localSearch) applyTwoOpt(a int, b int, c int, d int, forward bool) {
+	if s.symmetric {
+		s.length += s.distance(a, c) + s.distance(b, d) - s.distance(a, b) - s.distance(c, d)
+	}
+	if forward {
+		s.reverse(s.position[b], s.position[c])
+	} else {
+		s.reverse(s.position[a], s.position[d])
+	}
+	s.push(a)
+	s.push(b)
+	s.push(c)
+	s.push(d)
+}
+
+// improveOrOpt looks for Or-opt moves that reduce the cost of the circuit, and applies the one that reduces it the most.
+// An Or-opt move relocates a segment of up to maxSegmentLength consecutive vertices, that starts or ends with the supplied vertex, to between two other adjacent vertices, in either orientation.
+// The segment is only relocated next to the candidates of the supplied vertex, skipping candidates whose new edge costs at least as much as the reduction in cost from removing the segment.
+// If the candidates are sorted by cost, the search stops at the first such candidate.
+func (s *localSearch) improveOrOpt(a int, maxSegmentLength int) bool {
+	numVertices := len(s.tour)
+	bestGain, bestFirst, bestLast, bestX, bestY, bestReversed := model.Threshold, -1, -1, -1, -1, false
+	for segmentLength := 1; segmentLength <= maxSegmentLength && segmentLength <= numVertices-3; segmentLength++ {
+		for _, aIsFirst := range []bool{true, false} {
+			// A segment containing only the supplied vertex starts and ends with it, so it is only checked once.
+			if segmentLength == 1 && !aIsFirst {
+				break
+			}
+			first, last := a, a
+			for i := 1; i < segmentLength; i++ {
+				if aIsFirst {
+					last = s.next(last)
+				} else {
+					first = s.previous(first)
+				}
+			}
+			p, n := s.previous(first), s.next(last)
+			removalGain := s.cost(p, first) + s.cost(last, n) - s.cost(p, n)
+
+			for _, c := range s.candidates[a] {
+				if s.cost(a, c) >= removalGain {
+					if s.candidatesSortedByCost {
+						break
+					}
+					continue
+				}
+				if s.isInSegment(c, first, segmentLength) {
+					continue
+				}
+				// Insert the segment either after or before the candidate, oriented so that the supplied vertex is adjacent to the candidate.
+				for _, x := range []int{c, s.previous(c)} {
+					y := s.next(x)
+					if s.isInSegment(x, first, segmentLength) || s.isInSegment(y, first, segmentLength) {
+						continue
+					}
+					reversed := (x == c) != aIsFirst
+					if gain := removalGain - s.insertionIncrease(x, y, first, last, reversed); gain > bestGain {
+						bestGain, bestFirst, bestLast, bestX, bestY, bestReversed = gain, first, last, x, y, reversed
+					}
+				}
+			}
+		}
+	}
+	if bestFirst < 0 {
+		return false
+	}
+	s.applyOrOpt(bestFirst, bestLast, bestX, bestY, bestReversed)
+	return true
+}
+
+// isInSegment returns true if the vertex v is one of the segmentLength vertices of the tour that start at the vertex first.
+func (s *localSearch) isInSegment(v int, first int, segmentLength int) bool {
+	return (s.position[v]-s.position[first]+len(s.tour))%len(s.tour) < segmentLength
+}
+
+// insertionIncrease returns the increase in cost from inserting the segment from first to last between the adjacent vertices x and y, reversing it if requested (i.e. x->last...first->y).
+// When a single vertex is inserted, and the cost is the distance, this is the same as the distance increase of the edge from x to y.
+func (s *localSearch) insertionIncrease(x int, y int, first int, last int, reversed bool) float64 {
+	if first == last && s.costIsDistance {
+		return s.vertices[x].EdgeTo(s.vertices[y]).DistanceIncrease(s.vertices[first])
+	}
+	if reversed {
+		first, last = last, first
+	}
+	return s.cost(x, first) + s.cost(last, y) - s.cost(x, y)
+}
+
+// applyOrOpt moves the segment from first to last to between the adjacent vertices x and y, reversing it if requested. Neither x nor y may be in the segment.
+// Rather than removing and reinserting the segment, this shifts the vertices between the segment and its new location, on whichever side of the segment has fewer of them.
+func (s *localSearch) applyOrOpt(first int, last int, x int, y int, reversed bool) {
+	p, n := s.previous(first), s.next(last)
+	if s.symmetric {
+		inserted := s.distance(x, first) + s.distance(last, y)
+		if reversed {
+			inserted = s.distance(x, last) + s.distance(first, y)
+		}
+		s.length += s.distance(p, n) + inserted - s.distance(p, first) - s.distance(last, n) - s.distance(x, y)
+	} else {
+		s.lengthIsStale = true
+	}
+
+	numVertices := len(s.tour)
+	firstPosition, lastPosition := s.position[first], s.position[last]
+	segmentLength := (lastPosition-firstPosition+numVertices)%numVertices + 1
+	segment := make([]int, segmentLength)
+	for i := range segment {
+		segment[i] = s.tour[(firstPosition+i)%numVertices]
+	}
+	if reversed {
+		for i, j := 0, segmentLength-1; i < j; i, j = i+1, j-1 {
+			segment[i], segment[j] = segment[j], segment[i]
+		}
+	}
+
+	set := func(position int, v int) {
+		position = (position + numVertices) % numVertices
+		s.tour[position] = v
+		s.position[v] = position
+	}
+	numAfter := (s.position[x] - lastPosition + numVertices) % numVertices
+	numBefore := (firstPosition - s.position[y] + numVertices) % numVertices
+	if numAfter <= numBefore {
+		// Shift the vertices from n to x back into the segment's positions, then place the segment after them.
+		for i := 0; i < numAfter; i++ {
+			set(firstPosition+i, s.tour[(lastPosition+1+i)%numVertices])
+		}
+		for i, v := range segment {
+			set(firstPosition+numAfter+i, v)
+		}
+	} else {
+		// Shift the vertices from y to p forward into the segment's positions, then place the segment before them.
+		for i := 0; i < numBefore; i++ {
+			set(lastPosition-i, s.tour[(firstPosition-1-i+numVertices)%numVertices])
+		}
+		for i, v := range segment {
+			set(lastPosition-numBefore-segmentLength+1+i, v)
+		}
+	}
+
+	for _, v := range []int{p, n, first, last, x, y} {
+		s.push(v)
+	}
+}
+
+var _ model.Circuit = (*localSearchCircuit)(nil)
@@ -0,0 +1,204 @@
+package circuit
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// MultiStart solves several independent instances (starts) of a circuit in parallel, and exposes the best of them as a single model.Circuit.
+// This is intended for stochastic circuits, such as SimulatedAnnealing and GeneticAlgorithm, whose results vary between seeds.
+// Each start is seeded with a different seed, derived from the MultiStart's seed, if its circuit has a SetSeed(int64) method.
+//
+// Each call to Update advances every incomplete start by up to "updatesPerBatch" updates, in parallel, so that callers can still stop the MultiStart between batches (e.g. via a context).
+// The spread of lengths between the starts is available via GetLengths and GetStats, to judge how stable an algorithm is.
+type MultiStart struct {
+	circuits        []model.Circuit
+	completed       []bool
+	numWorkers      int
+	seeds           []int64
+	updatesPerBatch int
+}
+
+// MultiStartStats summarizes the lengths of the circuits in a MultiStart.
+type MultiStartStats struct {
+	Max               float64
+	Mean              float64
+	Min               float64
+	StandardDeviation float64
+}
+
+// seedable is implemented by circuits that use a random number generator, such as SimulatedAnnealing and GeneticAlgorithm.
+type seedable interface {
+	SetSeed(seed int64)
+}
+
+// NewMultiStart uses the supplied factory to create "numStarts" circuits, in parallel.
+// The factory must return a new circuit each time it is called, which does not share mutable state with the other circuits (e.g. each should be supplied its own copy of the vertices).
+func NewMultiStart(factory func() model.Circuit, numStarts int) *MultiStart {
+	if numStarts < 1 {
+		numStarts = 1
+	}
+	numWorkers := runtime.GOMAXPROCS(0)
+	if numWorkers > numStarts {
+		numWorkers = numStarts
+	}
+
+	m := &MultiStart{
+		circuits:        make([]model.Circuit, numStarts),
+		completed:       make([]bool, numStarts),
+		numWorkers:      numWorkers,
+		seeds:           make([]int64, numStarts),
+		updatesPerBatch: 1000,
+	}
+	m.forEachStart(func(i int) {
+		m.circuits[i] = factory()
+	})
+	m.SetSeed(time.Now().UnixNano())
+	return m
+}
+
+func (m *MultiStart) FindNextVertexAndEdge() (model.CircuitVertex, model.CircuitEdge) {
+	// Calling FindNextVertexAndEdge on the starts could modify them (e.g. ClosestGreedy removes the vertex from its heap), so starts are only marked as completed during Update().
+	// Each start determines its own vertices in Update(), so just return any vertex from an incomplete start since it will be ignored by Update().
+	for i, isCompleted := range m.completed {
+		if !isCompleted {
+			if attached := m.circuits[i].GetAttachedVertices(); len(attached) > 0 {
+				return attached[0], nil
+			}
+			for v := range m.circuits[i].GetUnattachedVertices() {
+				return v, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (m *MultiStart) GetAttachedVertices() []model.CircuitVertex {
+	return m.getBest().GetAttachedVertices()
+}
+
+// GetCircuits returns the circuit of each start, in the same order as GetSeeds.
+func (m *MultiStart) GetCircuits() []model.Circuit {
+	return m.circuits
+}
+
+func (m *MultiStart) GetLength() float64 {
+	return m.getBest().GetLength()
+}
+
+// GetLengths returns the current length of each start, in the same order as GetSeeds.
+func (m *MultiStart) GetLengths() []float64 {
+	lengths := make([]float64, len(m.circuits))
+	for i, c := range m.circuits {
+		lengths[i] = c.GetLength()
+	}
+	return lengths
+}
+
+// GetSeeds returns the seed supplied to each start, so that a start can be reproduced.
+func (m *MultiStart) GetSeeds() []int64 {
+	return m.seeds
+}
+
+// GetStats returns the minimum, maximum, mean, and standard deviation of the lengths of the starts.
+func (m *MultiStart) GetStats() *MultiStartStats {
+	lengths := m.GetLengths()
+	stats := &MultiStartStats{
+		Max: -math.MaxFloat64,
+		Min: math.MaxFloat64,
+	}
+	for _, l := range lengths {
+		stats.Max = math.Max(stats.Max, l)
+		stats.Min = math.Min(stats.Min, l)
+		stats.Mean += l
+	}
+	stats.Mean /= float64(len(lengths))
+
+	for _, l := range lengths {
+		stats.StandardDeviation += (l - stats.Mean) * (l - stats.Mean)
+	}
+	stats.StandardDeviation = math.Sqrt(stats.StandardDeviation / float64(len(lengths)))
+	return stats
+}
+
+func (m *MultiStart) GetUnattachedVertices() map[model.CircuitVertex]bool {
+	return m.getBest().GetUnattachedVertices()
+}
+
+// SetSeed derives a seed for each start from the supplied seed, and applies it to each start that supports seeding.
+// This is to facilitate consistent unit tests, and reproducing a good start.
+// Note: circuits that use their random number generator in their constructor (e.g. GeneticAlgorithm) are created prior to seeding, so they are only partially reproducible.
+func (m *MultiStart) SetSeed(seed int64) {
+	random := rand.New(rand.NewSource(seed))
+	for i, c := range m.circuits {
+		m.seeds[i] = random.Int63()
+		if s, okay := c.(seedable); okay {
+			s.SetSeed(m.seeds[i])
+		}
+	}
+}
+
+// SetUpdatesPerBatch sets the maximum number of times each start is updated during each call to Update. Values less than 1 are ignored.
+func (m *MultiStart) SetUpdatesPerBatch(updatesPerBatch int) {
+	if updatesPerBatch > 0 {
+		m.updatesPerBatch = updatesPerBatch
+	}
+}
+
+// Update advances each incomplete start by up to "updatesPerBatch" updates, in parallel.
+func (m *MultiStart) Update(ignoredVertex model.CircuitVertex, ignoredEdge model.CircuitEdge) {
+	m.forEachStart(func(i int) {
+		if m.completed[i] {
+			return
+		}
+		c := m.circuits[i]
+		for numUpdates := 0; numUpdates < m.updatesPerBatch; numUpdates++ {
+			nextVertex, nextEdge := c.FindNextVertexAndEdge()
+			if nextVertex == nil {
+				m.completed[i] = true
+				return
+			}
+			c.Update(nextVertex, nextEdge)
+		}
+	})
+}
+
+// forEachStart calls the supplied function once per start, using up to "numWorkers" goroutines, and waits for all calls to complete.
+func (m *MultiStart) forEachStart(f func(i int)) {
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < m.numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				f(i)
+			}
+		}()
+	}
+	for i := range m.circuits {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
+
+// getBest returns the start with the fewest unattached vertices, and the shortest length among those.
+func (m *MultiStart) getBest() model.Circuit {
+	best := m.circuits[0]
+	bestUnattached := len(best.GetUnattachedVertices())
+	for _, c := range m.circuits[1:] {
+		if numUnattached := len(c.GetUnattachedVertices()); numUnattached < bestUnattached || (numUnattached == bestUnattached && c.GetLength() < best.GetLength()) {
+			best = c
+			bestUnattached = numUnattached
+		}
+	}
+	return best
+}
+
+var _ model.Circuit = (*MultiStart)(nil)
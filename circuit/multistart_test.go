@@ -0,0 +1,92 @@
+package circuit_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiStart_SimulatedAnnealing(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(30))
+	c := circuit.NewMultiStart(func() model.Circuit {
+		return circuit.NewSimulatedAnnealing(append([]model.CircuitVertex{}, vertices...), 2500, false)
+	}, 4)
+	c.SetSeed(5)
+	c.SetUpdatesPerBatch(1000)
+
+	assert.Len(c.GetCircuits(), 4)
+	seeds := c.GetSeeds()
+	assert.Len(seeds, 4)
+	for i := 1; i < len(seeds); i++ {
+		assert.NotEqual(seeds[0], seeds[i])
+	}
+
+	numUpdates := 0
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+		numUpdates++
+	}
+	// Each batch advances each start by 1000 updates, so 2500 iterations require 3 batches.
+	assert.Equal(3, numUpdates)
+
+	lengths := c.GetLengths()
+	assert.Len(lengths, 4)
+	stats := c.GetStats()
+	shortest := math.MaxFloat64
+	for i, l := range lengths {
+		assert.InDelta(c.GetCircuits()[i].GetLength(), l, model.Threshold)
+		assert.GreaterOrEqual(l, stats.Min)
+		assert.LessOrEqual(l, stats.Max)
+		shortest = math.Min(shortest, l)
+	}
+	assert.Equal(shortest, stats.Min)
+	assert.Equal(shortest, c.GetLength())
+	assert.Greater(stats.Mean, 0.0)
+	assert.GreaterOrEqual(stats.StandardDeviation, 0.0)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+}
+
+func TestMultiStart_ShouldBeReproducibleWithSeed(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(20))
+	solve := func() []float64 {
+		c := circuit.NewMultiStart(func() model.Circuit {
+			return circuit.NewSimulatedAnnealing(append([]model.CircuitVertex{}, vertices...), 500, false)
+		}, 3)
+		c.SetSeed(11)
+		for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+			c.Update(next, edge)
+		}
+		return c.GetLengths()
+	}
+
+	assert.Equal(solve(), solve())
+}
+
+func TestMultiStart_ShouldSupportCircuitsWithoutSeeds(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(20))
+	c := circuit.NewMultiStart(func() model.Circuit {
+		return circuit.NewClosestGreedy(append([]model.CircuitVertex{}, vertices...), model2d.BuildPerimiter, false)
+	}, 2)
+	assert.Greater(len(c.GetUnattachedVertices()), 0)
+
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+	stats := c.GetStats()
+	assert.InDelta(stats.Min, stats.Max, model.Threshold)
+	assert.InDelta(0.0, stats.StandardDeviation, model.Threshold)
+}
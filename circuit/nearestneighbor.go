@@ -0,0 +1,116 @@
+package circuit
+
+import (
+	"math"
+
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/spatial"
+)
+
+// NearestNeighbor implements the [nearest neighbor](https://en.wikipedia.org/wiki/Nearest_neighbour_algorithm) construction heuristic.
+// Unlike the convex-concave algorithms this does not use a perimeter builder, rather it:
+// 1. Starts the circuit at the first supplied vertex.
+// 2. Finds the closest unattached vertex to the last vertex in the circuit.
+// 3. Attaches that vertex to the end of the circuit (i.e. splits the edge from the last vertex back to the first vertex).
+// 4. Repeats steps 2 and 3 until all vertices are attached.
+//
+// For 2D and 3D vertices the closest vertex is found with a k-d tree, so this is O(n*log(n)) on average, which is fast enough for tens of thousands of vertices.
+// For other vertices (e.g. graphs), the closest vertex is found by checking every unattached vertex, so this is O(n^2).
+// The circuit is typically 20-25% longer than optimal, so this is best used as a fast precursor for algorithms that improve an existing circuit.
+type NearestNeighbor struct {
+	circuit    []model.CircuitVertex
+	length     float64
+	next       model.CircuitVertex
+	tree       *spatial.KDTree
+	unattached map[model.CircuitVertex]bool
+	vertices   []model.CircuitVertex
+}
+
+// NewNearestNeighbor creates a NearestNeighbor circuit, starting from the first of the supplied vertices.
+func NewNearestNeighbor(vertices []model.CircuitVertex) *NearestNeighbor {
+	n := &NearestNeighbor{
+		circuit:    make([]model.CircuitVertex, 0, len(vertices)),
+		length:     0.0,
+		unattached: make(map[model.CircuitVertex]bool, len(vertices)),
+		vertices:   vertices,
+	}
+	for _, v := range vertices {
+		n.unattached[v] = true
+	}
+	if tree, okay := spatial.NewKDTree(vertices); okay {
+		n.tree = tree
+	}
+	if len(vertices) > 0 {
+		n.attach(vertices[0])
+	}
+	return n
+}
+
+// FindNextVertexAndEdge returns the closest unattached vertex to the last vertex in the circuit, and the edge from the last vertex back to the first vertex.
+// The vertex is retained until Update is called, so calling this repeatedly does not repeat the search.
+func (n *NearestNeighbor) FindNextVertexAndEdge() (model.CircuitVertex, model.CircuitEdge) {
+	if len(n.unattached) == 0 {
+		return nil, nil
+	}
+	last := n.circuit[len(n.circuit)-1]
+	if n.next == nil {
+		if n.tree != nil {
+			n.next = n.tree.Nearest(last)
+		} else {
+			n.next = n.findNearestByScan(last)
+		}
+	}
+	return n.next, last.EdgeTo(n.circuit[0])
+}
+
+func (n *NearestNeighbor) GetAttachedVertices() []model.CircuitVertex {
+	return n.circuit
+}
+
+func (n *NearestNeighbor) GetLength() float64 {
+	return n.length
+}
+
+func (n *NearestNeighbor) GetUnattachedVertices() map[model.CircuitVertex]bool {
+	return n.unattached
+}
+
+// Update attaches the supplied vertex to the end of the circuit. The supplied edge is ignored, since vertices are always attached between the last and first vertices.
+func (n *NearestNeighbor) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
+	if vertexToAdd == nil || !n.unattached[vertexToAdd] {
+		return
+	}
+	n.attach(vertexToAdd)
+}
+
+// attach appends the supplied vertex to the circuit, and updates the length of the circuit to account for the edge returning to the first vertex.
+func (n *NearestNeighbor) attach(vertexToAdd model.CircuitVertex) {
+	if numAttached := len(n.circuit); numAttached > 0 {
+		first, last := n.circuit[0], n.circuit[numAttached-1]
+		n.length += last.DistanceTo(vertexToAdd) + vertexToAdd.DistanceTo(first) - last.DistanceTo(first)
+	}
+	n.circuit = append(n.circuit, vertexToAdd)
+	delete(n.unattached, vertexToAdd)
+	if n.tree != nil {
+		n.tree.Remove(vertexToAdd)
+	}
+	n.next = nil
+}
+
+// findNearestByScan checks each unattached vertex, in the order the vertices were supplied so that ties are resolved consistently.
+func (n *NearestNeighbor) findNearestByScan(from model.CircuitVertex) model.CircuitVertex {
+	var nearest model.CircuitVertex
+	nearestDistance := math.MaxFloat64
+	for _, v := range n.vertices {
+		if !n.unattached[v] {
+			continue
+		}
+		if distance := from.DistanceTo(v); distance < nearestDistance {
+			nearest = v
+			nearestDistance = distance
+		}
+	}
+	return nearest
+}
+
+var _ model.Circuit = (*NearestNeighbor)(nil)
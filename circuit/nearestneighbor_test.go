@@ -0,0 +1,119 @@
+package circuit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/tsplib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNearestNeighbor(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(1, 0),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(0, 10),
+		model2d.NewVertex2D(3, 1),
+	}
+	c := circuit.NewNearestNeighbor(vertices)
+	assert.Equal([]model.CircuitVertex{vertices[0]}, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 5)
+	assert.InDelta(0.0, c.GetLength(), model.Threshold)
+
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Equal(vertices[2], next)
+	assert.True(vertices[0].EdgeTo(vertices[0]).Equals(edge))
+	// Finding the next vertex does not modify the circuit, so repeated calls produce the same result.
+	next, edge = c.FindNextVertexAndEdge()
+	assert.Equal(vertices[2], next)
+	c.Update(next, edge)
+	assert.InDelta(2.0, c.GetLength(), model.Threshold)
+
+	next, edge = c.FindNextVertexAndEdge()
+	assert.Equal(vertices[5], next)
+	assert.True(vertices[2].EdgeTo(vertices[0]).Equals(edge))
+
+	for ; next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+
+	assert.Equal([]model.CircuitVertex{vertices[0], vertices[2], vertices[5], vertices[3], vertices[1], vertices[4]}, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+
+	// Attached vertices are ignored.
+	c.Update(vertices[1], nil)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+}
+
+func TestNearestNeighbor_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model3d.GenerateVertices(200)
+	c := circuit.NewNearestNeighbor(vertices)
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+}
+
+func TestNearestNeighbor_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	// Unidirectional edges can leave some vertices unable to reach the others, so this uses a seed that produces a connected graph.
+	seed := int64(1)
+	gen := &graph.GraphGenerator{
+		EnableAsymetricDistances:  true,
+		EnableUnidirectionalEdges: true,
+		MaxEdges:                  5,
+		MinEdges:                  2,
+		NumVertices:               25,
+		Seed:                      &seed,
+	}
+	g := gen.Create()
+	defer g.Delete()
+
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+	c := circuit.NewNearestNeighbor(vertices)
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		last := c.GetAttachedVertices()[len(c.GetAttachedVertices())-1]
+		for v := range c.GetUnattachedVertices() {
+			assert.LessOrEqual(last.DistanceTo(next), last.DistanceTo(v))
+		}
+		c.Update(next, edge)
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+}
+
+func TestNearestNeighbor_ShouldBeFastForLargeInputs(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := tsplib.NewData("../test-data/tsplib/usa13509.tsp")
+	assert.Nil(err)
+	vertices := data.GetVertices()
+
+	start := time.Now()
+	c := circuit.NewNearestNeighbor(vertices)
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	duration := time.Since(start)
+	t.Logf("usa13509 took %v", duration)
+
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), 1e-6*c.GetLength())
+	// This is typically well under a second; the limit is generous so that the test is stable when run with the race detector.
+	assert.Less(duration, 10*time.Second)
+}
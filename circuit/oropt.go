@@ -0,0 +1,51 @@
+package circuit
+
+import (
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// OrOptDefaultMaxSegmentLength is the default length of the longest segment that OrOpt relocates.
+const OrOptDefaultMaxSegmentLength = 3
+
+// OrOpt implements the Or-opt local search, which deterministically improves a completed circuit by relocating short segments of consecutive vertices.
+// The convex-concave algorithms often attach short runs of 1-3 vertices to the wrong edge, which 2-opt cannot fix without first making the circuit longer, since it only reverses parts of the circuit.
+// Each move removes a segment of up to maxSegmentLength vertices from the circuit, and inserts it between two other adjacent vertices, in whichever orientation is shorter, if that shortens the circuit.
+//
+// Like TwoOpt, segments are only relocated next to the nearest neighbors (candidates) of their first or last vertex, and don't-look bits limit each pass to the parts of the circuit that changed.
+// Each call to Update checks one vertex, and applies the best move of a segment that starts or ends with it, if any.
+// The circuit is complete (FindNextVertexAndEdge returns nil) once every vertex has been checked without finding an improving move since its edges last changed.
+type OrOpt struct {
+	*localSearchCircuit
+	maxSegmentLength int
+}
+
+// NewOrOpt creates an OrOpt circuit that improves the supplied circuit, relocating segments of up to maxSegmentLength vertices next to the supplied number of candidate neighbors of each vertex.
+// If the number of neighbors is less than 1, every vertex is a candidate, and if the maximum segment length is less than 1, OrOptDefaultMaxSegmentLength is used.
+// Duplicate references to the same vertex are ignored.
+func NewOrOpt(circuit []model.CircuitVertex, numNeighbors int, maxSegmentLength int) *OrOpt {
+	if maxSegmentLength < 1 {
+		maxSegmentLength = OrOptDefaultMaxSegmentLength
+	}
+	search := newLocalSearch(circuit, numNeighbors)
+	return &OrOpt{
+		localSearchCircuit: &localSearchCircuit{
+			improve: func(a int) bool {
+				return search.improveOrOpt(a, maxSegmentLength)
+			},
+			search: search,
+		},
+		maxSegmentLength: maxSegmentLength,
+	}
+}
+
+// NewOrOptFromCircuit completes the supplied circuit, then creates an OrOpt circuit that improves it.
+func NewOrOptFromCircuit(circuit model.Circuit, numNeighbors int, maxSegmentLength int) *OrOpt {
+	return NewOrOpt(attachAll(circuit), numNeighbors, maxSegmentLength)
+}
+
+// GetMaxSegmentLength returns the length of the longest segment that this relocates.
+func (o *OrOpt) GetMaxSegmentLength() int {
+	return o.maxSegmentLength
+}
+
+var _ model.Circuit = (*OrOpt)(nil)
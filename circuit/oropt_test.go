@@ -0,0 +1,128 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrOpt(t *testing.T) {
+	assert := assert.New(t)
+
+	// (5,9) is attached to the bottom edge, but belongs on the top edge.
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(5, 9),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(0, 10),
+	}
+	c := circuit.NewOrOpt(vertices, 3, 0)
+	assert.Equal(circuit.OrOptDefaultMaxSegmentLength, c.GetMaxSegmentLength())
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.InDelta(model.Length(vertices), c.GetLength(), model.Threshold)
+	assert.Len(c.GetUnattachedVertices(), 0)
+
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Equal(vertices[0], next)
+	assert.Nil(edge)
+	solver.FindShortestPathCircuit(c)
+
+	assert.Equal(1, c.GetNumImprovements())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.InDelta(30+vertices[3].DistanceTo(vertices[1])+vertices[1].DistanceTo(vertices[4]), c.GetLength(), model.Threshold)
+}
+
+func TestOrOpt_ShouldRelocateReversedSegment(t *testing.T) {
+	assert := assert.New(t)
+
+	// The segment (4,11),(6,11) is attached to the bottom edge, and is shortest on the top edge in the opposite orientation.
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(4, 11),
+		model2d.NewVertex2D(6, 11),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(0, 10),
+	}
+	c := circuit.NewOrOpt(vertices, 5, 2)
+	solver.FindShortestPathCircuit(c)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.InDelta(30+vertices[4].DistanceTo(vertices[2])+2+vertices[1].DistanceTo(vertices[5]), c.GetLength(), model.Threshold)
+}
+
+func TestOrOpt_ShouldImproveConvexConcave(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(300))
+	c := circuit.NewOrOptFromCircuit(circuit.NewClosestGreedy(vertices, model2d.BuildPerimiter, false), circuit.LocalSearchDefaultNeighbors, 3)
+	initialLength := model.Length(c.GetAttachedVertices())
+	solver.FindShortestPathCircuit(c)
+
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+	for _, v := range vertices {
+		assert.Contains(c.GetAttachedVertices(), v)
+	}
+}
+
+func TestOrOpt_ShouldImproveAfterTwoOpt(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model3d.GenerateVertices(300)
+	twoOpt := circuit.NewTwoOpt(vertices, circuit.LocalSearchDefaultNeighbors)
+	solver.FindShortestPathCircuit(twoOpt)
+
+	c := circuit.NewOrOptFromCircuit(twoOpt, circuit.LocalSearchDefaultNeighbors, 3)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.LessOrEqual(c.GetLength(), twoOpt.GetLength())
+}
+
+func TestOrOpt_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		EnableAsymetricDistances: true,
+		MaxEdges:                 5,
+		MinEdges:                 2,
+		NumVertices:              30,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+
+	c := circuit.NewOrOpt(vertices, 5, 3)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Greater(c.GetNumImprovements(), 0)
+}
+
+func TestOrOpt_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	c := circuit.NewOrOpt([]model.CircuitVertex{}, 5, 3)
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(1, 2),
+		model2d.NewVertex2D(3, 2),
+		model2d.NewVertex2D(1, 5),
+	}
+	c = circuit.NewOrOpt(vertices, 5, 3)
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.Equal(0, c.GetNumImprovements())
+}
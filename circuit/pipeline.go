@@ -0,0 +1,106 @@
+package circuit
+
+import (
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// PipelineStage creates the circuit for one stage of a Pipeline, using the completed circuit from the preceding stage as its initial circuit.
+type PipelineStage func(precursor model.Circuit) model.Circuit
+
+// Pipeline computes a sequence of circuits (stages), where each stage starts from the circuit produced by the stage before it.
+// For example, ClosestGreedy can construct a circuit, which SimulatedAnnealing then refines, which a GeneticAlgorithm then refines further.
+//
+// Each stage is only created once the preceding stage is completed, and each call to Update only updates the current stage,
+// so callers can still stop a Pipeline partway through a stage (e.g. via a context).
+// The length of each completed stage is available via GetStageLengths, to judge how much each stage contributed.
+type Pipeline struct {
+	circuit      model.Circuit
+	numStages    int
+	stageLengths []float64
+	stages       []PipelineStage
+}
+
+// NewPipeline creates a Pipeline that starts by computing the supplied circuit, then creates and computes each of the supplied stages in order.
+func NewPipeline(first model.Circuit, stages ...PipelineStage) *Pipeline {
+	return &Pipeline{
+		circuit:      first,
+		numStages:    len(stages) + 1,
+		stageLengths: make([]float64, 0, len(stages)+1),
+		stages:       stages,
+	}
+}
+
+// FindNextVertexAndEdge returns the next vertex and edge of the current stage.
+// If the current stage is completed, this records its length and creates the next stage from it, so this only returns (nil, nil) once the final stage is completed.
+func (p *Pipeline) FindNextVertexAndEdge() (model.CircuitVertex, model.CircuitEdge) {
+	for {
+		if nextVertex, nextEdge := p.circuit.FindNextVertexAndEdge(); nextVertex != nil {
+			return nextVertex, nextEdge
+		}
+		if len(p.stageLengths) < p.numStages {
+			p.stageLengths = append(p.stageLengths, p.circuit.GetLength())
+		}
+		if len(p.stages) == 0 {
+			return nil, nil
+		}
+		p.circuit = p.stages[0](p.circuit)
+		p.stages = p.stages[1:]
+	}
+}
+
+func (p *Pipeline) GetAttachedVertices() []model.CircuitVertex {
+	return p.circuit.GetAttachedVertices()
+}
+
+// GetCurrentCircuit returns the circuit of the stage that is currently being computed, or of the final stage once the Pipeline is completed.
+func (p *Pipeline) GetCurrentCircuit() model.Circuit {
+	return p.circuit
+}
+
+func (p *Pipeline) GetLength() float64 {
+	return p.circuit.GetLength()
+}
+
+// GetNumStages returns the total number of stages in the Pipeline, including the initial circuit.
+func (p *Pipeline) GetNumStages() int {
+	return p.numStages
+}
+
+// GetStageLengths returns the length of each completed stage, in the order the stages were computed.
+// If the Pipeline was stopped partway through, this does not include the incomplete stage or the stages after it.
+func (p *Pipeline) GetStageLengths() []float64 {
+	return p.stageLengths
+}
+
+func (p *Pipeline) GetUnattachedVertices() map[model.CircuitVertex]bool {
+	return p.circuit.GetUnattachedVertices()
+}
+
+func (p *Pipeline) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
+	p.circuit.Update(vertexToAdd, edgeToSplit)
+}
+
+// BuildPerimeterFromCircuit creates a perimeter builder that uses the supplied circuit, rather than a convex hull, as the initial circuit.
+// This allows the convex-concave algorithms (e.g. ClosestGreedy, DisparityGreedy) to continue from a circuit produced by another algorithm,
+// attaching only the vertices that are not already in that circuit.
+func BuildPerimeterFromCircuit(c model.Circuit) model.PerimeterBuilder {
+	return func(verticesArg []model.CircuitVertex) ([]model.CircuitEdge, map[model.CircuitVertex]bool) {
+		attached := c.GetAttachedVertices()
+		isAttached := make(map[model.CircuitVertex]bool, len(attached))
+		edges := make([]model.CircuitEdge, len(attached))
+		for i, v := range attached {
+			isAttached[v] = true
+			edges[i] = v.EdgeTo(attached[(i+1)%len(attached)])
+		}
+
+		unattached := make(map[model.CircuitVertex]bool)
+		for _, v := range verticesArg {
+			if !isAttached[v] {
+				unattached[v] = true
+			}
+		}
+		return edges, unattached
+	}
+}
+
+var _ model.Circuit = (*Pipeline)(nil)
@@ -0,0 +1,101 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipeline(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(40))
+	greedy := circuit.NewClosestGreedy(vertices, model2d.BuildPerimiter, false)
+	var annealing *circuit.SimulatedAnnealing
+	var genetic *circuit.GeneticAlgorithm
+	c := circuit.NewPipeline(greedy, func(precursor model.Circuit) model.Circuit {
+		assert.Equal(greedy, precursor)
+		annealing = circuit.NewSimulatedAnnealingFromCircuit(precursor, 2000, false)
+		annealing.SetSeed(3)
+		return annealing
+	}, func(precursor model.Circuit) model.Circuit {
+		assert.Equal(annealing, precursor)
+		genetic = circuit.NewGeneticAlgorithmFromCircuit(precursor, 10, 10, 20)
+		genetic.SetSeed(5)
+		return genetic
+	})
+	assert.Equal(3, c.GetNumStages())
+	assert.Len(c.GetStageLengths(), 0)
+	assert.Equal(greedy, c.GetCurrentCircuit())
+
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+
+	assert.Equal(genetic, c.GetCurrentCircuit())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+
+	stageLengths := c.GetStageLengths()
+	assert.Len(stageLengths, 3)
+	assert.InDelta(greedy.GetLength(), stageLengths[0], model.Threshold)
+	assert.InDelta(annealing.GetLength(), stageLengths[1], model.Threshold)
+	assert.InDelta(genetic.GetLength(), stageLengths[2], model.Threshold)
+	assert.InDelta(c.GetLength(), stageLengths[2], model.Threshold)
+	// The genetic algorithm retains the circuit produced by simulated annealing as a parent, so it cannot be longer.
+	assert.LessOrEqual(stageLengths[2], stageLengths[1]+model.Threshold)
+
+	// Completed pipelines should not record additional lengths.
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+	assert.Len(c.GetStageLengths(), 3)
+}
+
+func TestPipeline_ShouldOnlyReportCompletedStages(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(20))
+	c := circuit.NewPipeline(circuit.NewClosestGreedy(vertices, model2d.BuildPerimiter, false), func(precursor model.Circuit) model.Circuit {
+		return circuit.NewSimulatedAnnealingFromCircuit(precursor, 100, false)
+	})
+
+	for i := 0; i < 50; i++ {
+		next, edge := c.FindNextVertexAndEdge()
+		assert.NotNil(next)
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetStageLengths(), 1)
+	assert.IsType(&circuit.SimulatedAnnealing{}, c.GetCurrentCircuit())
+}
+
+func TestBuildPerimeterFromCircuit(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(0, 10),
+		model2d.NewVertex2D(5, 5),
+	}
+	precursor := circuit.NewSimulatedAnnealing([]model.CircuitVertex{vertices[0], vertices[3], vertices[2], vertices[1]}, 0, false)
+
+	edges, unattached := circuit.BuildPerimeterFromCircuit(precursor)(vertices)
+	assert.Len(edges, 4)
+	assert.Equal(vertices[0], edges[0].GetStart())
+	assert.Equal(vertices[3], edges[0].GetEnd())
+	assert.Equal(vertices[1], edges[3].GetStart())
+	assert.Equal(vertices[0], edges[3].GetEnd())
+	assert.Equal(map[model.CircuitVertex]bool{vertices[4]: true}, unattached)
+
+	greedy := circuit.NewClosestGreedy(vertices, circuit.BuildPerimeterFromCircuit(precursor), false)
+	for next, edge := greedy.FindNextVertexAndEdge(); next != nil; next, edge = greedy.FindNextVertexAndEdge() {
+		greedy.Update(next, edge)
+	}
+	assert.Len(greedy.GetAttachedVertices(), 5)
+	assert.Len(greedy.GetUnattachedVertices(), 0)
+}
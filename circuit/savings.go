@@ -0,0 +1,143 @@
+package circuit
+
+import (
+	"math"
+	"sort"
+
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/mst"
+	"github.com/heustis/tsp-solver-go/spatial"
+)
+
+// Savings implements the [Clarke-Wright savings](https://en.wikipedia.org/wiki/Vehicle_routing_problem#Heuristic_methods) construction heuristic, which originated in vehicle routing.
+// It starts with a separate route from the hub (depot) to each other vertex and back, then merges routes in the order that saves the most distance:
+// 1. Computes the savings of each pair of non-hub vertices, i and j, as d(hub,i) + d(j,hub) - d(i,j), which is how much shorter the circuit becomes if the routes ending at i and j are joined.
+// 2. Sorts the pairs from the largest saving to the smallest.
+// 3. Joins the routes containing each pair, provided that both vertices are still at an end of their route, and that they are not already in the same route.
+//     * This uses the same disjoint set (union-find) bookkeeping as GreedyEdge, so each check is effectively O(1).
+// 4. If the pairs are exhausted before all routes are merged (e.g. because only the nearest neighbors were candidates), the remaining routes are joined by repeatedly connecting the end of the circuit to the closest end of another route.
+// 5. Closes the circuit through the hub, which is the first vertex of the circuit.
+//
+// The hub defaults to the 2D or 3D vertex closest to the centroid of the vertices, or for graphs, the vertex with the smallest total distance to the other vertices.
+// Distances are treated as symmetric, except for the distances to and from the hub, so the circuit may not be a good approximation for asymmetric graphs.
+//
+// The entire circuit is computed when this is created, then each call to FindNextVertexAndEdge and Update attaches the next vertex of that circuit.
+type Savings struct {
+	*tourCircuit
+	hub          model.CircuitVertex
+	numNeighbors int
+}
+
+// NewSavings creates a Savings circuit around the supplied hub, using every pair of vertices as candidates for up to GreedyEdgeMaxCompleteVertices vertices,
+// and the GreedyEdgeDefaultNeighbors nearest neighbors of each vertex for larger sets of 2D and 3D vertices.
+// If the hub is nil, or is not equal to one of the vertices, the default hub is used.
+// Duplicate references to the same vertex are ignored, and are not counted towards GreedyEdgeMaxCompleteVertices.
+func NewSavings(vertices []model.CircuitVertex, hub model.CircuitVertex) *Savings {
+	unique := uniqueVertices(vertices)
+	numNeighbors := 0
+	if len(unique) > GreedyEdgeMaxCompleteVertices {
+		numNeighbors = GreedyEdgeDefaultNeighbors
+	}
+	return NewSavingsWithNeighbors(unique, hub, numNeighbors)
+}
+
+// NewSavingsWithNeighbors creates a Savings circuit around the supplied hub, that uses the supplied number of nearest neighbors of each vertex as candidate pairs.
+// If the number of neighbors is less than 1, or the vertices are not all 2D or all 3D vertices (e.g. graphs), every pair of vertices is a candidate.
+// If the hub is nil, or is not equal to one of the vertices, the default hub is used. Duplicate references to the same vertex are ignored.
+func NewSavingsWithNeighbors(vertices []model.CircuitVertex, hub model.CircuitVertex, numNeighbors int) *Savings {
+	unique := uniqueVertices(vertices)
+	if len(unique) == 0 {
+		return &Savings{
+			tourCircuit: newTourCircuit(unique),
+		}
+	}
+
+	tree, isSpatial := spatial.NewKDTree(unique)
+	hubIndex := findHubIndex(unique, hub, tree)
+	// Move the hub to the front, so that joining the routes starts from the hub, and the circuit starts with the hub.
+	unique[0], unique[hubIndex] = unique[hubIndex], unique[0]
+
+	var candidates []mst.Edge
+	if numNeighbors > 0 && isSpatial {
+		candidates = mst.FindNeighborEdges(unique, tree, numNeighbors)
+	} else {
+		numNeighbors = 0
+		candidates = mst.FindAllEdges(unique)
+	}
+
+	// The candidates' lengths are replaced with their savings, excluding any pair that contains the hub, since the hub is in every route.
+	savings := candidates[:0]
+	for _, e := range candidates {
+		if e.From != 0 && e.To != 0 {
+			e.Length = unique[0].DistanceTo(unique[e.From]) + unique[e.To].DistanceTo(unique[0]) - e.Length
+			savings = append(savings, e)
+		}
+	}
+	sort.SliceStable(savings, func(i, j int) bool {
+		return savings[i].Length > savings[j].Length
+	})
+	paths := newGreedyPaths(len(unique))
+	paths.addEdges(savings)
+	order := joinPaths(unique, paths.adjacency)
+
+	return &Savings{
+		tourCircuit:  newTourCircuit(toVertices(unique, order)),
+		hub:          unique[0],
+		numNeighbors: numNeighbors,
+	}
+}
+
+// GetHub returns the hub vertex, which is the first vertex of the circuit, or nil if there are no vertices.
+func (s *Savings) GetHub() model.CircuitVertex {
+	return s.hub
+}
+
+// GetNumNeighbors returns the number of nearest neighbors of each vertex that were used as candidate pairs, or 0 if every pair of vertices was a candidate.
+func (s *Savings) GetNumNeighbors() int {
+	return s.numNeighbors
+}
+
+// findHubIndex returns the index of the supplied hub in the vertices, or if it is not present, the index of the default hub.
+// The tree is only used for 2D and 3D vertices, and may be nil for other vertices.
+func findHubIndex(vertices []model.CircuitVertex, hub model.CircuitVertex, tree *spatial.KDTree) int {
+	if hub != nil {
+		for i, v := range vertices {
+			if v == hub {
+				return i
+			}
+		}
+		// The hub may be a different instance than the vertices (e.g. if it was created from an API request), so fall back to checking equality.
+		for i, v := range vertices {
+			if v.Equals(hub) {
+				return i
+			}
+		}
+	}
+
+	if centroid, okay := spatial.Centroid(vertices); okay && tree != nil {
+		nearest := tree.Nearest(centroid)
+		for i, v := range vertices {
+			if v == nearest {
+				return i
+			}
+		}
+	}
+
+	// Vertices without coordinates (e.g. graphs) use the medoid, which is O(n^2).
+	hubIndex := 0
+	minTotal := math.MaxFloat64
+	for i, v := range vertices {
+		total := 0.0
+		for j, other := range vertices {
+			if i != j {
+				total += v.DistanceTo(other)
+			}
+		}
+		if total < minTotal {
+			hubIndex, minTotal = i, total
+		}
+	}
+	return hubIndex
+}
+
+var _ model.Circuit = (*Savings)(nil)
@@ -0,0 +1,183 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavings(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(0, 10),
+		model2d.NewVertex2D(5, 5),
+	}
+	c := circuit.NewSavings(vertices, nil)
+	// The centroid is (5,5), so that vertex is the hub.
+	assert.Equal(vertices[4], c.GetHub())
+	assert.Equal(0, c.GetNumNeighbors())
+	assert.Equal([]model.CircuitVertex{vertices[4]}, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 4)
+
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+
+	// Adjacent corners save 2*sqrt(50)-10 and opposite corners save nothing, so three sides of the square are joined (the fourth would close a loop without the hub).
+	assert.Equal([]model.CircuitVertex{vertices[4], vertices[3], vertices[2], vertices[1], vertices[0]}, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(30.0+2*vertices[4].DistanceTo(vertices[0]), c.GetLength(), model.Threshold)
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+}
+
+func TestSavings_Hub(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+
+	c := circuit.NewSavings(vertices, vertices[7])
+	assert.Equal(vertices[7], c.GetHub())
+	assert.Equal(vertices[7], c.GetAttachedVertices()[0])
+
+	// The hub may be a different instance that is equal to one of the vertices.
+	v := vertices[12].(*model2d.Vertex2D)
+	c = circuit.NewSavings(vertices, model2d.NewVertex2D(v.X, v.Y))
+	assert.Equal(vertices[12], c.GetHub())
+
+	// A hub that is not one of the vertices is replaced by the default hub.
+	c = circuit.NewSavings(vertices, model2d.NewVertex2D(-1000, -1000))
+	defaultHub := circuit.NewSavings(vertices, nil).GetHub()
+	assert.NotNil(defaultHub)
+	assert.Equal(defaultHub, c.GetHub())
+
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+}
+
+func TestSavings_Neighbors(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(500))
+	complete := circuit.NewSavings(vertices, nil)
+	neighbors := circuit.NewSavingsWithNeighbors(vertices, nil, 8)
+	assert.Equal(8, neighbors.GetNumNeighbors())
+	assert.Equal(complete.GetHub(), neighbors.GetHub())
+
+	for _, c := range []*circuit.Savings{complete, neighbors} {
+		for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+			c.Update(next, edge)
+		}
+		assert.Len(c.GetAttachedVertices(), len(vertices))
+		assert.Len(c.GetUnattachedVertices(), 0)
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	}
+	// Restricting the candidates to the nearest neighbors should have little effect on the length of the circuit.
+	assert.InDelta(complete.GetLength(), neighbors.GetLength(), 0.15*complete.GetLength())
+}
+
+func TestSavings_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model3d.GenerateVertices(200)
+	c := circuit.NewSavings(vertices, nil)
+	centroid := model3d.NewVertex3D(0, 0, 0)
+	for _, v := range vertices {
+		centroid = centroid.Add(v.(*model3d.Vertex3D))
+	}
+	centroid = centroid.Multiply(1.0 / float64(len(vertices)))
+	for _, v := range vertices {
+		assert.GreaterOrEqual(v.DistanceTo(centroid), c.GetHub().DistanceTo(centroid))
+	}
+
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+}
+
+func TestSavings_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	// Unidirectional edges can leave some vertices unable to reach the others, so this uses a seed that produces a connected graph.
+	seed := int64(1)
+	gen := &graph.GraphGenerator{
+		EnableAsymetricDistances:  true,
+		EnableUnidirectionalEdges: true,
+		MaxEdges:                  5,
+		MinEdges:                  2,
+		NumVertices:               25,
+		Seed:                      &seed,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+
+	// The default hub for a graph is the vertex with the smallest total distance to the other vertices.
+	c := circuit.NewSavingsWithNeighbors(vertices, nil, 5)
+	assert.Equal(0, c.GetNumNeighbors())
+	hubTotal := 0.0
+	for _, v := range vertices {
+		hubTotal += c.GetHub().DistanceTo(v)
+	}
+	for _, v := range vertices {
+		total := 0.0
+		for _, other := range vertices {
+			total += v.DistanceTo(other)
+		}
+		assert.GreaterOrEqual(total+model.Threshold, hubTotal)
+	}
+
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Equal(c.GetHub(), c.GetAttachedVertices()[0])
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+
+	// Graph vertices are equal if their ids are equal.
+	hub := vertices[3].(*graph.GraphVertex)
+	c = circuit.NewSavings(vertices, graph.NewGraphVertex(hub.GetId()))
+	assert.Equal(hub, c.GetHub())
+}
+
+func TestSavings_ShouldIgnoreDuplicatesWhenChoosingCandidates(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(circuit.GreedyEdgeMaxCompleteVertices * 3 / 4))
+	duplicated := append(append([]model.CircuitVertex{}, vertices...), vertices...)
+	assert.Greater(len(duplicated), circuit.GreedyEdgeMaxCompleteVertices)
+
+	c := circuit.NewSavings(duplicated, nil)
+	assert.Equal(0, c.GetNumNeighbors())
+	assert.Len(c.GetUnattachedVertices(), len(vertices)-1)
+}
+
+func TestSavings_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	c := circuit.NewSavings([]model.CircuitVertex{}, nil)
+	assert.Nil(c.GetHub())
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+
+	v := model2d.NewVertex2D(1, 2)
+	c = circuit.NewSavings([]model.CircuitVertex{v, v}, nil)
+	assert.Equal(v, c.GetHub())
+	next, _ = c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Equal([]model.CircuitVertex{v}, c.GetAttachedVertices())
+}
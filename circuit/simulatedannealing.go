@@ -27,17 +27,20 @@ type SimulatedAnnealing struct {
 	numIterations        float64
 	preferCloseNeighbors bool
 	random               *rand.Rand
+	source               *countingSource
 	temperatureFunction  func(currentIteration float64, maxIterations float64) float64
 }
 
 func NewSimulatedAnnealing(circuit []model.CircuitVertex, maxIterations int, preferCloseNeighbors bool) *SimulatedAnnealing {
+	random, source := newRandom(time.Now().UnixNano())
 	return &SimulatedAnnealing{
 		circuit:              circuit,
 		farthestDistance:     computeFarthestDistance(circuit),
 		maxIterations:        float64(maxIterations),
 		numIterations:        0.0,
 		preferCloseNeighbors: preferCloseNeighbors,
-		random:               rand.New(rand.NewSource(time.Now().UnixNano())),
+		random:               random,
+		source:               source,
 		temperatureFunction:  CalculateTemperatureLinear,
 	}
 }
@@ -48,13 +51,15 @@ func NewSimulatedAnnealingFromCircuit(circuit model.Circuit, maxIterations int,
 	}
 
 	initCircuit := circuit.GetAttachedVertices()
+	random, source := newRandom(time.Now().UnixNano())
 	return &SimulatedAnnealing{
 		circuit:              initCircuit,
 		farthestDistance:     computeFarthestDistance(initCircuit),
 		maxIterations:        float64(maxIterations),
 		numIterations:        0.0,
 		preferCloseNeighbors: preferCloseNeighbors,
-		random:               rand.New(rand.NewSource(time.Now().UnixNano())),
+		random:               random,
+		source:               source,
 		temperatureFunction:  CalculateTemperatureLinear,
 	}
 }
@@ -83,7 +88,7 @@ func (s *SimulatedAnnealing) GetUnattachedVertices() map[model.CircuitVertex]boo
 // SetSeed sets the seed used by the SimulatedAnnealing for random number generation.
 // This is to facilitate consistent unit tests.
 func (s *SimulatedAnnealing) SetSeed(seed int64) {
-	s.random = rand.New(rand.NewSource(seed))
+	s.random, s.source = newRandom(seed)
 }
 
 // SetTemperatureFunction updates the function used in each iteration of Update() to calculate the temperature.
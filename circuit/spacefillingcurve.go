@@ -0,0 +1,91 @@
+package circuit
+
+import (
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/spatial"
+)
+
+// CurveType determines which space-filling curve a SpaceFillingCurve uses to order its vertices.
+type CurveType int
+
+const (
+	// CurveHilbert orders the vertices along a Hilbert curve, which produces shorter circuits than a Morton curve.
+	CurveHilbert CurveType = iota
+	// CurveMorton orders the vertices along a Morton (Z-order) curve, which is slightly cheaper to compute than a Hilbert curve.
+	CurveMorton
+)
+
+// SpaceFillingCurve orders 2D or 3D vertices by their position along a [space-filling curve](https://en.wikipedia.org/wiki/Space-filling_curve), which visits every point of the vertices' bounding box.
+// Vertices that are close to each other along the curve are close to each other in space, so the order is a reasonable circuit.
+// Hilbert circuits are typically 25-45% longer than optimal (43% for usa13509), while Morton circuits are roughly twice as far from optimal, due to the curve's long jumps between quadrants.
+//
+// Unlike the other constructions, the entire circuit is attached when this is created, since sorting the vertices is O(n*log(n)) and completes in well under a second even for 100,000+ vertices.
+// This makes it a fast precursor for algorithms that improve an existing circuit, such as simulated annealing.
+type SpaceFillingCurve struct {
+	circuit   []model.CircuitVertex
+	curveType CurveType
+	length    float64
+}
+
+// NewHilbertCurve creates a complete circuit that visits the vertices in the order of a Hilbert curve.
+func NewHilbertCurve(vertices []model.CircuitVertex) *SpaceFillingCurve {
+	return NewSpaceFillingCurve(vertices, CurveHilbert)
+}
+
+// NewMortonCurve creates a complete circuit that visits the vertices in the order of a Morton (Z-order) curve.
+func NewMortonCurve(vertices []model.CircuitVertex) *SpaceFillingCurve {
+	return NewSpaceFillingCurve(vertices, CurveMorton)
+}
+
+// NewSpaceFillingCurve creates a complete circuit that visits the vertices in the order of the supplied type of curve.
+// The vertices must all be model2d.Vertex2D or all be model3d.Vertex3D; if they are not (e.g. they are graph vertices), they are visited in the order they are supplied.
+// Duplicate references to the same vertex are ignored.
+func NewSpaceFillingCurve(vertices []model.CircuitVertex, curveType CurveType) *SpaceFillingCurve {
+	unique := uniqueVertices(vertices)
+
+	var sorted []model.CircuitVertex
+	var okay bool
+	if curveType == CurveMorton {
+		sorted, okay = spatial.MortonOrder(unique)
+	} else {
+		sorted, okay = spatial.HilbertOrder(unique)
+	}
+	if !okay {
+		sorted = unique
+	}
+
+	return &SpaceFillingCurve{
+		circuit:   sorted,
+		curveType: curveType,
+		length:    model.Length(sorted),
+	}
+}
+
+// FindNextVertexAndEdge returns (nil, nil), since the circuit is complete once it is created.
+func (s *SpaceFillingCurve) FindNextVertexAndEdge() (model.CircuitVertex, model.CircuitEdge) {
+	return nil, nil
+}
+
+func (s *SpaceFillingCurve) GetAttachedVertices() []model.CircuitVertex {
+	return s.circuit
+}
+
+// GetCurveType returns the type of curve used to order the vertices.
+func (s *SpaceFillingCurve) GetCurveType() CurveType {
+	return s.curveType
+}
+
+func (s *SpaceFillingCurve) GetLength() float64 {
+	return s.length
+}
+
+// GetUnattachedVertices returns an empty map, since the circuit is complete once it is created.
+func (s *SpaceFillingCurve) GetUnattachedVertices() map[model.CircuitVertex]bool {
+	return make(map[model.CircuitVertex]bool)
+}
+
+// Update does nothing, since the circuit is complete once it is created.
+func (s *SpaceFillingCurve) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
+}
+
+var _ model.Circuit = (*SpaceFillingCurve)(nil)
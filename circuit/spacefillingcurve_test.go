@@ -0,0 +1,92 @@
+package circuit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/tsplib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpaceFillingCurve(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(0, 10),
+		model2d.NewVertex2D(10, 0),
+	}
+
+	c := circuit.NewHilbertCurve(vertices)
+	assert.Equal(circuit.CurveHilbert, c.GetCurveType())
+	assert.Equal([]model.CircuitVertex{vertices[0], vertices[2], vertices[1], vertices[3]}, c.GetAttachedVertices())
+	assert.InDelta(40.0, c.GetLength(), model.Threshold)
+	assert.Len(c.GetUnattachedVertices(), 0)
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+
+	// The Morton curve crosses diagonally between its second and third quadrants.
+	c = circuit.NewMortonCurve(vertices)
+	assert.Equal(circuit.CurveMorton, c.GetCurveType())
+	assert.Equal([]model.CircuitVertex{vertices[0], vertices[2], vertices[3], vertices[1]}, c.GetAttachedVertices())
+	assert.InDelta(20.0+2*vertices[0].DistanceTo(vertices[1]), c.GetLength(), model.Threshold)
+}
+
+func TestSpaceFillingCurve_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model3d.GenerateVertices(500)
+	for _, curveType := range []circuit.CurveType{circuit.CurveHilbert, circuit.CurveMorton} {
+		c := circuit.NewSpaceFillingCurve(vertices, curveType)
+		assert.ElementsMatch(vertices, c.GetAttachedVertices())
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	}
+}
+
+func TestSpaceFillingCurve_ShouldUseSuppliedOrderForGraphs(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    5,
+		MinEdges:    2,
+		NumVertices: 10,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+
+	c := circuit.NewHilbertCurve(append(vertices, vertices[0]))
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.InDelta(model.Length(vertices), c.GetLength(), model.Threshold)
+}
+
+func TestSpaceFillingCurve_Usa13509(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large data set in short mode")
+	}
+	assert := assert.New(t)
+
+	data, err := tsplib.NewData("../test-data/tsplib/usa13509.tsp")
+	assert.Nil(err)
+	vertices := data.GetVertices()
+
+	start := time.Now()
+	hilbert := circuit.NewHilbertCurve(vertices)
+	t.Logf("usa13509 hilbert took %v, length %f", time.Since(start), hilbert.GetLength())
+	start = time.Now()
+	morton := circuit.NewMortonCurve(vertices)
+	t.Logf("usa13509 morton took %v, length %f", time.Since(start), morton.GetLength())
+
+	assert.Len(hilbert.GetAttachedVertices(), len(vertices))
+	assert.Len(morton.GetAttachedVertices(), len(vertices))
+	// The optimal length of usa13509 is 19,982,859.
+	assert.Less(hilbert.GetLength(), 1.5*19982859)
+	assert.Less(hilbert.GetLength(), morton.GetLength())
+}
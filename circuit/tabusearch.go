@@ -0,0 +1,306 @@
+package circuit
+
+import (
+	"math"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// TabuSearchDefaultTenure is the default number of iterations for which TabuSearch prevents a removed edge from being added back to the circuit.
+const TabuSearchDefaultTenure = 50
+
+// TabuSearchDefaultFrequencyWeight is the default weight of the long-term frequency memory, see TabuSearch.SetFrequencyWeight.
+const TabuSearchDefaultFrequencyWeight = 1.0
+
+// The types of moves evaluated by TabuSearch.
+const (
+	tabuMoveNone = iota
+	tabuMoveTwoOpt
+	tabuMoveSwap
+	tabuMoveRelocate
+)
+
+// TabuSearch implements [tabu search](https://en.wikipedia.org/wiki/Tabu_search), which deterministically improves a completed circuit, and can escape local optima since it applies the best move in each iteration, even if it lengthens the circuit.
+// Unlike SimulatedAnnealing, it does not use random numbers, so the same circuit always produces the same result.
+//
+// During each iteration (up to "maxIterations" times) this:
+// 1. Evaluates every move from each vertex to its nearest neighbors (candidates), where each move is one of:
+//     * 2-opt - replace the edges (a,b) and (c,d) with (a,c) and (b,d), reversing the part of the circuit between them.
+//     * Swap - swap the positions of a and c in the circuit.
+//     * Relocate - move a to between c and one of its neighbors in the circuit.
+// 2. Skips moves that add an edge that was removed within the last "tenure" iterations (tabu moves), unless the move would produce a circuit shorter than the best circuit so far (aspiration).
+// 3. Penalizes moves that lengthen the circuit, based on how often their new edges have been added during the search (long-term frequency memory), so that the search is diversified towards edges it has not tried.
+// 4. Applies the move with the lowest (penalized) change in length, and records the edges it removed as tabu.
+//
+// GetAttachedVertices and GetLength return the best circuit found so far.
+// For asymmetric graphs, moves are evaluated as though the distances are symmetric, but GetLength always returns the actual length of the best circuit.
+type TabuSearch struct {
+	best            []int
+	bestCircuit     []model.CircuitVertex
+	bestCost        float64
+	bestLength      float64
+	bestLengthStale bool
+	cost            float64
+	frequency       map[int]int
+	frequencyWeight float64
+	maxIterations   int
+	meanCost        float64
+	numImprovements int
+	numIterations   int
+	search          *localSearch
+	tabuUntil       map[int]int
+	tenure          int
+}
+
+// tabuMove is a move evaluated by TabuSearch, along with the edges it removes from and adds to the circuit, excluding any edge that it both removes and adds.
+type tabuMove struct {
+	a          int
+	added      [4][2]int
+	b          int
+	c          int
+	d          int
+	delta      float64
+	evaluation float64
+	forward    bool
+	kind       int
+	numAdded   int
+	numRemoved int
+	removed    [4][2]int
+}
+
+// NewTabuSearch creates a TabuSearch circuit that improves the supplied circuit for the supplied number of iterations, considering moves to the supplied number of candidate neighbors of each vertex.
+// If the number of neighbors is less than 1, every vertex is a candidate, which is O(n^2) per iteration, and if the tenure is less than 1, TabuSearchDefaultTenure is used.
+// Duplicate references to the same vertex are ignored.
+func NewTabuSearch(circuit []model.CircuitVertex, numNeighbors int, maxIterations int, tenure int) *TabuSearch {
+	if tenure < 1 {
+		tenure = TabuSearchDefaultTenure
+	}
+	search := newLocalSearch(circuit, numNeighbors)
+	t := &TabuSearch{
+		best:            append([]int{}, search.tour...),
+		frequency:       make(map[int]int),
+		frequencyWeight: TabuSearchDefaultFrequencyWeight,
+		maxIterations:   maxIterations,
+		search:          search,
+		tabuUntil:       make(map[int]int),
+		tenure:          tenure,
+	}
+	for i, a := range search.tour {
+		t.cost += search.cost(a, search.tour[(i+1)%len(search.tour)])
+	}
+	if len(search.tour) > 0 {
+		t.meanCost = t.cost / float64(len(search.tour))
+	}
+	t.bestCost = t.cost
+	t.bestLength = search.getLength()
+	return t
+}
+
+// NewTabuSearchFromCircuit completes the supplied circuit, then creates a TabuSearch circuit that improves it.
+func NewTabuSearchFromCircuit(circuit model.Circuit, numNeighbors int, maxIterations int, tenure int) *TabuSearch {
+	return NewTabuSearch(attachAll(circuit), numNeighbors, maxIterations, tenure)
+}
+
+// FindNextVertexAndEdge returns (nil, nil) once every iteration is complete, otherwise it returns the first vertex in the circuit, which is ignored by Update.
+func (t *TabuSearch) FindNextVertexAndEdge() (model.CircuitVertex, model.CircuitEdge) {
+	if t.numIterations >= t.maxIterations || len(t.search.tour) < 4 {
+		return nil, nil
+	}
+	return t.search.vertices[t.search.tour[0]], nil
+}
+
+// GetAttachedVertices returns the best circuit found so far.
+func (t *TabuSearch) GetAttachedVertices() []model.CircuitVertex {
+	if t.bestCircuit == nil {
+		t.bestCircuit = toVertices(t.search.vertices, t.best)
+	}
+	return t.bestCircuit
+}
+
+// GetLength returns the length of the best circuit found so far.
+func (t *TabuSearch) GetLength() float64 {
+	if t.bestLengthStale {
+		t.bestLength = model.Length(t.GetAttachedVertices())
+		t.bestLengthStale = false
+	}
+	return t.bestLength
+}
+
+// GetNumImprovements returns the number of times that the search has found a circuit shorter than the best circuit so far.
+func (t *TabuSearch) GetNumImprovements() int {
+	return t.numImprovements
+}
+
+// GetNumIterations returns the number of iterations that have been completed.
+func (t *TabuSearch) GetNumIterations() int {
+	return t.numIterations
+}
+
+// GetTenure returns the number of iterations for which a removed edge cannot be added back to the circuit, unless that produces a new best circuit.
+func (t *TabuSearch) GetTenure() int {
+	return t.tenure
+}
+
+func (t *TabuSearch) GetUnattachedVertices() map[model.CircuitVertex]bool {
+	return make(map[model.CircuitVertex]bool)
+}
+
+// SetFrequencyWeight sets the weight of the long-term frequency memory, which penalizes each move that lengthens the circuit by:
+// weight * (the average length of an edge in the initial circuit) * (the number of times its new edges have been added) / (the number of iterations).
+// A weight of 0 disables the frequency memory.
+func (t *TabuSearch) SetFrequencyWeight(weight float64) {
+	t.frequencyWeight = weight
+}
+
+// Update applies the best admissible move to the circuit, and updates the tabu edges and frequency memory. The supplied vertex and edge are ignored.
+func (t *TabuSearch) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
+	if t.numIterations >= t.maxIterations || len(t.search.tour) < 4 {
+		return
+	}
+	t.numIterations++
+
+	s := t.search
+	best := tabuMove{evaluation: math.Inf(1)}
+	for a := range s.vertices {
+		previousA, nextA := s.previous(a), s.next(a)
+		for _, c := range s.candidates[a] {
+			previousC, nextC := s.previous(c), s.next(c)
+
+			if c != nextA && nextC != a {
+				t.consider(&best, tabuMove{
+					kind: tabuMoveTwoOpt, a: a, b: nextA, c: c, d: nextC, forward: true,
+					removed: [4][2]int{{a, nextA}, {c, nextC}}, numRemoved: 2,
+					added: [4][2]int{{a, c}, {nextA, nextC}}, numAdded: 2,
+				})
+			}
+			if c != previousA && previousC != a {
+				t.consider(&best, tabuMove{
+					kind: tabuMoveTwoOpt, a: a, b: previousA, c: c, d: previousC, forward: false,
+					removed: [4][2]int{{a, previousA}, {c, previousC}}, numRemoved: 2,
+					added: [4][2]int{{a, c}, {previousA, previousC}}, numAdded: 2,
+				})
+			}
+
+			// Adjacent vertices keep the edge between them when they are swapped.
+			swap := tabuMove{
+				kind: tabuMoveSwap, a: a, c: c,
+				removed: [4][2]int{{previousA, a}, {a, nextA}, {previousC, c}, {c, nextC}}, numRemoved: 4,
+				added: [4][2]int{{previousA, c}, {c, nextA}, {previousC, a}, {a, nextC}}, numAdded: 4,
+			}
+			if c == nextA {
+				swap.removed, swap.added, swap.numRemoved, swap.numAdded = [4][2]int{{previousA, a}, {c, nextC}}, [4][2]int{{previousA, c}, {a, nextC}}, 2, 2
+			} else if c == previousA {
+				swap.removed, swap.added, swap.numRemoved, swap.numAdded = [4][2]int{{previousC, c}, {a, nextA}}, [4][2]int{{previousC, a}, {c, nextA}}, 2, 2
+			}
+			t.consider(&best, swap)
+
+			for _, x := range [2]int{previousC, c} {
+				if y := s.next(x); x != a && y != a {
+					t.consider(&best, tabuMove{
+						kind: tabuMoveRelocate, a: a, c: x, d: y,
+						removed: [4][2]int{{previousA, a}, {a, nextA}, {x, y}}, numRemoved: 3,
+						added: [4][2]int{{previousA, nextA}, {x, a}, {a, y}}, numAdded: 3,
+					})
+				}
+			}
+		}
+	}
+
+	if best.kind != tabuMoveNone {
+		t.apply(best)
+	}
+}
+
+// consider evaluates the supplied move, and replaces the best move with it if it is admissible and has a lower evaluation.
+func (t *TabuSearch) consider(best *tabuMove, move tabuMove) {
+	s := t.search
+	// Edges that are both removed and added by a move (e.g. when swapping vertices that share a neighbor) are unchanged.
+	for i := 0; i < move.numRemoved; i++ {
+		for j := 0; j < move.numAdded; j++ {
+			if s.edgeKey(move.removed[i][0], move.removed[i][1]) == s.edgeKey(move.added[j][0], move.added[j][1]) {
+				move.numRemoved--
+				move.numAdded--
+				move.removed[i], move.added[j] = move.removed[move.numRemoved], move.added[move.numAdded]
+				i--
+				break
+			}
+		}
+	}
+	if move.numAdded == 0 {
+		return
+	}
+
+	for _, edge := range move.added[:move.numAdded] {
+		move.delta += s.cost(edge[0], edge[1])
+	}
+	for _, edge := range move.removed[:move.numRemoved] {
+		move.delta -= s.cost(edge[0], edge[1])
+	}
+	// The penalty is never negative, so the tabu and frequency memory only need to be checked if the move could be better than the best move.
+	if move.delta >= best.evaluation {
+		return
+	}
+
+	isTabu := false
+	frequency := 0
+	for _, edge := range move.added[:move.numAdded] {
+		key := s.edgeKey(edge[0], edge[1])
+		isTabu = isTabu || t.tabuUntil[key] >= t.numIterations
+		frequency += t.frequency[key]
+	}
+	if isTabu && t.cost+move.delta >= t.bestCost-model.Threshold {
+		return
+	}
+
+	move.evaluation = move.delta
+	if move.delta > 0 {
+		move.evaluation += t.frequencyWeight * t.meanCost * float64(frequency) / float64(t.numIterations)
+	}
+	if move.evaluation < best.evaluation {
+		*best = move
+	}
+}
+
+// apply applies the supplied move, records the edges it removed as tabu and the edges it added in the frequency memory, and saves the circuit if it is the best so far.
+func (t *TabuSearch) apply(move tabuMove) {
+	s := t.search
+	switch move.kind {
+	case tabuMoveTwoOpt:
+		s.applyTwoOpt(move.a, move.b, move.c, move.d, move.forward)
+	case tabuMoveRelocate:
+		s.applyOrOpt(move.a, move.a, move.c, move.d, false)
+	case tabuMoveSwap:
+		if s.symmetric {
+			for _, edge := range move.added[:move.numAdded] {
+				s.length += s.distance(edge[0], edge[1])
+			}
+			for _, edge := range move.removed[:move.numRemoved] {
+				s.length -= s.distance(edge[0], edge[1])
+			}
+		} else {
+			s.lengthIsStale = true
+		}
+		positionA, positionC := s.position[move.a], s.position[move.c]
+		s.tour[positionA], s.tour[positionC] = move.c, move.a
+		s.position[move.a], s.position[move.c] = positionC, positionA
+	}
+	t.cost += move.delta
+
+	for _, edge := range move.removed[:move.numRemoved] {
+		t.tabuUntil[t.search.edgeKey(edge[0], edge[1])] = t.numIterations + t.tenure
+	}
+	for _, edge := range move.added[:move.numAdded] {
+		t.frequency[t.search.edgeKey(edge[0], edge[1])]++
+	}
+
+	if t.cost < t.bestCost-model.Threshold {
+		t.bestCost = t.cost
+		t.best = append(t.best[:0], s.tour...)
+		t.bestCircuit = nil
+		t.bestLength = s.length
+		t.bestLengthStale = !s.symmetric
+		t.numImprovements++
+	}
+}
+
+var _ model.Circuit = (*TabuSearch)(nil)
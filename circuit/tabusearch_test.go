@@ -0,0 +1,151 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTabuSearch(t *testing.T) {
+	assert := assert.New(t)
+
+	// The initial circuit crosses itself, between (0,0)-(10,10) and (10,0)-(0,10).
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(0, 10),
+	}
+	c := circuit.NewTabuSearch(vertices, 0, 5, 0)
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(vertices), c.GetLength(), model.Threshold)
+	assert.Equal(circuit.TabuSearchDefaultTenure, c.GetTenure())
+
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Equal(vertices[0], next)
+	assert.Nil(edge)
+	for ; next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+
+	// After the first move, every other move lengthens the circuit, but the best circuit is retained.
+	assert.Equal(5, c.GetNumIterations())
+	assert.Equal(1, c.GetNumImprovements())
+	assert.InDelta(40.0, c.GetLength(), model.Threshold)
+	assert.InDelta(40.0, model.Length(c.GetAttachedVertices()), model.Threshold)
+	assert.Len(c.GetAttachedVertices(), 4)
+	assert.Len(c.GetUnattachedVertices(), 0)
+}
+
+func TestTabuSearch_ShouldMatchOptimalForSmallCircuits(t *testing.T) {
+	assert := assert.New(t)
+
+	for i := 0; i < 5; i++ {
+		vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(9))
+		c := circuit.NewTabuSearch(vertices, 0, 100, 5)
+		solver.FindShortestPathCircuit(c)
+
+		_, optimalLength := solver.FindShortestPathNPHeap(vertices)
+		assert.Len(c.GetAttachedVertices(), len(vertices))
+		assert.Equal(100, c.GetNumIterations())
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+		assert.InDelta(optimalLength, c.GetLength(), model.Threshold)
+	}
+}
+
+func TestTabuSearch_ShouldBeDeterministic(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(150))
+	nearestNeighbor := circuit.NewNearestNeighbor(vertices)
+	solver.FindShortestPathCircuit(nearestNeighbor)
+	initialLength := nearestNeighbor.GetLength()
+
+	var expected []model.CircuitVertex
+	for i := 0; i < 2; i++ {
+		c := circuit.NewTabuSearchFromCircuit(circuit.NewNearestNeighbor(vertices), 8, 500, 0)
+		solver.FindShortestPathCircuit(c)
+		assert.Len(c.GetAttachedVertices(), len(vertices))
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+		assert.Less(c.GetLength(), initialLength)
+		if expected == nil {
+			expected = c.GetAttachedVertices()
+		} else {
+			assert.Equal(expected, c.GetAttachedVertices())
+		}
+	}
+}
+
+func TestTabuSearch_FrequencyWeight(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(100))
+	for _, weight := range []float64{0.0, 0.5, 2.0} {
+		c := circuit.NewTabuSearch(vertices, 6, 200, 10)
+		c.SetFrequencyWeight(weight)
+		solver.FindShortestPathCircuit(c)
+		assert.Len(c.GetAttachedVertices(), len(vertices))
+		assert.Greater(c.GetNumImprovements(), 0)
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+		assert.Less(c.GetLength(), model.Length(vertices))
+	}
+}
+
+func TestTabuSearch_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model3d.GenerateVertices(100)
+	initialLength := model.Length(vertices)
+	c := circuit.NewTabuSearch(vertices, 8, 200, 15)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+}
+
+func TestTabuSearch_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    5,
+		MinEdges:    2,
+		NumVertices: 30,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+	initialLength := model.Length(vertices)
+
+	c := circuit.NewTabuSearch(vertices, 5, 100, 10)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+}
+
+func TestTabuSearch_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	c := circuit.NewTabuSearch([]model.CircuitVertex{}, 5, 10, 5)
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+	assert.Len(c.GetAttachedVertices(), 0)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(1, 2),
+		model2d.NewVertex2D(3, 2),
+		model2d.NewVertex2D(1, 5),
+	}
+	c = circuit.NewTabuSearch(append(vertices, vertices[0]), 5, 10, 5)
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.Equal(0, c.GetNumIterations())
+}
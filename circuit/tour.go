@@ -0,0 +1,84 @@
+package circuit
+
+import (
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// tourCircuit attaches the vertices of a precomputed tour one at a time, in order, so that constructions that compute their entire tour up front (e.g. Christofides)
+// can be observed and stopped like the algorithms that build their circuit incrementally.
+// Each vertex is attached between the last and first attached vertices, so the circuit always follows the order of the tour.
+type tourCircuit struct {
+	circuit    []model.CircuitVertex
+	length     float64
+	tour       []model.CircuitVertex
+	tourIndex  int
+	unattached map[model.CircuitVertex]bool
+}
+
+// newTourCircuit creates a tourCircuit for the supplied tour, which must not contain duplicate vertices, and attaches the first vertex of the tour.
+func newTourCircuit(tour []model.CircuitVertex) *tourCircuit {
+	t := &tourCircuit{
+		circuit:    make([]model.CircuitVertex, 0, len(tour)),
+		length:     0.0,
+		tour:       tour,
+		unattached: make(map[model.CircuitVertex]bool, len(tour)),
+	}
+	for _, v := range tour {
+		t.unattached[v] = true
+	}
+	if len(tour) > 0 {
+		t.Update(tour[0], nil)
+	}
+	return t
+}
+
+// FindNextVertexAndEdge returns the next unattached vertex of the tour, and the edge from the last attached vertex back to the first vertex.
+func (t *tourCircuit) FindNextVertexAndEdge() (model.CircuitVertex, model.CircuitEdge) {
+	for t.tourIndex < len(t.tour) && !t.unattached[t.tour[t.tourIndex]] {
+		t.tourIndex++
+	}
+	if t.tourIndex >= len(t.tour) {
+		return nil, nil
+	}
+	return t.tour[t.tourIndex], t.circuit[len(t.circuit)-1].EdgeTo(t.circuit[0])
+}
+
+func (t *tourCircuit) GetAttachedVertices() []model.CircuitVertex {
+	return t.circuit
+}
+
+func (t *tourCircuit) GetLength() float64 {
+	return t.length
+}
+
+func (t *tourCircuit) GetUnattachedVertices() map[model.CircuitVertex]bool {
+	return t.unattached
+}
+
+// Update attaches the supplied vertex to the end of the circuit. The supplied edge is ignored, since vertices are always attached between the last and first vertices.
+func (t *tourCircuit) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
+	if vertexToAdd == nil || !t.unattached[vertexToAdd] {
+		return
+	}
+	if numAttached := len(t.circuit); numAttached > 0 {
+		first, last := t.circuit[0], t.circuit[numAttached-1]
+		t.length += last.DistanceTo(vertexToAdd) + vertexToAdd.DistanceTo(first) - last.DistanceTo(first)
+	}
+	t.circuit = append(t.circuit, vertexToAdd)
+	delete(t.unattached, vertexToAdd)
+}
+
+// uniqueVertices returns the supplied vertices without duplicate references to the same vertex, preserving their order.
+func uniqueVertices(vertices []model.CircuitVertex) []model.CircuitVertex {
+	unique := make([]model.CircuitVertex, 0, len(vertices))
+	seen := make(map[model.CircuitVertex]bool, len(vertices))
+	for _, v := range vertices {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+var _ model.Circuit = (*tourCircuit)(nil)
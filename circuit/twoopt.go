@@ -0,0 +1,37 @@
+package circuit
+
+import (
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// TwoOpt implements the [2-opt](https://en.wikipedia.org/wiki/2-opt) local search, which deterministically improves a completed circuit by removing crossing (and other inefficient) pairs of edges.
+// Unlike SimulatedAnnealing, which swaps two vertices at random, each move replaces two edges (a,b) and (c,d) with (a,c) and (b,d), reversing the part of the circuit between them, and only moves that shorten the circuit are applied.
+//
+// To scale to tens of thousands of vertices, this uses two standard optimizations:
+// 1. Candidate lists - moves are only considered between a vertex and its candidate neighbors (e.g. LocalSearchDefaultNeighbors), found with a k-d tree for 2D and 3D vertices, and spread across the quadrants around each 2D vertex.
+// 2. Don't-look bits - each vertex is checked once, and is only checked again if one of its edges changes, so later passes only revisit the parts of the circuit that changed.
+//
+// Each call to Update checks one vertex, and applies the best improving move from it, if any. The circuit is complete (FindNextVertexAndEdge returns nil) once every vertex has been checked without finding an improving move since its edges last changed.
+// For asymmetric graphs, moves are evaluated as though the distances are symmetric, so a move may not shorten the circuit; GetLength always returns the actual length of the circuit.
+type TwoOpt struct {
+	*localSearchCircuit
+}
+
+// NewTwoOpt creates a TwoOpt circuit that improves the supplied circuit, considering moves to the supplied number of candidate neighbors of each vertex.
+// If the number of neighbors is less than 1, every pair of edges is considered, which is O(n^2) per pass. Duplicate references to the same vertex are ignored.
+func NewTwoOpt(circuit []model.CircuitVertex, numNeighbors int) *TwoOpt {
+	search := newLocalSearch(circuit, numNeighbors)
+	return &TwoOpt{
+		localSearchCircuit: &localSearchCircuit{
+			improve: search.improveTwoOpt,
+			search:  search,
+		},
+	}
+}
+
+// NewTwoOptFromCircuit completes the supplied circuit, then creates a TwoOpt circuit that improves it.
+func NewTwoOptFromCircuit(circuit model.Circuit, numNeighbors int) *TwoOpt {
+	return NewTwoOpt(attachAll(circuit), numNeighbors)
+}
+
+var _ model.Circuit = (*TwoOpt)(nil)
@@ -0,0 +1,195 @@
+package circuit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/heustis/tsp-solver-go/tsplib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoOpt(t *testing.T) {
+	assert := assert.New(t)
+
+	// The initial circuit crosses itself, between (0,0)-(10,10) and (10,0)-(0,10).
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 10),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(0, 10),
+	}
+	c := circuit.NewTwoOpt(vertices, 2)
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.Len(c.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(vertices), c.GetLength(), model.Threshold)
+
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Equal(vertices[0], next)
+	assert.Nil(edge)
+	for ; next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+	}
+
+	assert.Equal(1, c.GetNumImprovements())
+	assert.Equal([]model.CircuitVertex{vertices[0], vertices[2], vertices[1], vertices[3]}, c.GetAttachedVertices())
+	assert.InDelta(40.0, c.GetLength(), model.Threshold)
+	assert.Len(c.GetUnattachedVertices(), 0)
+}
+
+func TestTwoOpt_ShouldMatchOptimalForSmallCircuits(t *testing.T) {
+	assert := assert.New(t)
+
+	for i := 0; i < 5; i++ {
+		vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(8))
+		c := circuit.NewTwoOpt(vertices, 0)
+		solver.FindShortestPathCircuit(c)
+
+		_, optimalLength := solver.FindShortestPathNPHeap(vertices)
+		assert.Len(c.GetAttachedVertices(), len(vertices))
+		assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+		// 2-opt is not guaranteed to be optimal, but is close to optimal for small circuits.
+		assert.LessOrEqual(c.GetLength(), 1.1*optimalLength)
+		assert.LessOrEqual(c.GetLength(), model.Length(vertices)+model.Threshold)
+	}
+}
+
+func TestTwoOpt_ShouldNotHaveIntersectingEdges(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(300))
+	c := circuit.NewTwoOptFromCircuit(circuit.NewHilbertCurve(vertices), circuit.LocalSearchDefaultNeighbors)
+	initialLength := model.Length(c.GetAttachedVertices())
+	solver.FindShortestPathCircuit(c)
+
+	result := c.GetAttachedVertices()
+	assert.Len(result, len(vertices))
+	assert.Greater(c.GetNumImprovements(), 0)
+	assert.Less(c.GetLength(), initialLength)
+	assert.InDelta(model.Length(result), c.GetLength(), model.Threshold)
+
+	edges := make([]*model2d.Edge2D, len(result))
+	for i, v := range result {
+		edges[i] = v.EdgeTo(result[(i+1)%len(result)]).(*model2d.Edge2D)
+	}
+	for i, a := range edges {
+		for j := i + 2; j < len(edges); j++ {
+			if i == 0 && j == len(edges)-1 {
+				continue
+			}
+			assert.False(a.Intersects(edges[j]), "edges %d and %d intersect", i, j)
+		}
+	}
+}
+
+func TestTwoOpt_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model3d.GenerateVertices(200)
+	initialLength := model.Length(vertices)
+	c := circuit.NewTwoOpt(vertices, 8)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+}
+
+func TestTwoOpt_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    5,
+		MinEdges:    2,
+		NumVertices: 30,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+	initialLength := model.Length(vertices)
+
+	c := circuit.NewTwoOpt(vertices, 5)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	assert.Less(c.GetLength(), initialLength)
+}
+
+func TestTwoOpt_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	c := circuit.NewTwoOpt([]model.CircuitVertex{}, 5)
+	next, edge := c.FindNextVertexAndEdge()
+	assert.Nil(next)
+	assert.Nil(edge)
+	assert.Len(c.GetAttachedVertices(), 0)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(1, 2),
+		model2d.NewVertex2D(3, 2),
+		model2d.NewVertex2D(1, 5),
+	}
+	c = circuit.NewTwoOpt(append(vertices, vertices[0]), 5)
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(vertices, c.GetAttachedVertices())
+	assert.Equal(0, c.GetNumImprovements())
+}
+
+func TestTwoOpt_Usa13509(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large data set in short mode")
+	}
+	assert := assert.New(t)
+
+	data, err := tsplib.NewData("../test-data/tsplib/usa13509.tsp")
+	assert.Nil(err)
+	vertices := data.GetVertices()
+
+	start := time.Now()
+	c := circuit.NewTwoOptFromCircuit(circuit.NewGreedyEdge(vertices), circuit.LocalSearchDefaultNeighbors)
+	solver.FindShortestPathCircuit(c)
+	duration := time.Since(start)
+	t.Logf("usa13509 took %v, with %d improvements", duration, c.GetNumImprovements())
+
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), 1e-6*c.GetLength())
+	// The optimal length of usa13509 is 19,982,859, and 2-opt improves greedy edge from around 20% to within 10% of it.
+	assert.Less(c.GetLength(), 1.1*19982859)
+	assert.Less(duration, 10*time.Second)
+}
+
+func TestTwoOpt_ShouldConnectAcrossGrids(t *testing.T) {
+	assert := assert.New(t)
+
+	// These are drilling problems, whose points are mostly in grids, so the nearest neighbors of a point are often collinear with it, or all on one side of it.
+	// The candidates include neighbors in each quadrant around each point, so 2-opt can still improve the greedy edge circuits to within 6% and 10% of optimal.
+	for name, maxRatio := range map[string]float64{"pcb442": 1.06, "pr2392": 1.10} {
+		data, err := tsplib.NewData("../test-data/tsplib/" + name + ".tsp")
+		assert.Nil(err)
+
+		c := circuit.NewTwoOptFromCircuit(circuit.NewGreedyEdge(data.GetVertices()), circuit.LocalSearchDefaultNeighbors)
+		solver.FindShortestPathCircuit(c)
+		assert.Len(c.GetAttachedVertices(), data.GetNumPoints(), name)
+		assert.Less(c.GetLength(), maxRatio*data.GetBestRouteLength(), name)
+	}
+}
+
+func TestTwoOpt_ShouldFindCandidatesQuicklyForCollinearVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	// Every point has two empty quadrants, which must not require checking every other point to find their candidates.
+	vertices := make([]model.CircuitVertex, 20000)
+	for i := range vertices {
+		vertices[i] = model2d.NewVertex2D(float64(i), 0)
+	}
+
+	start := time.Now()
+	c := circuit.NewTwoOpt(vertices, circuit.LocalSearchDefaultNeighbors)
+	assert.Less(time.Since(start), 2*time.Second)
+	solver.FindShortestPathCircuit(c)
+	assert.InDelta(2*19999.0, c.GetLength(), model.Threshold)
+}
@@ -0,0 +1,658 @@
+// Package matching contains graph matching algorithms, such as the minimum-weight perfect matching used by the Christofides algorithm.
+package matching
+
+import (
+	"math"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// Edge is an undirected, weighted edge between the vertices at indices I and J.
+type Edge struct {
+	I      int
+	J      int
+	Weight float64
+}
+
+// MinWeightPerfectMatching returns the perfect matching with the smallest total weight on a complete graph with the supplied number of vertices.
+// The weight function is called once for each pair of vertices (i, j) where i < j. The number of vertices must be even, otherwise one vertex is left unmatched.
+// The result contains the index of the vertex that each vertex is matched to, or -1 if it is unmatched.
+func MinWeightPerfectMatching(numVertices int, weight func(i int, j int) float64) []int {
+	if numVertices < 2 {
+		mate := make([]int, numVertices)
+		for i := range mate {
+			mate[i] = -1
+		}
+		return mate
+	}
+	edges := make([]Edge, 0, numVertices*(numVertices-1)/2)
+	maxWeight := 0.0
+	for i := 0; i < numVertices; i++ {
+		for j := i + 1; j < numVertices; j++ {
+			w := weight(i, j)
+			maxWeight = math.Max(maxWeight, w)
+			edges = append(edges, Edge{I: i, J: j, Weight: w})
+		}
+	}
+	// Every maximum-cardinality matching of a complete graph has the same number of edges, so maximizing (maxWeight - weight) minimizes the total weight.
+	for k := range edges {
+		edges[k].Weight = maxWeight - edges[k].Weight
+	}
+	return MaxWeightMatching(edges, true)
+}
+
+// MaxWeightMatching computes a maximum-weight matching of the supplied general (i.e. not necessarily bipartite) graph,
+// using [Edmonds' blossom algorithm](https://en.wikipedia.org/wiki/Blossom_algorithm) with the primal-dual method in O(n^3).
+// If maxCardinality is true, it returns the maximum-weight matching among the matchings with the most edges.
+//
+// The result contains, for each vertex, the index of the vertex that it is matched to, or -1 if it is unmatched.
+// This is a port of Joris van Rantwijk's reference implementation, which follows "An O(EV log V) algorithm for finding a maximal weighted matching in general graphs" by Galil, Micali and Gabow.
+func MaxWeightMatching(edges []Edge, maxCardinality bool) []int {
+	if len(edges) == 0 {
+		return []int{}
+	}
+	m := newBlossomMatcher(edges, maxCardinality)
+	m.solve()
+	return m.result()
+}
+
+// blossomMatcher contains the state of the blossom algorithm.
+// Vertices are numbered 0..n-1, and non-trivial blossoms are numbered n..2n-1.
+// Each edge k has two endpoints, 2k and 2k+1, so that the remote endpoint of p is p^1.
+type blossomMatcher struct {
+	edges          []Edge
+	maxCardinality bool
+	numVertices    int
+
+	allowEdge        []bool
+	bestEdge         []int
+	blossomBase      []int
+	blossomBestEdges [][]int
+	blossomChildren  [][]int
+	blossomEndpoints [][]int
+	blossomParent    []int
+	dualVar          []float64
+	endpoint         []int
+	inBlossom        []int
+	// label is 0 for unlabeled, 1 for an S-vertex/blossom, 2 for a T-vertex/blossom, and has bit 4 set temporarily while scanning for blossoms.
+	label          []int
+	labelEnd       []int
+	mate           []int
+	neighborEnds   [][]int
+	queue          []int
+	unusedBlossoms []int
+}
+
+func newBlossomMatcher(edges []Edge, maxCardinality bool) *blossomMatcher {
+	numVertices := 0
+	maxWeight := 0.0
+	for _, e := range edges {
+		if e.I >= numVertices {
+			numVertices = e.I + 1
+		}
+		if e.J >= numVertices {
+			numVertices = e.J + 1
+		}
+		maxWeight = math.Max(maxWeight, e.Weight)
+	}
+
+	m := &blossomMatcher{
+		edges:            edges,
+		maxCardinality:   maxCardinality,
+		numVertices:      numVertices,
+		allowEdge:        make([]bool, len(edges)),
+		bestEdge:         make([]int, 2*numVertices),
+		blossomBase:      make([]int, 2*numVertices),
+		blossomBestEdges: make([][]int, 2*numVertices),
+		blossomChildren:  make([][]int, 2*numVertices),
+		blossomEndpoints: make([][]int, 2*numVertices),
+		blossomParent:    make([]int, 2*numVertices),
+		dualVar:          make([]float64, 2*numVertices),
+		endpoint:         make([]int, 2*len(edges)),
+		inBlossom:        make([]int, numVertices),
+		label:            make([]int, 2*numVertices),
+		labelEnd:         make([]int, 2*numVertices),
+		mate:             make([]int, numVertices),
+		neighborEnds:     make([][]int, numVertices),
+		unusedBlossoms:   make([]int, 0, numVertices),
+	}
+
+	for k, e := range edges {
+		m.endpoint[2*k] = e.I
+		m.endpoint[2*k+1] = e.J
+		m.neighborEnds[e.I] = append(m.neighborEnds[e.I], 2*k+1)
+		m.neighborEnds[e.J] = append(m.neighborEnds[e.J], 2*k)
+	}
+	for v := 0; v < numVertices; v++ {
+		m.mate[v] = -1
+		m.inBlossom[v] = v
+		m.blossomBase[v] = v
+		m.blossomBase[numVertices+v] = -1
+		m.dualVar[v] = maxWeight
+		m.unusedBlossoms = append(m.unusedBlossoms, numVertices+v)
+	}
+	for b := range m.blossomParent {
+		m.blossomParent[b] = -1
+		m.bestEdge[b] = -1
+		m.labelEnd[b] = -1
+	}
+	return m
+}
+
+// solve runs one stage per vertex, where each stage either augments the matching or determines that no augmenting path exists.
+func (m *blossomMatcher) solve() {
+	n := m.numVertices
+	for t := 0; t < n; t++ {
+		for b := range m.label {
+			m.label[b] = 0
+			m.bestEdge[b] = -1
+		}
+		for b := n; b < 2*n; b++ {
+			m.blossomBestEdges[b] = nil
+		}
+		for k := range m.allowEdge {
+			m.allowEdge[k] = false
+		}
+		m.queue = m.queue[:0]
+
+		for v := 0; v < n; v++ {
+			if m.mate[v] == -1 && m.label[m.inBlossom[v]] == 0 {
+				m.assignLabel(v, 1, -1)
+			}
+		}
+
+		augmented := false
+		for {
+			augmented = m.scanQueue()
+			if augmented || !m.updateDuals() {
+				break
+			}
+		}
+		if !augmented {
+			break
+		}
+
+		// Expand S-blossoms whose dual variable has reached zero, at the end of the stage.
+		// The duals are sums and differences of floating point weights, so they are compared using model.Threshold rather than exactly.
+		for b := n; b < 2*n; b++ {
+			if m.blossomParent[b] == -1 && m.blossomBase[b] >= 0 && m.label[b] == 1 && m.dualVar[b] <= model.Threshold {
+				m.expandBlossom(b, true)
+			}
+		}
+	}
+}
+
+// scanQueue grows the alternating trees from the queued S-vertices along tight edges, and returns true if the matching was augmented.
+func (m *blossomMatcher) scanQueue() bool {
+	for len(m.queue) > 0 {
+		v := m.queue[len(m.queue)-1]
+		m.queue = m.queue[:len(m.queue)-1]
+
+		for _, p := range m.neighborEnds[v] {
+			k := p / 2
+			w := m.endpoint[p]
+			if m.inBlossom[v] == m.inBlossom[w] {
+				continue
+			}
+			kSlack := 0.0
+			if !m.allowEdge[k] {
+				if kSlack = m.slack(k); kSlack <= model.Threshold {
+					m.allowEdge[k] = true
+				}
+			}
+			if m.allowEdge[k] {
+				if m.label[m.inBlossom[w]] == 0 {
+					m.assignLabel(w, 2, p^1)
+				} else if m.label[m.inBlossom[w]] == 1 {
+					if base := m.scanBlossom(v, w); base >= 0 {
+						m.addBlossom(base, k)
+					} else {
+						m.augmentMatching(k)
+						return true
+					}
+				} else if m.label[w] == 0 {
+					// w is inside a T-blossom, but has not been reached from an S-vertex yet.
+					m.label[w] = 2
+					m.labelEnd[w] = p ^ 1
+				}
+			} else if m.label[m.inBlossom[w]] == 1 {
+				if b := m.inBlossom[v]; m.bestEdge[b] == -1 || kSlack < m.slack(m.bestEdge[b]) {
+					m.bestEdge[b] = k
+				}
+			} else if m.label[w] == 0 {
+				if m.bestEdge[w] == -1 || kSlack < m.slack(m.bestEdge[w]) {
+					m.bestEdge[w] = k
+				}
+			}
+		}
+	}
+	return false
+}
+
+// updateDuals changes the dual variables by the largest amount that keeps them feasible, which makes at least one new edge tight or blossom expandable.
+// It returns false if no further progress is possible in this stage.
+func (m *blossomMatcher) updateDuals() bool {
+	n := m.numVertices
+	deltaType := -1
+	delta := 0.0
+	deltaEdge := -1
+	deltaBlossom := -1
+
+	if !m.maxCardinality {
+		deltaType = 1
+		delta = m.minVertexDual()
+	}
+	for v := 0; v < n; v++ {
+		if m.label[m.inBlossom[v]] == 0 && m.bestEdge[v] != -1 {
+			if d := m.slack(m.bestEdge[v]); deltaType == -1 || d < delta {
+				delta = d
+				deltaType = 2
+				deltaEdge = m.bestEdge[v]
+			}
+		}
+	}
+	for b := 0; b < 2*n; b++ {
+		if m.blossomParent[b] == -1 && m.label[b] == 1 && m.bestEdge[b] != -1 {
+			if d := m.slack(m.bestEdge[b]) / 2; deltaType == -1 || d < delta {
+				delta = d
+				deltaType = 3
+				deltaEdge = m.bestEdge[b]
+			}
+		}
+	}
+	for b := n; b < 2*n; b++ {
+		if m.blossomBase[b] >= 0 && m.blossomParent[b] == -1 && m.label[b] == 2 && (deltaType == -1 || m.dualVar[b] < delta) {
+			delta = m.dualVar[b]
+			deltaType = 4
+			deltaBlossom = b
+		}
+	}
+	if deltaType == -1 {
+		// No further improvement is possible with maximum cardinality; optimize the remaining duals and stop.
+		deltaType = 1
+		delta = math.Max(0, m.minVertexDual())
+	}
+
+	for v := 0; v < n; v++ {
+		switch m.label[m.inBlossom[v]] {
+		case 1:
+			m.dualVar[v] -= delta
+		case 2:
+			m.dualVar[v] += delta
+		}
+	}
+	for b := n; b < 2*n; b++ {
+		if m.blossomBase[b] >= 0 && m.blossomParent[b] == -1 {
+			switch m.label[b] {
+			case 1:
+				m.dualVar[b] += delta
+			case 2:
+				m.dualVar[b] -= delta
+			}
+		}
+	}
+
+	switch deltaType {
+	case 1:
+		return false
+	case 2:
+		m.allowEdge[deltaEdge] = true
+		i, j := m.edges[deltaEdge].I, m.edges[deltaEdge].J
+		if m.label[m.inBlossom[i]] == 0 {
+			i = j
+		}
+		m.queue = append(m.queue, i)
+	case 3:
+		m.allowEdge[deltaEdge] = true
+		m.queue = append(m.queue, m.edges[deltaEdge].I)
+	case 4:
+		m.expandBlossom(deltaBlossom, false)
+	}
+	return true
+}
+
+func (m *blossomMatcher) minVertexDual() float64 {
+	minDual := math.MaxFloat64
+	for v := 0; v < m.numVertices; v++ {
+		minDual = math.Min(minDual, m.dualVar[v])
+	}
+	return minDual
+}
+
+// result converts the matched endpoints into matched vertices.
+func (m *blossomMatcher) result() []int {
+	mate := make([]int, m.numVertices)
+	for v, p := range m.mate {
+		if p >= 0 {
+			mate[v] = m.endpoint[p]
+		} else {
+			mate[v] = -1
+		}
+	}
+	return mate
+}
+
+func (m *blossomMatcher) slack(k int) float64 {
+	e := m.edges[k]
+	return m.dualVar[e.I] + m.dualVar[e.J] - 2*e.Weight
+}
+
+// blossomLeaves returns the vertices contained in the supplied blossom, including those in nested blossoms.
+func (m *blossomMatcher) blossomLeaves(b int) []int {
+	if b < m.numVertices {
+		return []int{b}
+	}
+	leaves := []int{}
+	for _, t := range m.blossomChildren[b] {
+		leaves = append(leaves, m.blossomLeaves(t)...)
+	}
+	return leaves
+}
+
+// assignLabel labels vertex w (and its top-level blossom) with t, having been reached via endpoint p.
+func (m *blossomMatcher) assignLabel(w int, t int, p int) {
+	b := m.inBlossom[w]
+	m.label[w], m.label[b] = t, t
+	m.labelEnd[w], m.labelEnd[b] = p, p
+	m.bestEdge[w], m.bestEdge[b] = -1, -1
+	if t == 1 {
+		m.queue = append(m.queue, m.blossomLeaves(b)...)
+	} else if t == 2 {
+		base := m.blossomBase[b]
+		m.assignLabel(m.endpoint[m.mate[base]], 1, m.mate[base]^1)
+	}
+}
+
+// scanBlossom traces back from vertices v and w to find either a new blossom (returning its base) or an augmenting path (returning -1).
+func (m *blossomMatcher) scanBlossom(v int, w int) int {
+	path := []int{}
+	base := -1
+	for v != -1 || w != -1 {
+		b := m.inBlossom[v]
+		if m.label[b]&4 != 0 {
+			base = m.blossomBase[b]
+			break
+		}
+		path = append(path, b)
+		m.label[b] = 5
+		if m.labelEnd[b] == -1 {
+			// The root of the alternating tree has been reached.
+			v = -1
+		} else {
+			v = m.endpoint[m.labelEnd[b]]
+			b = m.inBlossom[v]
+			v = m.endpoint[m.labelEnd[b]]
+		}
+		if w != -1 {
+			v, w = w, v
+		}
+	}
+	for _, b := range path {
+		m.label[b] = 1
+	}
+	return base
+}
+
+// addBlossom creates a new blossom with the supplied base, containing edge k, which connects two S-vertices in the same alternating tree.
+func (m *blossomMatcher) addBlossom(base int, k int) {
+	v, w := m.edges[k].I, m.edges[k].J
+	bb := m.inBlossom[base]
+	bv := m.inBlossom[v]
+	bw := m.inBlossom[w]
+
+	b := m.unusedBlossoms[len(m.unusedBlossoms)-1]
+	m.unusedBlossoms = m.unusedBlossoms[:len(m.unusedBlossoms)-1]
+	m.blossomBase[b] = base
+	m.blossomParent[b] = -1
+	m.blossomParent[bb] = b
+
+	// Trace back from v to the base, then reverse, so that the children are in order around the blossom.
+	path := []int{}
+	endpoints := []int{}
+	for bv != bb {
+		m.blossomParent[bv] = b
+		path = append(path, bv)
+		endpoints = append(endpoints, m.labelEnd[bv])
+		v = m.endpoint[m.labelEnd[bv]]
+		bv = m.inBlossom[v]
+	}
+	path = append(path, bb)
+	reverseInts(path)
+	reverseInts(endpoints)
+	endpoints = append(endpoints, 2*k)
+	for bw != bb {
+		m.blossomParent[bw] = b
+		path = append(path, bw)
+		endpoints = append(endpoints, m.labelEnd[bw]^1)
+		w = m.endpoint[m.labelEnd[bw]]
+		bw = m.inBlossom[w]
+	}
+	m.blossomChildren[b] = path
+	m.blossomEndpoints[b] = endpoints
+
+	m.label[b] = 1
+	m.labelEnd[b] = m.labelEnd[bb]
+	m.dualVar[b] = 0
+	for _, leaf := range m.blossomLeaves(b) {
+		if m.label[m.inBlossom[leaf]] == 2 {
+			// Former T-vertices are now S-vertices, so they need to be scanned.
+			m.queue = append(m.queue, leaf)
+		}
+		m.inBlossom[leaf] = b
+	}
+
+	// Compute the least-slack edge from the new blossom to each neighboring S-blossom.
+	bestEdgeTo := make([]int, 2*m.numVertices)
+	for i := range bestEdgeTo {
+		bestEdgeTo[i] = -1
+	}
+	for _, child := range path {
+		var neighborLists [][]int
+		if m.blossomBestEdges[child] == nil {
+			for _, leaf := range m.blossomLeaves(child) {
+				list := make([]int, len(m.neighborEnds[leaf]))
+				for i, p := range m.neighborEnds[leaf] {
+					list[i] = p / 2
+				}
+				neighborLists = append(neighborLists, list)
+			}
+		} else {
+			neighborLists = [][]int{m.blossomBestEdges[child]}
+		}
+		for _, list := range neighborLists {
+			for _, edgeIndex := range list {
+				i, j := m.edges[edgeIndex].I, m.edges[edgeIndex].J
+				if m.inBlossom[j] == b {
+					i, j = j, i
+				}
+				if bj := m.inBlossom[j]; bj != b && m.label[bj] == 1 && (bestEdgeTo[bj] == -1 || m.slack(edgeIndex) < m.slack(bestEdgeTo[bj])) {
+					bestEdgeTo[bj] = edgeIndex
+				}
+			}
+		}
+		m.blossomBestEdges[child] = nil
+		m.bestEdge[child] = -1
+	}
+
+	bestEdges := []int{}
+	for _, edgeIndex := range bestEdgeTo {
+		if edgeIndex != -1 {
+			bestEdges = append(bestEdges, edgeIndex)
+		}
+	}
+	m.blossomBestEdges[b] = bestEdges
+	m.bestEdge[b] = -1
+	for _, edgeIndex := range bestEdges {
+		if m.bestEdge[b] == -1 || m.slack(edgeIndex) < m.slack(m.bestEdge[b]) {
+			m.bestEdge[b] = edgeIndex
+		}
+	}
+}
+
+// expandBlossom replaces blossom b with its children. If this is not the end of a stage and b is a T-blossom, its children are relabeled so the alternating tree remains valid.
+func (m *blossomMatcher) expandBlossom(b int, endStage bool) {
+	for _, s := range m.blossomChildren[b] {
+		m.blossomParent[s] = -1
+		if s < m.numVertices {
+			m.inBlossom[s] = s
+		} else if endStage && m.dualVar[s] <= model.Threshold {
+			m.expandBlossom(s, endStage)
+		} else {
+			for _, leaf := range m.blossomLeaves(s) {
+				m.inBlossom[leaf] = s
+			}
+		}
+	}
+
+	if !endStage && m.label[b] == 2 {
+		children := m.blossomChildren[b]
+		endpoints := m.blossomEndpoints[b]
+		entryChild := m.inBlossom[m.endpoint[m.labelEnd[b]^1]]
+		j := indexOfInt(children, entryChild)
+		jStep, endpointTrick := -1, 1
+		if j&1 != 0 {
+			// Go forward around the blossom, wrapping around via a negative index.
+			j -= len(children)
+			jStep, endpointTrick = 1, 0
+		}
+
+		// Relabel the T-sub-blossoms along the path from the entry child to the base.
+		p := m.labelEnd[b]
+		for j != 0 {
+			m.label[m.endpoint[p^1]] = 0
+			m.label[m.endpoint[endpoints[wrapIndex(j-endpointTrick, len(endpoints))]^endpointTrick^1]] = 0
+			m.assignLabel(m.endpoint[p^1], 2, p)
+			m.allowEdge[endpoints[wrapIndex(j-endpointTrick, len(endpoints))]/2] = true
+			j += jStep
+			p = endpoints[wrapIndex(j-endpointTrick, len(endpoints))] ^ endpointTrick
+			m.allowEdge[p/2] = true
+			j += jStep
+		}
+
+		bv := children[wrapIndex(j, len(children))]
+		m.label[m.endpoint[p^1]], m.label[bv] = 2, 2
+		m.labelEnd[m.endpoint[p^1]], m.labelEnd[bv] = p, p
+		m.bestEdge[bv] = -1
+		j += jStep
+
+		// Sub-blossoms that are not on the path, but were reached from outside the blossom, need to be labeled T.
+		for children[wrapIndex(j, len(children))] != entryChild {
+			bv = children[wrapIndex(j, len(children))]
+			if m.label[bv] == 1 {
+				j += jStep
+				continue
+			}
+			leaf := -1
+			for _, candidate := range m.blossomLeaves(bv) {
+				leaf = candidate
+				if m.label[candidate] != 0 {
+					break
+				}
+			}
+			if leaf >= 0 && m.label[leaf] != 0 {
+				m.label[leaf] = 0
+				m.label[m.endpoint[m.mate[m.blossomBase[bv]]]] = 0
+				m.assignLabel(leaf, 2, m.labelEnd[leaf])
+			}
+			j += jStep
+		}
+	}
+
+	m.label[b], m.labelEnd[b] = -1, -1
+	m.blossomChildren[b], m.blossomEndpoints[b] = nil, nil
+	m.blossomBase[b] = -1
+	m.blossomBestEdges[b] = nil
+	m.bestEdge[b] = -1
+	m.unusedBlossoms = append(m.unusedBlossoms, b)
+}
+
+// augmentBlossom swaps the matched and unmatched edges along the path from vertex v to the base of blossom b, so that v becomes the base.
+func (m *blossomMatcher) augmentBlossom(b int, v int) {
+	t := v
+	for m.blossomParent[t] != b {
+		t = m.blossomParent[t]
+	}
+	if t >= m.numVertices {
+		m.augmentBlossom(t, v)
+	}
+
+	children := m.blossomChildren[b]
+	endpoints := m.blossomEndpoints[b]
+	i := indexOfInt(children, t)
+	j := i
+	jStep, endpointTrick := -1, 1
+	if i&1 != 0 {
+		j -= len(children)
+		jStep, endpointTrick = 1, 0
+	}
+	for j != 0 {
+		j += jStep
+		t = children[wrapIndex(j, len(children))]
+		p := endpoints[wrapIndex(j-endpointTrick, len(endpoints))] ^ endpointTrick
+		if t >= m.numVertices {
+			m.augmentBlossom(t, m.endpoint[p])
+		}
+		j += jStep
+		t = children[wrapIndex(j, len(children))]
+		if t >= m.numVertices {
+			m.augmentBlossom(t, m.endpoint[p^1])
+		}
+		m.mate[m.endpoint[p]] = p ^ 1
+		m.mate[m.endpoint[p^1]] = p
+	}
+
+	// Rotate the children so that the new base is first.
+	m.blossomChildren[b] = append(append([]int{}, children[i:]...), children[:i]...)
+	m.blossomEndpoints[b] = append(append([]int{}, endpoints[i:]...), endpoints[:i]...)
+	m.blossomBase[b] = m.blossomBase[m.blossomChildren[b][0]]
+}
+
+// augmentMatching swaps the matched and unmatched edges along the augmenting path through edge k, which connects the roots of two alternating trees.
+func (m *blossomMatcher) augmentMatching(k int) {
+	for _, start := range [][2]int{{m.edges[k].I, 2*k + 1}, {m.edges[k].J, 2 * k}} {
+		s, p := start[0], start[1]
+		for {
+			bs := m.inBlossom[s]
+			if bs >= m.numVertices {
+				m.augmentBlossom(bs, s)
+			}
+			m.mate[s] = p
+			if m.labelEnd[bs] == -1 {
+				// The root of the alternating tree has been reached.
+				break
+			}
+			t := m.endpoint[m.labelEnd[bs]]
+			bt := m.inBlossom[t]
+			s = m.endpoint[m.labelEnd[bt]]
+			j := m.endpoint[m.labelEnd[bt]^1]
+			if bt >= m.numVertices {
+				m.augmentBlossom(bt, j)
+			}
+			m.mate[j] = m.labelEnd[bt]
+			p = m.labelEnd[bt] ^ 1
+		}
+	}
+}
+
+func indexOfInt(values []int, value int) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func reverseInts(values []int) {
+	for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+		values[i], values[j] = values[j], values[i]
+	}
+}
+
+// wrapIndex converts a possibly negative index into an index from the start of a slice, like a negative index in Python.
+func wrapIndex(index int, length int) int {
+	if index < 0 {
+		return index + length
+	}
+	return index
+}
@@ -0,0 +1,144 @@
+package matching_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/matching"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxWeightMatching(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]int{}, matching.MaxWeightMatching([]matching.Edge{}, false))
+	assert.Equal([]int{1, 0}, matching.MaxWeightMatching([]matching.Edge{{I: 0, J: 1, Weight: 1}}, false))
+	// The heavier edge is preferred over two lighter edges, unless the number of edges must be maximized.
+	edges := []matching.Edge{{I: 1, J: 2, Weight: 10}, {I: 2, J: 3, Weight: 11}}
+	assert.Equal([]int{-1, -1, 3, 2}, matching.MaxWeightMatching(edges, false))
+	edges = []matching.Edge{{I: 1, J: 2, Weight: 5}, {I: 2, J: 3, Weight: 11}, {I: 3, J: 4, Weight: 5}}
+	assert.Equal([]int{-1, -1, 3, 2, -1}, matching.MaxWeightMatching(edges, false))
+	assert.Equal([]int{-1, 2, 1, 4, 3}, matching.MaxWeightMatching(edges, true))
+	// Negative weights are never used, unless they are required for maximum cardinality.
+	negative := []matching.Edge{{I: 1, J: 2, Weight: 2}, {I: 1, J: 3, Weight: -2}, {I: 2, J: 3, Weight: 1}, {I: 2, J: 4, Weight: -1}, {I: 3, J: 4, Weight: -6}}
+	assert.Equal([]int{-1, 2, 1, -1, -1}, matching.MaxWeightMatching(negative, false))
+	assert.Equal([]int{-1, 3, 4, 1, 2}, matching.MaxWeightMatching(negative, true))
+}
+
+func TestMaxWeightMatching_Blossoms(t *testing.T) {
+	assert := assert.New(t)
+
+	// Create an S-blossom and use it for augmentation.
+	edges := []matching.Edge{{I: 1, J: 2, Weight: 8}, {I: 1, J: 3, Weight: 9}, {I: 2, J: 3, Weight: 10}, {I: 3, J: 4, Weight: 7}}
+	assert.Equal([]int{-1, 2, 1, 4, 3}, matching.MaxWeightMatching(edges, false))
+	edges = append(edges, matching.Edge{I: 1, J: 6, Weight: 5}, matching.Edge{I: 4, J: 5, Weight: 6})
+	assert.Equal([]int{-1, 6, 3, 2, 5, 4, 1}, matching.MaxWeightMatching(edges, false))
+
+	// Create an S-blossom, relabel it as a T-blossom, and use it for augmentation.
+	edges = []matching.Edge{{I: 1, J: 2, Weight: 9}, {I: 1, J: 3, Weight: 8}, {I: 2, J: 3, Weight: 10}, {I: 1, J: 4, Weight: 5}, {I: 4, J: 5, Weight: 4}, {I: 1, J: 6, Weight: 3}}
+	assert.Equal([]int{-1, 6, 3, 2, 5, 4, 1}, matching.MaxWeightMatching(edges, false))
+
+	// Create a nested S-blossom and use it for augmentation.
+	edges = []matching.Edge{{I: 1, J: 2, Weight: 9}, {I: 1, J: 3, Weight: 9}, {I: 2, J: 3, Weight: 10}, {I: 2, J: 4, Weight: 8}, {I: 3, J: 5, Weight: 8}, {I: 4, J: 5, Weight: 10}, {I: 5, J: 6, Weight: 6}}
+	assert.Equal([]int{-1, 3, 4, 1, 2, 6, 5}, matching.MaxWeightMatching(edges, false))
+
+	// Create a nested S-blossom, relabel it as T, and expand it.
+	edges = []matching.Edge{{I: 1, J: 2, Weight: 19}, {I: 1, J: 3, Weight: 20}, {I: 1, J: 8, Weight: 8}, {I: 2, J: 3, Weight: 25}, {I: 2, J: 4, Weight: 18}, {I: 3, J: 5, Weight: 18}, {I: 4, J: 5, Weight: 13}, {I: 4, J: 7, Weight: 7}, {I: 5, J: 6, Weight: 7}}
+	assert.Equal([]int{-1, 8, 3, 2, 7, 6, 5, 4, 1}, matching.MaxWeightMatching(edges, false))
+
+	// Create a blossom, relabel it as T in more than one way, then expand it and augment.
+	edges = []matching.Edge{{I: 1, J: 2, Weight: 45}, {I: 1, J: 5, Weight: 45}, {I: 2, J: 3, Weight: 50}, {I: 3, J: 4, Weight: 45}, {I: 4, J: 5, Weight: 50}, {I: 1, J: 6, Weight: 30}, {I: 3, J: 9, Weight: 35}, {I: 4, J: 8, Weight: 35}, {I: 5, J: 7, Weight: 26}, {I: 9, J: 10, Weight: 5}}
+	assert.Equal([]int{-1, 6, 3, 2, 8, 7, 1, 5, 4, 10, 9}, matching.MaxWeightMatching(edges, false))
+
+	// Create a nested blossom, relabel it as T, and expand the outer blossom such that the inner blossom ends up on an augmenting path.
+	edges = []matching.Edge{{I: 1, J: 2, Weight: 45}, {I: 1, J: 7, Weight: 45}, {I: 2, J: 3, Weight: 50}, {I: 3, J: 4, Weight: 45}, {I: 4, J: 5, Weight: 95}, {I: 4, J: 6, Weight: 94}, {I: 5, J: 6, Weight: 94}, {I: 6, J: 7, Weight: 50}, {I: 1, J: 8, Weight: 30}, {I: 3, J: 11, Weight: 35}, {I: 5, J: 9, Weight: 36}, {I: 7, J: 10, Weight: 26}, {I: 11, J: 12, Weight: 5}}
+	assert.Equal([]int{-1, 8, 3, 2, 6, 9, 4, 10, 1, 5, 7, 12, 11}, matching.MaxWeightMatching(edges, false))
+}
+
+func TestMinWeightPerfectMatching(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]int{}, matching.MinWeightPerfectMatching(0, nil))
+	assert.Equal([]int{-1}, matching.MinWeightPerfectMatching(1, nil))
+
+	random := rand.New(rand.NewSource(11))
+	for numVertices := 2; numVertices <= 10; numVertices += 2 {
+		for trial := 0; trial < 20; trial++ {
+			weights := make([][]float64, numVertices)
+			for i := range weights {
+				weights[i] = make([]float64, numVertices)
+				for j := 0; j < i; j++ {
+					weights[i][j] = random.Float64() * 100
+					weights[j][i] = weights[i][j]
+				}
+			}
+
+			mate := matching.MinWeightPerfectMatching(numVertices, func(i, j int) float64 { return weights[i][j] })
+			total := 0.0
+			for i, j := range mate {
+				assert.NotEqual(-1, j)
+				assert.Equal(i, mate[j])
+				total += weights[i][j] / 2
+			}
+			assert.InDelta(bruteForceMinMatching(weights, make([]bool, numVertices)), total, 1e-9)
+		}
+	}
+}
+
+func TestMinWeightPerfectMatching_ShouldTolerateRoundingErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	// Distances between points with large coordinates are not exactly representable, so the duals and slacks accumulate rounding errors.
+	random := rand.New(rand.NewSource(3))
+	for trial := 0; trial < 50; trial++ {
+		numVertices := 10
+		x := make([]float64, numVertices)
+		y := make([]float64, numVertices)
+		for i := range x {
+			x[i] = 1e7 + random.Float64()*1e3
+			y[i] = 1e7 + random.Float64()*1e3
+		}
+		weights := make([][]float64, numVertices)
+		for i := range weights {
+			weights[i] = make([]float64, numVertices)
+			for j := range weights[i] {
+				weights[i][j] = math.Hypot(x[i]-x[j], y[i]-y[j])
+			}
+		}
+
+		mate := matching.MinWeightPerfectMatching(numVertices, func(i, j int) float64 { return weights[i][j] })
+		total := 0.0
+		for i, j := range mate {
+			assert.NotEqual(-1, j)
+			assert.Equal(i, mate[j])
+			total += weights[i][j] / 2
+		}
+		assert.InDelta(bruteForceMinMatching(weights, make([]bool, numVertices)), total, 1e-6)
+	}
+}
+
+// bruteForceMinMatching computes the minimum-weight perfect matching by matching the first unmatched vertex with each other unmatched vertex.
+func bruteForceMinMatching(weights [][]float64, matched []bool) float64 {
+	first := -1
+	for i, isMatched := range matched {
+		if !isMatched {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return 0
+	}
+	best := math.MaxFloat64
+	matched[first] = true
+	for j := first + 1; j < len(matched); j++ {
+		if !matched[j] {
+			matched[j] = true
+			best = math.Min(best, weights[first][j]+bruteForceMinMatching(weights, matched))
+			matched[j] = false
+		}
+	}
+	matched[first] = false
+	return best
+}
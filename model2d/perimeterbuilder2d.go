@@ -1,6 +1,10 @@
 package model2d
 
-import "github.com/heustis/tsp-solver-go/model"
+import (
+	"sort"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
 
 // BuildPerimiter produces the smallest convex perimeter that can encompass all the vertices in the supplied array.
 // This returns both the edges comprising the convex perimeter and the set of unattached (interior) vertices.
@@ -108,4 +112,69 @@ func BuildPerimiter(verticesArg []model.CircuitVertex) (circuitEdges []model.Cir
 	return circuitEdges, unattachedVertices
 }
 
+// BuildPerimiterMonotoneChain produces the same convex perimeter as BuildPerimiter, using Andrew's monotone chain algorithm, which is O(n*log(n)) rather than O(n^2).
+// The perimeter is counter-clockwise, and starts from the vertex with the smallest X (and then smallest Y) coordinate.
+// Like BuildPerimiter, the perimeter includes vertices that lie on its edges, and the vertices that are not on the perimeter are returned as the unattached (interior) vertices.
+// This will panic if any of the vertices in the array are not of type Vertex2D.
+func BuildPerimiterMonotoneChain(verticesArg []model.CircuitVertex) (circuitEdges []model.CircuitEdge, unattachedVertices map[model.CircuitVertex]bool) {
+	sorted := make([]*Vertex2D, len(verticesArg))
+	unattachedVertices = make(map[model.CircuitVertex]bool, len(verticesArg))
+	for i, v := range verticesArg {
+		sorted[i] = v.(*Vertex2D)
+		unattachedVertices[v] = true
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].X < sorted[j].X || (sorted[i].X == sorted[j].X && sorted[i].Y < sorted[j].Y)
+	})
+
+	// Build the lower chain from left to right, then the upper chain from right to left.
+	// A vertex is removed from a chain if the next vertex is to the right of the edge leading to it (i.e. the chain would turn clockwise),
+	// so collinear vertices remain in the chain, consistent with BuildPerimiter.
+	chain := make([]*Vertex2D, 0, 2*len(sorted))
+	buildChain := func(vertices []*Vertex2D, minLen int) {
+		for _, v := range vertices {
+			for len(chain) >= minLen+2 && isClockwise(chain[len(chain)-2], chain[len(chain)-1], v) {
+				chain = chain[:len(chain)-1]
+			}
+			chain = append(chain, v)
+		}
+	}
+	buildChain(sorted, 0)
+	lowerLen := len(chain)
+	reversed := make([]*Vertex2D, len(sorted))
+	for i, v := range sorted {
+		reversed[len(sorted)-1-i] = v
+	}
+	// The rightmost vertex is already the last vertex of the lower chain.
+	if len(reversed) > 1 {
+		buildChain(reversed[1:], lowerLen-1)
+	}
+
+	// The first vertex is repeated at the end of the upper chain, and if every vertex is collinear the upper chain retraces the lower chain,
+	// so only the first occurrence of each vertex is kept.
+	perimeter := make([]*Vertex2D, 0, len(chain))
+	isOnPerimeter := make(map[*Vertex2D]bool, len(chain))
+	for _, v := range chain {
+		if !isOnPerimeter[v] {
+			isOnPerimeter[v] = true
+			perimeter = append(perimeter, v)
+		}
+	}
+
+	circuitEdges = make([]model.CircuitEdge, len(perimeter))
+	for i, v := range perimeter {
+		delete(unattachedVertices, v)
+		circuitEdges[i] = v.EdgeTo(perimeter[(i+1)%len(perimeter)])
+	}
+	return circuitEdges, unattachedVertices
+}
+
+// isClockwise returns true if c is to the right of the edge from a to b, by more than model.Threshold.
+// The cross product is compared to the threshold scaled by the length of the edge, to avoid dividing by a zero-length edge.
+func isClockwise(a *Vertex2D, b *Vertex2D, c *Vertex2D) bool {
+	cross := (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+	return cross < -model.Threshold*a.DistanceTo(b)
+}
+
 var _ model.PerimeterBuilder = BuildPerimiter
+var _ model.PerimeterBuilder = BuildPerimiterMonotoneChain
@@ -40,3 +40,84 @@ func TestBuildPerimeter(t *testing.T) {
 	assert.True(unattachedVertices[vertices[3]])
 	assert.True(unattachedVertices[vertices[5]])
 }
+
+func TestBuildPerimeterMonotoneChain(t *testing.T) {
+	assert := assert.New(t)
+	vertices := model2d.DeduplicateVertices([]model.CircuitVertex{
+		model2d.NewVertex2D(-15, -15),
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(15, -15),
+		model2d.NewVertex2D(3, 0),
+		model2d.NewVertex2D(3, 13),
+		model2d.NewVertex2D(8, 5),
+		model2d.NewVertex2D(9, 6),
+		model2d.NewVertex2D(-7, 6),
+	})
+
+	circuitEdges, unattachedVertices := model2d.BuildPerimiterMonotoneChain(vertices)
+	expectedEdges, expectedUnattached := model2d.BuildPerimiter(vertices)
+	assert.Len(circuitEdges, 5)
+	for i, e := range expectedEdges {
+		assert.True(e.Equals(circuitEdges[i]), i)
+	}
+	assert.Equal(expectedUnattached, unattachedVertices)
+}
+
+func TestBuildPerimeterMonotoneChain_ShouldIncludeCollinearVertices(t *testing.T) {
+	assert := assert.New(t)
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(5, 5),
+		model2d.NewVertex2D(5, 0),
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(5, 2),
+	}
+
+	circuitEdges, unattachedVertices := model2d.BuildPerimiterMonotoneChain(vertices)
+	assert.Len(circuitEdges, 4)
+	assert.True(vertices[3].EdgeTo(vertices[2]).Equals(circuitEdges[0]))
+	assert.True(vertices[2].EdgeTo(vertices[0]).Equals(circuitEdges[1]))
+	assert.True(vertices[0].EdgeTo(vertices[1]).Equals(circuitEdges[2]))
+	assert.True(vertices[1].EdgeTo(vertices[3]).Equals(circuitEdges[3]))
+	assert.Equal(map[model.CircuitVertex]bool{vertices[4]: true}, unattachedVertices)
+
+	// If every vertex is collinear, the perimeter goes from one end of the line to the other, and back.
+	line := []model.CircuitVertex{
+		model2d.NewVertex2D(2, 2),
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(1, 1),
+	}
+	circuitEdges, unattachedVertices = model2d.BuildPerimiterMonotoneChain(line)
+	expectedEdges, _ := model2d.BuildPerimiter(model2d.DeduplicateVertices(line))
+	assert.Len(circuitEdges, 3)
+	for i, e := range expectedEdges {
+		assert.True(e.Equals(circuitEdges[i]), i)
+	}
+	assert.Len(unattachedVertices, 0)
+
+	pair := []model.CircuitVertex{model2d.NewVertex2D(2, 2), model2d.NewVertex2D(0, 0)}
+	circuitEdges, unattachedVertices = model2d.BuildPerimiterMonotoneChain(pair)
+	assert.Len(circuitEdges, 2)
+	assert.True(pair[1].EdgeTo(pair[0]).Equals(circuitEdges[0]))
+	assert.True(pair[0].EdgeTo(pair[1]).Equals(circuitEdges[1]))
+	assert.Len(unattachedVertices, 0)
+}
+
+func TestBuildPerimeterMonotoneChain_ShouldMatchBuildPerimeter(t *testing.T) {
+	assert := assert.New(t)
+
+	for i := 0; i < 20; i++ {
+		vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(200))
+		circuitEdges, unattachedVertices := model2d.BuildPerimiterMonotoneChain(vertices)
+		expectedEdges, expectedUnattached := model2d.BuildPerimiter(vertices)
+
+		// BuildPerimiter starts from the vertex farthest from the midpoint, so compare the edges starting from the same vertex.
+		assert.Len(circuitEdges, len(expectedEdges))
+		offset := model.IndexOfEdge(circuitEdges, expectedEdges[0])
+		assert.GreaterOrEqual(offset, 0)
+		for j, e := range expectedEdges {
+			assert.True(e.Equals(circuitEdges[(j+offset)%len(circuitEdges)]))
+		}
+		assert.Equal(expectedUnattached, unattachedVertices)
+	}
+}
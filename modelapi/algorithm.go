@@ -1,21 +1,71 @@
 package modelapi
 
 import (
+	"fmt"
 	"math"
+	"time"
 
 	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
 	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
 )
 
 type AlgorithmType string
 
 const (
-	ALG_ANNEALING        AlgorithmType = "ANNEALING"
-	ALG_CLOSEST_CLONE    AlgorithmType = "CLOSEST_CLONE"
-	ALG_CLOSEST_GREEDY   AlgorithmType = "CLOSEST_GREEDY"
-	ALG_DISPARITY_CLONE  AlgorithmType = "DISPARITY_CLONE"
-	ALG_DISPARITY_GREEDY AlgorithmType = "DISPARITY_GREEDY"
-	ALG_GENETIC          AlgorithmType = "GENETIC"
+	ALG_ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH AlgorithmType = "ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH"
+	ALG_ANNEALING                          AlgorithmType = "ANNEALING"
+	ALG_AUTO                               AlgorithmType = "AUTO"
+	ALG_CHEAPEST_INSERTION                 AlgorithmType = "CHEAPEST_INSERTION"
+	ALG_CHRISTOFIDES                       AlgorithmType = "CHRISTOFIDES"
+	ALG_CLOSEST_CLONE                      AlgorithmType = "CLOSEST_CLONE"
+	ALG_CLOSEST_GREEDY                     AlgorithmType = "CLOSEST_GREEDY"
+	ALG_DISPARITY_CLONE                    AlgorithmType = "DISPARITY_CLONE"
+	ALG_DISPARITY_GREEDY                   AlgorithmType = "DISPARITY_GREEDY"
+	ALG_DOUBLE_TREE                        AlgorithmType = "DOUBLE_TREE"
+	ALG_FARTHEST_INSERTION                 AlgorithmType = "FARTHEST_INSERTION"
+	ALG_GENETIC                            AlgorithmType = "GENETIC"
+	ALG_GREEDY_EDGE                        AlgorithmType = "GREEDY_EDGE"
+	ALG_GUIDED_LOCAL_SEARCH                AlgorithmType = "GUIDED_LOCAL_SEARCH"
+	ALG_HILBERT_CURVE                      AlgorithmType = "HILBERT_CURVE"
+	ALG_ITERATED_LOCAL_SEARCH              AlgorithmType = "ITERATED_LOCAL_SEARCH"
+	ALG_LIN_KERNIGHAN                      AlgorithmType = "LIN_KERNIGHAN"
+	ALG_MORTON_CURVE                       AlgorithmType = "MORTON_CURVE"
+	ALG_NEAREST_INSERTION                  AlgorithmType = "NEAREST_INSERTION"
+	ALG_NEAREST_NEIGHBOR                   AlgorithmType = "NEAREST_NEIGHBOR"
+	ALG_OR_OPT                             AlgorithmType = "OR_OPT"
+	ALG_PIPELINE                           AlgorithmType = "PIPELINE"
+	ALG_RANDOM_INSERTION                   AlgorithmType = "RANDOM_INSERTION"
+	ALG_SAVINGS                            AlgorithmType = "SAVINGS"
+	ALG_TABU_SEARCH                        AlgorithmType = "TABU_SEARCH"
+	ALG_TWO_OPT                            AlgorithmType = "TWO_OPT"
+)
+
+type AcceptanceType string
+
+const (
+	ACCEPTANCE_DEFAULT     AcceptanceType = ""
+	ACCEPTANCE_ANNEALING   AcceptanceType = "ANNEALING"
+	ACCEPTANCE_BETTER      AcceptanceType = "BETTER"
+	ACCEPTANCE_RANDOM_WALK AcceptanceType = "RANDOM_WALK"
+)
+
+type PerimeterBuilderType string
+
+const (
+	PERIMETER_DEFAULT        PerimeterBuilderType = ""
+	PERIMETER_FARTHEST_POINT PerimeterBuilderType = "FARTHEST_POINT"
+	PERIMETER_MONOTONE_CHAIN PerimeterBuilderType = "MONOTONE_CHAIN"
+)
+
+type PerturbationType string
+
+const (
+	PERTURBATION_DEFAULT          PerturbationType = ""
+	PERTURBATION_DOUBLE_BRIDGE    PerturbationType = "DOUBLE_BRIDGE"
+	PERTURBATION_SEGMENT_REVERSAL PerturbationType = "SEGMENT_REVERSAL"
 )
 
 type TemperatureFunctionType string
@@ -27,43 +77,120 @@ const (
 )
 
 // Algorithm represents a union of the possible configuration data used by different types of circuits, so that the API can appear to be polymorphic.
+// Any algorithm can start from the circuit produced by its PrecursorAlgorithm, and a PIPELINE computes its Stages in order, with each stage starting from the circuit produced by the stage before it.
+// An AUTO algorithm is replaced, via Resolve, with a configuration selected from the request's points and time limit.
+// The convex-concave algorithms build their initial perimeter with the PerimeterBuilder, which for 2D points can be the O(n*log(n)) MONOTONE_CHAIN rather than the default FARTHEST_POINT builder.
+// A SAVINGS algorithm's HubIndex is the index of its hub in the request's points, which Resolve converts into a vertex, since the circuit functions only receive the deduplicated vertices.
 type Algorithm struct {
-	AlgorithmType         AlgorithmType           `json:"algorithmType" validate:"required,oneof=ANNEALING CLOSEST_CLONE CLOSEST_GREEDY DISPARITY_CLONE DISPARITY_GREEDY GENETIC"`
+	Acceptance            AcceptanceType          `json:"acceptance,omitempty" validate:"omitempty,oneof=ANNEALING BETTER RANDOM_WALK"`
+	AlgorithmType         AlgorithmType           `json:"algorithmType" validate:"required,oneof=ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH ANNEALING AUTO CHEAPEST_INSERTION CHRISTOFIDES CLOSEST_CLONE CLOSEST_GREEDY DISPARITY_CLONE DISPARITY_GREEDY DOUBLE_TREE FARTHEST_INSERTION GENETIC GREEDY_EDGE GUIDED_LOCAL_SEARCH HILBERT_CURVE ITERATED_LOCAL_SEARCH LIN_KERNIGHAN MORTON_CURVE NEAREST_INSERTION NEAREST_NEIGHBOR OR_OPT PIPELINE RANDOM_INSERTION SAVINGS TABU_SEARCH TWO_OPT"`
 	CloneByInitEdges      *bool                   `json:"cloneByInitEdges,omitempty"`
 	CloneOnFirstAttach    *bool                   `json:"cloneOnFirstAttach,omitempty"`
+	FrequencyWeight       *float64                `json:"frequencyWeight,omitempty" validate:"omitempty,min=0"`
+	HubIndex              *int                    `json:"hubIndex,omitempty" validate:"omitempty,min=0"`
+	Improver              *Algorithm              `json:"improver,omitempty" validate:"omitempty,dive"`
 	MaxClones             *int64                  `json:"maxClones,omitempty"`
 	MaxCrossovers         int                     `json:"maxCrossovers,omitempty" validate:"isdefault|min=1"`
-	MaxIterations         int                     `json:"maxIterations,omitempty" validate:"isdefault|min=1,required_if=AlgorithmType GENETIC,required_if=AlgorithmType ANNEALING"`
+	MaxIterations         int                     `json:"maxIterations,omitempty" validate:"isdefault|min=1,required_if=AlgorithmType ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH,required_if=AlgorithmType GENETIC,required_if=AlgorithmType ANNEALING,required_if=AlgorithmType GUIDED_LOCAL_SEARCH,required_if=AlgorithmType ITERATED_LOCAL_SEARCH,required_if=AlgorithmType TABU_SEARCH"`
+	MaxRemoved            int                     `json:"maxRemoved,omitempty" validate:"isdefault|min=2"`
+	MaxSegmentLength      int                     `json:"maxSegmentLength,omitempty" validate:"isdefault|min=1"`
+	MaxStagnation         int                     `json:"maxStagnation,omitempty" validate:"isdefault|min=1"`
+	MaxTrials             *int                    `json:"maxTrials,omitempty" validate:"omitempty,min=0"`
 	MinSignificance       *float64                `json:"minSignificance,omitempty" validate:"omitempty,min=0"`
 	MutationRate          *float64                `json:"mutationRate,omitempty" validate:"omitempty,min=0,max=1"`
 	NumChildren           int                     `json:"numChildren,omitempty" validate:"isdefault|min=1,required_if=AlgorithmType GENETIC"`
+	NumNeighbors          int                     `json:"numNeighbors,omitempty" validate:"isdefault|min=1"`
 	NumParents            int                     `json:"numParents,omitempty" validate:"isdefault|min=1,required_if=AlgorithmType GENETIC"`
+	PenaltyFactor         *float64                `json:"penaltyFactor,omitempty" validate:"omitempty,gt=0"`
+	PerimeterBuilder      PerimeterBuilderType    `json:"perimeterBuilder,omitempty" validate:"omitempty,oneof=FARTHEST_POINT MONOTONE_CHAIN"`
+	Perturbation          PerturbationType        `json:"perturbation,omitempty" validate:"omitempty,oneof=DOUBLE_BRIDGE SEGMENT_REVERSAL"`
 	PrecursorAlgorithm    *Algorithm              `json:"precursorAlgorithm,omitempty" validate:"omitempty,dive"`
 	PreferCloseNeighbors  *bool                   `json:"preferCloseNeighbors,omitempty"`
 	Seed                  *int64                  `json:"seed,omitempty"`
 	ShouldBuildConvexHull *bool                   `json:"shouldBuildConvexHull,omitempty"`
+	Stages                []*Algorithm            `json:"stages,omitempty" validate:"required_if=AlgorithmType PIPELINE,omitempty,min=1,dive,required"`
 	TemperatureFunction   TemperatureFunctionType `json:"temperatureFunction,omitempty" validate:"omitempty,oneof=GEOMETRIC LINEAR"`
+	Tenure                int                     `json:"tenure,omitempty" validate:"isdefault|min=1"`
+	TimeLimitMillis       int64                   `json:"timeLimitMillis,omitempty" validate:"min=0"`
 	UpdateInteriorPoints  *bool                   `json:"updateInteriorPoints,omitempty"`
 	UseRelativeDisparity  *bool                   `json:"useRelativeDisparity,omitempty"`
+	hub                   model.CircuitVertex
 }
 
+// GetCircuitFunction returns the function that creates this algorithm's circuit.
+// If the algorithm is a PIPELINE, or has a precursor algorithm, the function creates a circuit.Pipeline so that each stage is computed in turn and its length is reported.
+// AUTO algorithms should be resolved prior to calling this, since without a request they behave like CLOSEST_GREEDY.
+// If the algorithm selects a PerimeterBuilder, the function replaces the supplied perimeter builder with it, for 2D vertices.
 func (alg *Algorithm) GetCircuitFunction() func(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	if alg.AlgorithmType == ALG_PIPELINE || alg.PrecursorAlgorithm != nil {
+		return alg.CreatePipeline
+	}
+	if alg.PerimeterBuilder == PERIMETER_MONOTONE_CHAIN {
+		return func(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+			return alg.getStageFunction()(vertices, alg.getPerimeterBuilder(vertices, perimeterBuilder))
+		}
+	}
+	return alg.getStageFunction()
+}
+
+// getStageFunction returns the function that creates this algorithm's circuit, ignoring its precursor and stages.
+func (alg *Algorithm) getStageFunction() func(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
 	switch alg.AlgorithmType {
+	case ALG_ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH:
+		return alg.CreateAdaptiveLargeNeighborhoodSearch
 	case ALG_ANNEALING:
 		return alg.CreateSimulatedAnnealing
+	case ALG_CHEAPEST_INSERTION, ALG_FARTHEST_INSERTION, ALG_NEAREST_INSERTION, ALG_RANDOM_INSERTION:
+		return alg.CreateInsertion
+	case ALG_CHRISTOFIDES:
+		return alg.CreateChristofides
 	case ALG_CLOSEST_CLONE:
 		return alg.CreateClosestClone
 	case ALG_DISPARITY_CLONE:
 		return alg.CreateDisparityClone
 	case ALG_DISPARITY_GREEDY:
 		return alg.CreateDisparityGreedy
+	case ALG_DOUBLE_TREE:
+		return alg.CreateDoubleTree
 	case ALG_GENETIC:
 		return alg.CreateGenetic
+	case ALG_GREEDY_EDGE:
+		return alg.CreateGreedyEdge
+	case ALG_GUIDED_LOCAL_SEARCH:
+		return alg.CreateGuidedLocalSearch
+	case ALG_HILBERT_CURVE, ALG_MORTON_CURVE:
+		return alg.CreateSpaceFillingCurve
+	case ALG_ITERATED_LOCAL_SEARCH:
+		return alg.CreateIteratedLocalSearch
+	case ALG_LIN_KERNIGHAN:
+		return alg.CreateLinKernighan
+	case ALG_NEAREST_NEIGHBOR:
+		return alg.CreateNearestNeighbor
+	case ALG_OR_OPT:
+		return alg.CreateOrOpt
+	case ALG_SAVINGS:
+		return alg.CreateSavings
+	case ALG_TABU_SEARCH:
+		return alg.CreateTabuSearch
+	case ALG_TWO_OPT:
+		return alg.CreateTwoOpt
 	default:
 		return alg.CreateClosestGreedy
 	}
 }
 
+// CreateAdaptiveLargeNeighborhoodSearch creates a circuit.AdaptiveLargeNeighborhoodSearch that improves the supplied points in the order they are supplied, for MaxIterations iterations, which does not use the perimeter builder.
+// Like TwoOpt, AdaptiveLargeNeighborhoodSearch is intended to improve the circuit from a preceding stage. If MaxRemoved is set, up to that many points are removed and reinserted in each iteration.
+// The default Acceptance is ANNEALING, rather than BETTER as in IteratedLocalSearch.
+func (alg *Algorithm) CreateAdaptiveLargeNeighborhoodSearch(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	return alg.configureAdaptiveLargeNeighborhoodSearch(circuit.NewAdaptiveLargeNeighborhoodSearch(vertices, alg.MaxIterations))
+}
+
+// CreateChristofides creates a circuit.Christofides, which does not use the perimeter builder.
+func (alg *Algorithm) CreateChristofides(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	return circuit.NewChristofides(vertices)
+}
+
 func (alg *Algorithm) CreateClosestClone(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
 	c := circuit.NewClosestClonable(vertices, perimeterBuilder)
 	c.SetCloneOnFirstAttach(isTrue(alg.CloneOnFirstAttach))
@@ -101,6 +228,11 @@ func (alg *Algorithm) CreateDisparityGreedy(vertices []model.CircuitVertex, peri
 	return circuit.NewDisparityGreedy(vertices, perimeterBuilder, isTrue(alg.UseRelativeDisparity))
 }
 
+// CreateDoubleTree creates a circuit.DoubleTree, which does not use the perimeter builder.
+func (alg *Algorithm) CreateDoubleTree(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	return circuit.NewDoubleTree(vertices)
+}
+
 func (alg *Algorithm) CreateGenetic(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
 	var c *circuit.GeneticAlgorithm
 	if isTrue(alg.ShouldBuildConvexHull) {
@@ -108,6 +240,203 @@ func (alg *Algorithm) CreateGenetic(vertices []model.CircuitVertex, perimeterBui
 	} else {
 		c = circuit.NewGeneticAlgorithm(vertices, alg.NumParents, alg.NumChildren, alg.MaxIterations)
 	}
+	return alg.configureGenetic(c)
+}
+
+// CreateGreedyEdge creates a circuit.GreedyEdge, which does not use the perimeter builder.
+// If NumNeighbors is set, only that many nearest neighbors of each 2D or 3D point are candidate edges, otherwise the circuit selects its candidates based on the number of points.
+func (alg *Algorithm) CreateGreedyEdge(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	if alg.NumNeighbors > 0 {
+		return circuit.NewGreedyEdgeWithNeighbors(vertices, alg.NumNeighbors)
+	}
+	return circuit.NewGreedyEdge(vertices)
+}
+
+// CreateInsertion creates a circuit.Insertion of the type corresponding to this algorithm's type, which does not use the perimeter builder.
+func (alg *Algorithm) CreateInsertion(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	var insertionType circuit.InsertionType
+	switch alg.AlgorithmType {
+	case ALG_FARTHEST_INSERTION:
+		insertionType = circuit.InsertionFarthest
+	case ALG_NEAREST_INSERTION:
+		insertionType = circuit.InsertionNearest
+	case ALG_RANDOM_INSERTION:
+		insertionType = circuit.InsertionRandom
+	default:
+		insertionType = circuit.InsertionCheapest
+	}
+	c := circuit.NewInsertion(vertices, insertionType)
+	if alg.Seed != nil {
+		c.SetSeed(*alg.Seed)
+	}
+	return c
+}
+
+// CreateSpaceFillingCurve creates a circuit.SpaceFillingCurve of the type corresponding to this algorithm's type, which does not use the perimeter builder.
+func (alg *Algorithm) CreateSpaceFillingCurve(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	if alg.AlgorithmType == ALG_MORTON_CURVE {
+		return circuit.NewMortonCurve(vertices)
+	}
+	return circuit.NewHilbertCurve(vertices)
+}
+
+// CreateGuidedLocalSearch creates a circuit.GuidedLocalSearch that improves the supplied points in the order they are supplied, for MaxIterations penalty iterations, which does not use the perimeter builder.
+// Like TwoOpt, GuidedLocalSearch is intended to improve the circuit from a preceding stage. If PenaltyFactor is not set, circuit.GuidedLocalSearchDefaultPenaltyFactor is used.
+func (alg *Algorithm) CreateGuidedLocalSearch(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	return alg.configureGuidedLocalSearch(circuit.NewGuidedLocalSearch(vertices, alg.getNumLocalSearchNeighbors(), alg.MaxIterations))
+}
+
+// CreateIteratedLocalSearch creates a circuit.IteratedLocalSearch that improves the supplied points in the order they are supplied, for up to MaxIterations iterations, which does not use the perimeter builder.
+// Like TwoOpt, IteratedLocalSearch is intended to improve the circuit from a preceding stage. The search also stops once MaxStagnation or TimeLimitMillis is reached, if they are set.
+// If Improver is set, each perturbed circuit is improved by that algorithm (typically ANNEALING), rather than by 2-opt and Or-opt.
+func (alg *Algorithm) CreateIteratedLocalSearch(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	return alg.configureIteratedLocalSearch(circuit.NewIteratedLocalSearch(vertices, alg.getNumLocalSearchNeighbors(), alg.MaxIterations))
+}
+
+// CreateLinKernighan creates a circuit.LinKernighan that improves the supplied points in the order they are supplied, which does not use the perimeter builder.
+// Like TwoOpt, LinKernighan is intended to improve the circuit from a preceding stage, such as GreedyEdge.
+// If MaxTrials is set, that many double-bridge kicks are applied once no move improves the circuit, otherwise one kick per point is applied.
+func (alg *Algorithm) CreateLinKernighan(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	return alg.configureLinKernighan(circuit.NewLinKernighan(vertices, alg.getNumLocalSearchNeighbors(), alg.getMaxTrials()))
+}
+
+// CreateNearestNeighbor creates a circuit.NearestNeighbor, which does not use the perimeter builder.
+func (alg *Algorithm) CreateNearestNeighbor(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	return circuit.NewNearestNeighbor(vertices)
+}
+
+// CreateOrOpt creates a circuit.OrOpt that improves the supplied points in the order they are supplied, which does not use the perimeter builder.
+// Like TwoOpt, OrOpt is intended to improve the circuit from a preceding stage, such as a convex-concave algorithm.
+// If MaxSegmentLength is set, segments of up to that many points are relocated, otherwise circuit.OrOptDefaultMaxSegmentLength is used.
+func (alg *Algorithm) CreateOrOpt(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	return circuit.NewOrOpt(vertices, alg.getNumLocalSearchNeighbors(), alg.MaxSegmentLength)
+}
+
+// CreatePipeline creates a circuit.Pipeline from this algorithm's stages, including its precursor algorithm, if it has one.
+// The first stage is created from the supplied vertices and perimeter builder, and each later stage is created from the circuit produced by the stage before it.
+func (alg *Algorithm) CreatePipeline(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	stages := alg.getStages()
+	first := stages[0].getStageFunction()(vertices, stages[0].getPerimeterBuilder(vertices, perimeterBuilder))
+	nextStages := make([]circuit.PipelineStage, len(stages)-1)
+	for i, stage := range stages[1:] {
+		nextStages[i] = stage.getPipelineStage(vertices)
+	}
+	return circuit.NewPipeline(first, nextStages...)
+}
+
+// CreateSavings creates a circuit.Savings, which does not use the perimeter builder.
+// Its hub is the point at HubIndex, if this algorithm was resolved with a request containing that point, otherwise the circuit selects its default hub.
+// If NumNeighbors is set, only that many nearest neighbors of each 2D or 3D point are candidates, otherwise the circuit selects its candidates based on the number of points.
+func (alg *Algorithm) CreateSavings(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	if alg.NumNeighbors > 0 {
+		return circuit.NewSavingsWithNeighbors(vertices, alg.hub, alg.NumNeighbors)
+	}
+	return circuit.NewSavings(vertices, alg.hub)
+}
+
+// CreateTabuSearch creates a circuit.TabuSearch that improves the supplied points in the order they are supplied, for MaxIterations iterations, which does not use the perimeter builder.
+// Like TwoOpt, TabuSearch is intended to improve the circuit from a preceding stage. If Tenure or FrequencyWeight are not set, the circuit's defaults are used.
+func (alg *Algorithm) CreateTabuSearch(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	return alg.configureTabuSearch(circuit.NewTabuSearch(vertices, alg.getNumLocalSearchNeighbors(), alg.MaxIterations, alg.Tenure))
+}
+
+// CreateTwoOpt creates a circuit.TwoOpt that improves the supplied points in the order they are supplied, which does not use the perimeter builder.
+// TwoOpt is intended to improve the circuit from a preceding stage, so it should usually be used in a pipeline or with a precursor algorithm.
+// If NumNeighbors is set, that many candidate neighbors of each point are considered for each move, otherwise circuit.LocalSearchDefaultNeighbors are considered.
+func (alg *Algorithm) CreateTwoOpt(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	return circuit.NewTwoOpt(vertices, alg.getNumLocalSearchNeighbors())
+}
+
+// getMaxTrials returns the number of trials for LinKernighan, or -1 if MaxTrials is not set, so that it uses one trial per point.
+func (alg *Algorithm) getMaxTrials() int {
+	if alg.MaxTrials != nil {
+		return *alg.MaxTrials
+	}
+	return -1
+}
+
+// getNumLocalSearchNeighbors returns the number of nearest neighbors that the local search algorithms consider for each move.
+func (alg *Algorithm) getNumLocalSearchNeighbors() int {
+	if alg.NumNeighbors > 0 {
+		return alg.NumNeighbors
+	}
+	return circuit.LocalSearchDefaultNeighbors
+}
+
+// getStages flattens this algorithm into the ordered list of algorithms that it consists of, expanding precursor algorithms and nested pipelines.
+func (alg *Algorithm) getStages() []*Algorithm {
+	stages := []*Algorithm{}
+	if alg.PrecursorAlgorithm != nil {
+		stages = append(stages, alg.PrecursorAlgorithm.getStages()...)
+	}
+	if alg.AlgorithmType == ALG_PIPELINE {
+		for _, stage := range alg.Stages {
+			stages = append(stages, stage.getStages()...)
+		}
+	} else {
+		stages = append(stages, alg)
+	}
+	return stages
+}
+
+// getPipelineStage returns a function that creates this algorithm's circuit from the circuit of a preceding stage.
+// The stochastic and local search algorithms start from the preceding circuit, while the convex-concave algorithms use the preceding circuit as their perimeter, so they only attach vertices that it is missing.
+// Christofides, DoubleTree, GreedyEdge, NearestNeighbor and the insertion algorithms do not use a perimeter, so they discard the preceding circuit; they are intended to be the first stage.
+func (alg *Algorithm) getPipelineStage(vertices []model.CircuitVertex) circuit.PipelineStage {
+	return func(precursor model.Circuit) model.Circuit {
+		switch alg.AlgorithmType {
+		case ALG_ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH:
+			return alg.configureAdaptiveLargeNeighborhoodSearch(circuit.NewAdaptiveLargeNeighborhoodSearchFromCircuit(precursor, alg.MaxIterations))
+		case ALG_ANNEALING:
+			return alg.configureSimulatedAnnealing(circuit.NewSimulatedAnnealingFromCircuit(precursor, alg.MaxIterations, isTrue(alg.PreferCloseNeighbors)))
+		case ALG_GENETIC:
+			return alg.configureGenetic(circuit.NewGeneticAlgorithmFromCircuit(precursor, alg.NumParents, alg.NumChildren, alg.MaxIterations))
+		case ALG_GUIDED_LOCAL_SEARCH:
+			return alg.configureGuidedLocalSearch(circuit.NewGuidedLocalSearchFromCircuit(precursor, alg.getNumLocalSearchNeighbors(), alg.MaxIterations))
+		case ALG_ITERATED_LOCAL_SEARCH:
+			return alg.configureIteratedLocalSearch(circuit.NewIteratedLocalSearchFromCircuit(precursor, alg.getNumLocalSearchNeighbors(), alg.MaxIterations))
+		case ALG_LIN_KERNIGHAN:
+			return alg.configureLinKernighan(circuit.NewLinKernighanFromCircuit(precursor, alg.getNumLocalSearchNeighbors(), alg.getMaxTrials()))
+		case ALG_OR_OPT:
+			return circuit.NewOrOptFromCircuit(precursor, alg.getNumLocalSearchNeighbors(), alg.MaxSegmentLength)
+		case ALG_TABU_SEARCH:
+			return alg.configureTabuSearch(circuit.NewTabuSearchFromCircuit(precursor, alg.getNumLocalSearchNeighbors(), alg.MaxIterations, alg.Tenure))
+		case ALG_TWO_OPT:
+			return circuit.NewTwoOptFromCircuit(precursor, alg.getNumLocalSearchNeighbors())
+		default:
+			return alg.getStageFunction()(vertices, circuit.BuildPerimeterFromCircuit(precursor))
+		}
+	}
+}
+
+// getPerimeterBuilder returns the perimeter builder selected by this algorithm, if it supports the supplied vertices, otherwise it returns the supplied perimeter builder.
+// This is only used for the first stage of a circuit, since later stages use the preceding stage's circuit as their perimeter.
+func (alg *Algorithm) getPerimeterBuilder(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.PerimeterBuilder {
+	if alg.PerimeterBuilder == PERIMETER_MONOTONE_CHAIN && len(vertices) > 0 {
+		if _, is2D := vertices[0].(*model2d.Vertex2D); is2D {
+			return model2d.BuildPerimiterMonotoneChain
+		}
+	}
+	return perimeterBuilder
+}
+
+func (alg *Algorithm) configureAdaptiveLargeNeighborhoodSearch(c *circuit.AdaptiveLargeNeighborhoodSearch) *circuit.AdaptiveLargeNeighborhoodSearch {
+	switch alg.Acceptance {
+	case ACCEPTANCE_BETTER:
+		c.SetAcceptance(circuit.AcceptanceBetter)
+	case ACCEPTANCE_RANDOM_WALK:
+		c.SetAcceptance(circuit.AcceptanceRandomWalk)
+	}
+	if alg.MaxRemoved > 0 {
+		c.SetMaxRemoved(alg.MaxRemoved)
+	}
+	if alg.Seed != nil {
+		c.SetSeed(*alg.Seed)
+	}
+	return c
+}
+
+func (alg *Algorithm) configureGenetic(c *circuit.GeneticAlgorithm) *circuit.GeneticAlgorithm {
 	if alg.MaxCrossovers > 0 {
 		c.SetMaxCrossovers(alg.MaxCrossovers)
 	}
@@ -120,14 +449,67 @@ func (alg *Algorithm) CreateGenetic(vertices []model.CircuitVertex, perimeterBui
 	return c
 }
 
-func (alg *Algorithm) CreateSimulatedAnnealing(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
-	var c *circuit.SimulatedAnnealing
-	if alg.PrecursorAlgorithm != nil {
-		precursorCircuit := alg.PrecursorAlgorithm.GetCircuitFunction()(vertices, perimeterBuilder)
-		c = circuit.NewSimulatedAnnealingFromCircuit(precursorCircuit, alg.MaxIterations, isTrue(alg.PreferCloseNeighbors))
-	} else {
-		c = circuit.NewSimulatedAnnealing(vertices, alg.MaxIterations, isTrue(alg.PreferCloseNeighbors))
+func (alg *Algorithm) configureGuidedLocalSearch(c *circuit.GuidedLocalSearch) *circuit.GuidedLocalSearch {
+	if alg.PenaltyFactor != nil {
+		c.SetPenaltyFactor(*alg.PenaltyFactor)
+	}
+	return c
+}
+
+func (alg *Algorithm) configureIteratedLocalSearch(c *circuit.IteratedLocalSearch) *circuit.IteratedLocalSearch {
+	switch alg.Acceptance {
+	case ACCEPTANCE_ANNEALING:
+		c.SetAcceptance(circuit.AcceptanceAnnealing)
+	case ACCEPTANCE_RANDOM_WALK:
+		c.SetAcceptance(circuit.AcceptanceRandomWalk)
+	}
+	if alg.Perturbation == PERTURBATION_SEGMENT_REVERSAL {
+		c.SetPerturbation(circuit.PerturbationSegmentReversal)
+	}
+	if alg.Improver != nil {
+		// The improver's stages start from each perturbed circuit, as though they were later stages of a pipeline.
+		c.SetImprover(func(vertices []model.CircuitVertex) model.Circuit {
+			stages := alg.Improver.getStages()
+			nextStages := make([]circuit.PipelineStage, len(stages))
+			for i, stage := range stages {
+				nextStages[i] = stage.getPipelineStage(vertices)
+			}
+			return circuit.NewPipeline(&circuit.CompletedCircuit{Circuit: vertices, Length: model.Length(vertices)}, nextStages...)
+		})
+	}
+	if alg.MaxStagnation > 0 {
+		c.SetMaxStagnation(alg.MaxStagnation)
 	}
+	if alg.Seed != nil {
+		c.SetSeed(*alg.Seed)
+	}
+	if alg.TimeLimitMillis > 0 {
+		c.SetMaxDuration(time.Duration(alg.TimeLimitMillis) * time.Millisecond)
+	}
+	return c
+}
+
+func (alg *Algorithm) configureLinKernighan(c *circuit.LinKernighan) *circuit.LinKernighan {
+	if alg.Seed != nil {
+		c.SetSeed(*alg.Seed)
+	}
+	return c
+}
+
+func (alg *Algorithm) configureTabuSearch(c *circuit.TabuSearch) *circuit.TabuSearch {
+	if alg.FrequencyWeight != nil {
+		c.SetFrequencyWeight(*alg.FrequencyWeight)
+	}
+	return c
+}
+
+// CreateSimulatedAnnealing creates a circuit.SimulatedAnnealing that improves the supplied points in the order they are supplied, which does not use the perimeter builder.
+// Any precursor algorithm is computed by the pipeline that GetCircuitFunction creates, rather than by this.
+func (alg *Algorithm) CreateSimulatedAnnealing(vertices []model.CircuitVertex, perimeterBuilder model.PerimeterBuilder) model.Circuit {
+	return alg.configureSimulatedAnnealing(circuit.NewSimulatedAnnealing(vertices, alg.MaxIterations, isTrue(alg.PreferCloseNeighbors)))
+}
+
+func (alg *Algorithm) configureSimulatedAnnealing(c *circuit.SimulatedAnnealing) *circuit.SimulatedAnnealing {
 	if alg.Seed != nil {
 		c.SetSeed(*alg.Seed)
 	}
@@ -138,6 +520,74 @@ func (alg *Algorithm) CreateSimulatedAnnealing(vertices []model.CircuitVertex, p
 	return c
 }
 
+// ValidateHubIndex returns an error if this algorithm, or any of its precursors, pipeline stages, or improvers, is a SAVINGS algorithm whose HubIndex is not the index of one of the request's points.
+// This is separate from the struct's validation tags, since those cannot compare the HubIndex to the number of points in the request.
+func (alg *Algorithm) ValidateHubIndex(request *TspRequest) error {
+	numPoints := len(request.Points2D) + len(request.Points3D) + len(request.PointsGraph)
+	if alg.AlgorithmType == ALG_SAVINGS && alg.HubIndex != nil && (*alg.HubIndex < 0 || *alg.HubIndex >= numPoints) {
+		return fmt.Errorf("hubIndex must be the index of a point in the request, found %d for %d points", *alg.HubIndex, numPoints)
+	}
+	if alg.PrecursorAlgorithm != nil {
+		if err := alg.PrecursorAlgorithm.ValidateHubIndex(request); err != nil {
+			return err
+		}
+	}
+	if alg.Improver != nil {
+		if err := alg.Improver.ValidateHubIndex(request); err != nil {
+			return err
+		}
+	}
+	for _, stage := range alg.Stages {
+		if err := stage.ValidateHubIndex(request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateNumPoints returns an error if this algorithm, or any of its precursors, pipeline stages, or improvers, cannot process the number of points in the request.
+// Currently this only limits CHRISTOFIDES, see circuit.ChristofidesMaxVertices.
+func (alg *Algorithm) ValidateNumPoints(request *TspRequest) error {
+	numPoints := len(request.Points2D) + len(request.Points3D) + len(request.PointsGraph)
+	if alg.AlgorithmType == ALG_CHRISTOFIDES && numPoints > circuit.ChristofidesMaxVertices {
+		return fmt.Errorf("CHRISTOFIDES supports at most %d points, found %d", circuit.ChristofidesMaxVertices, numPoints)
+	}
+	if alg.PrecursorAlgorithm != nil {
+		if err := alg.PrecursorAlgorithm.ValidateNumPoints(request); err != nil {
+			return err
+		}
+	}
+	if alg.Improver != nil {
+		if err := alg.Improver.ValidateNumPoints(request); err != nil {
+			return err
+		}
+	}
+	for _, stage := range alg.Stages {
+		if err := stage.ValidateNumPoints(request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveHub returns a vertex equal to the request's point at HubIndex, or nil if this is not a SAVINGS algorithm or HubIndex is not set.
+// It also returns nil if the request does not have a point at HubIndex, although solver.FindShortestPathApi rejects such requests (see ValidateHubIndex).
+func (alg *Algorithm) resolveHub(request *TspRequest) model.CircuitVertex {
+	if alg.AlgorithmType != ALG_SAVINGS || alg.HubIndex == nil || *alg.HubIndex < 0 {
+		return nil
+	}
+	index := *alg.HubIndex
+	if index < len(request.Points2D) {
+		return model2d.NewVertex2D(*request.Points2D[index].X, *request.Points2D[index].Y)
+	} else if index < len(request.Points3D) {
+		return model3d.NewVertex3D(*request.Points3D[index].X, *request.Points3D[index].Y, *request.Points3D[index].Z)
+	} else if index < len(request.PointsGraph) {
+		// Graph vertices are equal if their ids are equal, so the hub does not need the point's neighbors.
+		return graph.NewGraphVertex(request.PointsGraph[index].Id)
+	}
+	return nil
+}
+
 func isTrue(b *bool) bool {
 	return b != nil && *b
 }
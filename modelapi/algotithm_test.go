@@ -7,7 +7,9 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/model"
 	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
 	"github.com/heustis/tsp-solver-go/modelapi"
 	"github.com/heustis/tsp-solver-go/solver"
 	"github.com/stretchr/testify/assert"
@@ -28,6 +30,69 @@ func TestValidateAlgorithm(t *testing.T) {
 	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_ANNEALING, MaxIterations: 5000000, TemperatureFunction: "LINEAR"}))
 	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_ANNEALING, MaxIterations: 5000000, TemperatureFunction: "GEOMETRIC"}))
 
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_AUTO}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_AUTO, Seed: intPointer(5)}))
+
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_NEAREST_NEIGHBOR}))
+
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_CHEAPEST_INSERTION}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_CHRISTOFIDES}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_DOUBLE_TREE}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_TWO_OPT}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_TWO_OPT, NumNeighbors: 5}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_OR_OPT}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_OR_OPT, MaxSegmentLength: 2, NumNeighbors: 5}))
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_OR_OPT, MaxSegmentLength: -1}), "Key: 'Algorithm.MaxSegmentLength' Error:Field validation for 'MaxSegmentLength' failed on the 'isdefault|min=1' tag")
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_TABU_SEARCH, MaxIterations: 100}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_TABU_SEARCH, MaxIterations: 100, Tenure: 10, FrequencyWeight: float64Pointer(0), NumNeighbors: 5}))
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_TABU_SEARCH}), "Key: 'Algorithm.MaxIterations' Error:Field validation for 'MaxIterations' failed on the 'required_if' tag")
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_TABU_SEARCH, MaxIterations: 100, Tenure: -1}), "Key: 'Algorithm.Tenure' Error:Field validation for 'Tenure' failed on the 'isdefault|min=1' tag")
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_TABU_SEARCH, MaxIterations: 100, FrequencyWeight: float64Pointer(-0.5)}), "Key: 'Algorithm.FrequencyWeight' Error:Field validation for 'FrequencyWeight' failed on the 'min' tag")
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_GUIDED_LOCAL_SEARCH, MaxIterations: 100}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_GUIDED_LOCAL_SEARCH, MaxIterations: 100, PenaltyFactor: float64Pointer(0.5), NumNeighbors: 5}))
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_GUIDED_LOCAL_SEARCH}), "Key: 'Algorithm.MaxIterations' Error:Field validation for 'MaxIterations' failed on the 'required_if' tag")
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_GUIDED_LOCAL_SEARCH, MaxIterations: 100, PenaltyFactor: float64Pointer(0)}), "Key: 'Algorithm.PenaltyFactor' Error:Field validation for 'PenaltyFactor' failed on the 'gt' tag")
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_ITERATED_LOCAL_SEARCH, MaxIterations: 100}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{
+		AlgorithmType:   modelapi.ALG_ITERATED_LOCAL_SEARCH,
+		Acceptance:      modelapi.ACCEPTANCE_ANNEALING,
+		Improver:        &modelapi.Algorithm{AlgorithmType: modelapi.ALG_ANNEALING, MaxIterations: 1000},
+		MaxIterations:   100,
+		MaxStagnation:   10,
+		Perturbation:    modelapi.PERTURBATION_SEGMENT_REVERSAL,
+		TimeLimitMillis: 1000,
+	}))
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_ITERATED_LOCAL_SEARCH}), "Key: 'Algorithm.MaxIterations' Error:Field validation for 'MaxIterations' failed on the 'required_if' tag")
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_ITERATED_LOCAL_SEARCH, MaxIterations: 100, Acceptance: "WORSE"}), "Key: 'Algorithm.Acceptance' Error:Field validation for 'Acceptance' failed on the 'oneof' tag")
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_ITERATED_LOCAL_SEARCH, MaxIterations: 100, Perturbation: "SWAP"}), "Key: 'Algorithm.Perturbation' Error:Field validation for 'Perturbation' failed on the 'oneof' tag")
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_ITERATED_LOCAL_SEARCH, MaxIterations: 100, MaxStagnation: -1}), "Key: 'Algorithm.MaxStagnation' Error:Field validation for 'MaxStagnation' failed on the 'isdefault|min=1' tag")
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_ITERATED_LOCAL_SEARCH, MaxIterations: 100, TimeLimitMillis: -1}), "Key: 'Algorithm.TimeLimitMillis' Error:Field validation for 'TimeLimitMillis' failed on the 'min' tag")
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_ITERATED_LOCAL_SEARCH, MaxIterations: 100, Improver: &modelapi.Algorithm{AlgorithmType: modelapi.ALG_ANNEALING}}), "Key: 'Algorithm.Improver.MaxIterations' Error:Field validation for 'MaxIterations' failed on the 'required_if' tag")
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH, MaxIterations: 100}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH, MaxIterations: 100, Acceptance: modelapi.ACCEPTANCE_BETTER, MaxRemoved: 2}))
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH}), "Key: 'Algorithm.MaxIterations' Error:Field validation for 'MaxIterations' failed on the 'required_if' tag")
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH, MaxIterations: 100, MaxRemoved: 1}), "Key: 'Algorithm.MaxRemoved' Error:Field validation for 'MaxRemoved' failed on the 'isdefault|min=2' tag")
+	noTrials, negativeTrials := 0, -1
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_LIN_KERNIGHAN}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_LIN_KERNIGHAN, MaxTrials: &noTrials, NumNeighbors: 5, Seed: intPointer(5)}))
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_LIN_KERNIGHAN, MaxTrials: &negativeTrials}), "Key: 'Algorithm.MaxTrials' Error:Field validation for 'MaxTrials' failed on the 'min' tag")
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_GREEDY_EDGE}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_GREEDY_EDGE, NumNeighbors: 8}))
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_GREEDY_EDGE, NumNeighbors: -1}), "Key: 'Algorithm.NumNeighbors' Error:Field validation for 'NumNeighbors' failed on the 'isdefault|min=1' tag")
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY, PerimeterBuilder: modelapi.PERIMETER_FARTHEST_POINT}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY, PerimeterBuilder: modelapi.PERIMETER_MONOTONE_CHAIN}))
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY, PerimeterBuilder: "QUICKHULL"}), "Key: 'Algorithm.PerimeterBuilder' Error:Field validation for 'PerimeterBuilder' failed on the 'oneof' tag")
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_HILBERT_CURVE}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_MORTON_CURVE}))
+	hubIndex := 3
+	negativeHubIndex := -1
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_SAVINGS}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_SAVINGS, HubIndex: &hubIndex, NumNeighbors: 8}))
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_SAVINGS, HubIndex: &negativeHubIndex}), "Key: 'Algorithm.HubIndex' Error:Field validation for 'HubIndex' failed on the 'min' tag")
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_FARTHEST_INSERTION}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_NEAREST_INSERTION}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_RANDOM_INSERTION, Seed: intPointer(5)}))
+
 	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_CLONE}))
 	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_CLONE, MaxClones: intPointer(15)}))
 	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_CLONE, CloneOnFirstAttach: boolPointer(false)}))
@@ -73,18 +138,39 @@ func TestValidateAlgorithm(t *testing.T) {
 		`Key: 'Algorithm.MaxCrossovers' Error:Field validation for 'MaxCrossovers' failed on the 'isdefault|min=1' tag`)
 
 	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_GENETIC, MaxIterations: 10, NumChildren: 5, NumParents: 15, Seed: intPointer(12345), MaxCrossovers: 6}))
+
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_PIPELINE}), "Key: 'Algorithm.Stages' Error:Field validation for 'Stages' failed on the 'required_if' tag")
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_PIPELINE, Stages: []*modelapi.Algorithm{}}), "Key: 'Algorithm.Stages' Error:Field validation for 'Stages' failed on the 'min' tag")
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_PIPELINE, Stages: []*modelapi.Algorithm{{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY}, nil}}), "Key: 'Algorithm.Stages[1]' Error:Field validation for 'Stages[1]' failed on the 'required' tag")
+	assert.EqualError(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_PIPELINE, Stages: []*modelapi.Algorithm{{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY}, {AlgorithmType: modelapi.ALG_ANNEALING}}}), "Key: 'Algorithm.Stages[1].MaxIterations' Error:Field validation for 'MaxIterations' failed on the 'required_if' tag")
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_PIPELINE, Stages: []*modelapi.Algorithm{{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY}, {AlgorithmType: modelapi.ALG_ANNEALING, MaxIterations: 100}}}))
+	assert.Nil(validate.Struct(modelapi.Algorithm{AlgorithmType: modelapi.ALG_GENETIC, MaxIterations: 10, NumChildren: 5, NumParents: 15, PrecursorAlgorithm: &modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY}}))
 }
 
 func TestGetProcessFunction(t *testing.T) {
 	assert := assert.New(t)
 
 	alg := &modelapi.Algorithm{}
+	alg.AlgorithmType = modelapi.ALG_ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH
+	assert.True(reflect.ValueOf(alg.CreateAdaptiveLargeNeighborhoodSearch).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
 	alg.AlgorithmType = modelapi.ALG_ANNEALING
 	assert.True(reflect.ValueOf(alg.CreateSimulatedAnnealing).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
 
 	alg.AlgorithmType = modelapi.ALG_CLOSEST_CLONE
 	assert.True(reflect.ValueOf(alg.CreateClosestClone).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
 
+	for _, insertionType := range []modelapi.AlgorithmType{modelapi.ALG_CHEAPEST_INSERTION, modelapi.ALG_FARTHEST_INSERTION, modelapi.ALG_NEAREST_INSERTION, modelapi.ALG_RANDOM_INSERTION} {
+		alg.AlgorithmType = insertionType
+		assert.True(reflect.ValueOf(alg.CreateInsertion).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+	}
+
+	alg.AlgorithmType = modelapi.ALG_CHRISTOFIDES
+	assert.True(reflect.ValueOf(alg.CreateChristofides).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_DOUBLE_TREE
+	assert.True(reflect.ValueOf(alg.CreateDoubleTree).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
 	alg.AlgorithmType = modelapi.ALG_CLOSEST_GREEDY
 	assert.True(reflect.ValueOf(alg.CreateClosestGreedy).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
 
@@ -96,6 +182,46 @@ func TestGetProcessFunction(t *testing.T) {
 
 	alg.AlgorithmType = modelapi.ALG_GENETIC
 	assert.True(reflect.ValueOf(alg.CreateGenetic).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_GREEDY_EDGE
+	assert.True(reflect.ValueOf(alg.CreateGreedyEdge).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_GUIDED_LOCAL_SEARCH
+	assert.True(reflect.ValueOf(alg.CreateGuidedLocalSearch).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_HILBERT_CURVE
+	assert.True(reflect.ValueOf(alg.CreateSpaceFillingCurve).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_ITERATED_LOCAL_SEARCH
+	assert.True(reflect.ValueOf(alg.CreateIteratedLocalSearch).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_LIN_KERNIGHAN
+	assert.True(reflect.ValueOf(alg.CreateLinKernighan).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_MORTON_CURVE
+	assert.True(reflect.ValueOf(alg.CreateSpaceFillingCurve).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_NEAREST_NEIGHBOR
+	assert.True(reflect.ValueOf(alg.CreateNearestNeighbor).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_OR_OPT
+	assert.True(reflect.ValueOf(alg.CreateOrOpt).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_SAVINGS
+	assert.True(reflect.ValueOf(alg.CreateSavings).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_TABU_SEARCH
+	assert.True(reflect.ValueOf(alg.CreateTabuSearch).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_TWO_OPT
+	assert.True(reflect.ValueOf(alg.CreateTwoOpt).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_PIPELINE
+	assert.True(reflect.ValueOf(alg.CreatePipeline).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
+
+	alg.AlgorithmType = modelapi.ALG_GENETIC
+	alg.PrecursorAlgorithm = &modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY}
+	assert.True(reflect.ValueOf(alg.CreatePipeline).Pointer() == reflect.ValueOf(alg.GetCircuitFunction()).Pointer())
 }
 
 func TestCreateClosestClone(t *testing.T) {
@@ -261,17 +387,503 @@ func TestCreateSimulatedAnnealing(t *testing.T) {
 	c = alg.CreateSimulatedAnnealing(vertices, model2d.BuildPerimiter)
 	assert.IsType(&circuit.SimulatedAnnealing{}, c)
 
+	// Precursor algorithms are computed by a pipeline, which creates the annealing circuit once the precursor is complete.
 	alg.PrecursorAlgorithm = &modelapi.Algorithm{
 		AlgorithmType: modelapi.ALG_CLOSEST_GREEDY,
 	}
-	c = alg.CreateSimulatedAnnealing(vertices, model2d.BuildPerimiter)
-	assert.IsType(&circuit.SimulatedAnnealing{}, c)
+	c = alg.GetCircuitFunction()(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.Pipeline{}, c)
+	solver.FindShortestPathCircuit(c)
+	assert.IsType(&circuit.SimulatedAnnealing{}, c.(*circuit.Pipeline).GetCurrentCircuit())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+}
 
-	alg.PrecursorAlgorithm = &modelapi.Algorithm{
-		AlgorithmType: modelapi.ALG_DISPARITY_GREEDY,
+func TestCreateChristofides(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.GenerateVertices(10)
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_CHRISTOFIDES}
+	c := alg.CreateChristofides(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.Christofides{}, c)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+}
+
+func TestCreateDoubleTree(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.GenerateVertices(10)
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_DOUBLE_TREE}
+	c := alg.CreateDoubleTree(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.DoubleTree{}, c)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+}
+
+func TestCreateGreedyEdge(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.GenerateVertices(20)
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_GREEDY_EDGE}
+	c := alg.CreateGreedyEdge(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.GreedyEdge{}, c)
+	assert.Equal(0, c.(*circuit.GreedyEdge).GetNumNeighbors())
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+
+	alg.NumNeighbors = 4
+	c = alg.CreateGreedyEdge(vertices, model2d.BuildPerimiter)
+	assert.Equal(4, c.(*circuit.GreedyEdge).GetNumNeighbors())
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+}
+
+func TestGetCircuitFunction_ShouldSelectPerimeterBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	numCalls := 0
+	countingBuilder := func(builder model.PerimeterBuilder) model.PerimeterBuilder {
+		return func(vertices []model.CircuitVertex) ([]model.CircuitEdge, map[model.CircuitVertex]bool) {
+			numCalls++
+			return builder(vertices)
+		}
 	}
-	c = alg.CreateSimulatedAnnealing(vertices, model2d.BuildPerimiter)
-	assert.IsType(&circuit.SimulatedAnnealing{}, c)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+	expected := circuit.NewClosestGreedy(vertices, model2d.BuildPerimiter, false)
+	solver.FindShortestPathCircuit(expected)
+
+	for _, alg := range []*modelapi.Algorithm{
+		{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY, PerimeterBuilder: modelapi.PERIMETER_MONOTONE_CHAIN},
+		{AlgorithmType: modelapi.ALG_PIPELINE, Stages: []*modelapi.Algorithm{
+			{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY, PerimeterBuilder: modelapi.PERIMETER_MONOTONE_CHAIN},
+			{AlgorithmType: modelapi.ALG_DISPARITY_GREEDY},
+		}},
+	} {
+		c := alg.GetCircuitFunction()(vertices, countingBuilder(model2d.BuildPerimiter))
+		solver.FindShortestPathCircuit(c)
+		assert.Equal(0, numCalls)
+		assert.InDelta(expected.GetLength(), c.GetLength(), model.Threshold)
+	}
+
+	// The monotone chain only supports 2D vertices, and the default perimeter builder uses the supplied builder.
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY, PerimeterBuilder: modelapi.PERIMETER_MONOTONE_CHAIN}
+	c := alg.GetCircuitFunction()(model3d.GenerateVertices(20), countingBuilder(model3d.BuildPerimiter))
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(1, numCalls)
+
+	alg.PerimeterBuilder = modelapi.PERIMETER_FARTHEST_POINT
+	c = alg.GetCircuitFunction()(vertices, countingBuilder(model2d.BuildPerimiter))
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(2, numCalls)
+}
+
+func TestCreateSavings(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.GenerateVertices(20)
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_SAVINGS}
+	c := alg.CreateSavings(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.Savings{}, c)
+	assert.Equal(0, c.(*circuit.Savings).GetNumNeighbors())
+	assert.Equal(circuit.NewSavings(vertices, nil).GetHub(), c.(*circuit.Savings).GetHub())
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+
+	alg.NumNeighbors = 4
+	c = alg.CreateSavings(vertices, model2d.BuildPerimiter)
+	assert.Equal(4, c.(*circuit.Savings).GetNumNeighbors())
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+}
+
+func TestCreateSpaceFillingCurve(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.GenerateVertices(20)
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_HILBERT_CURVE}
+	c := alg.CreateSpaceFillingCurve(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.SpaceFillingCurve{}, c)
+	assert.Equal(circuit.CurveHilbert, c.(*circuit.SpaceFillingCurve).GetCurveType())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+
+	alg.AlgorithmType = modelapi.ALG_MORTON_CURVE
+	c = alg.CreateSpaceFillingCurve(vertices, model2d.BuildPerimiter)
+	assert.Equal(circuit.CurveMorton, c.(*circuit.SpaceFillingCurve).GetCurveType())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+}
+
+func TestCreateInsertion(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.GenerateVertices(10)
+
+	expected := map[modelapi.AlgorithmType]circuit.InsertionType{
+		modelapi.ALG_CHEAPEST_INSERTION: circuit.InsertionCheapest,
+		modelapi.ALG_FARTHEST_INSERTION: circuit.InsertionFarthest,
+		modelapi.ALG_NEAREST_INSERTION:  circuit.InsertionNearest,
+		modelapi.ALG_RANDOM_INSERTION:   circuit.InsertionRandom,
+	}
+	for algorithmType, insertionType := range expected {
+		alg := &modelapi.Algorithm{AlgorithmType: algorithmType, Seed: intPointer(3)}
+		c := alg.CreateInsertion(vertices, model2d.BuildPerimiter)
+		assert.IsType(&circuit.Insertion{}, c)
+		assert.Equal(insertionType, c.(*circuit.Insertion).GetInsertionType())
+		solver.FindShortestPathCircuit(c)
+		assert.Len(c.GetAttachedVertices(), len(vertices))
+	}
+
+	// Random insertion is consistent when it has a seed.
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_RANDOM_INSERTION, Seed: intPointer(3)}
+	first := alg.CreateInsertion(vertices, model2d.BuildPerimiter)
+	solver.FindShortestPathCircuit(first)
+	second := alg.CreateInsertion(vertices, model2d.BuildPerimiter)
+	solver.FindShortestPathCircuit(second)
+	assert.Equal(first.GetAttachedVertices(), second.GetAttachedVertices())
+}
+
+func TestCreateNearestNeighbor(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.GenerateVertices(10)
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_NEAREST_NEIGHBOR}
+	c := alg.CreateNearestNeighbor(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.NearestNeighbor{}, c)
+
+	alg = &modelapi.Algorithm{
+		AlgorithmType:      modelapi.ALG_ANNEALING,
+		MaxIterations:      100,
+		PrecursorAlgorithm: &modelapi.Algorithm{AlgorithmType: modelapi.ALG_NEAREST_NEIGHBOR},
+	}
+	c = alg.GetCircuitFunction()(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.NearestNeighbor{}, c.(*circuit.Pipeline).GetCurrentCircuit())
+	solver.FindShortestPathCircuit(c)
+	assert.IsType(&circuit.SimulatedAnnealing{}, c.(*circuit.Pipeline).GetCurrentCircuit())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+}
+
+func TestCreatePipeline(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(30))
+
+	alg := &modelapi.Algorithm{
+		AlgorithmType: modelapi.ALG_PIPELINE,
+		Stages: []*modelapi.Algorithm{
+			{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY},
+			{AlgorithmType: modelapi.ALG_ANNEALING, MaxIterations: 500, Seed: intPointer(12)},
+			{
+				AlgorithmType: modelapi.ALG_PIPELINE,
+				Stages: []*modelapi.Algorithm{
+					{AlgorithmType: modelapi.ALG_GENETIC, MaxIterations: 5, NumChildren: 10, NumParents: 10, Seed: intPointer(34)},
+					{AlgorithmType: modelapi.ALG_DISPARITY_GREEDY},
+				},
+			},
+		},
+	}
+	c := alg.CreatePipeline(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.Pipeline{}, c)
+	pipeline := c.(*circuit.Pipeline)
+	assert.Equal(4, pipeline.GetNumStages())
+	assert.IsType(&circuit.ClosestGreedy{}, pipeline.GetCurrentCircuit())
+
+	solver.FindShortestPathCircuit(c)
+	assert.IsType(&circuit.DisparityGreedy{}, pipeline.GetCurrentCircuit())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Len(c.GetUnattachedVertices(), 0)
+	stageLengths := pipeline.GetStageLengths()
+	assert.Len(stageLengths, 4)
+	// The genetic algorithm retains its precursor's circuit as a parent, and the disparity greedy algorithm has no vertices left to attach, so neither can lengthen the circuit.
+	assert.LessOrEqual(stageLengths[2], stageLengths[1]+model.Threshold)
+	assert.InDelta(stageLengths[2], stageLengths[3], model.Threshold)
+
+	alg = &modelapi.Algorithm{
+		AlgorithmType:      modelapi.ALG_GENETIC,
+		MaxIterations:      5,
+		NumChildren:        10,
+		NumParents:         10,
+		PrecursorAlgorithm: &modelapi.Algorithm{AlgorithmType: modelapi.ALG_DISPARITY_GREEDY},
+	}
+	c = alg.GetCircuitFunction()(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.Pipeline{}, c)
+	pipeline = c.(*circuit.Pipeline)
+	assert.Equal(2, pipeline.GetNumStages())
+	solver.FindShortestPathCircuit(c)
+	assert.IsType(&circuit.GeneticAlgorithm{}, pipeline.GetCurrentCircuit())
+	assert.Len(pipeline.GetStageLengths(), 2)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+}
+
+func TestCreateAdaptiveLargeNeighborhoodSearch(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH, MaxIterations: 50}
+	c := alg.CreateAdaptiveLargeNeighborhoodSearch(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.AdaptiveLargeNeighborhoodSearch{}, c)
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(50, c.(*circuit.AdaptiveLargeNeighborhoodSearch).GetNumIterations())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Less(c.GetLength(), model.Length(vertices))
+
+	var expected []model.CircuitVertex
+	for i := 0; i < 2; i++ {
+		alg = &modelapi.Algorithm{
+			AlgorithmType: modelapi.ALG_ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH,
+			Acceptance:    modelapi.ACCEPTANCE_BETTER,
+			MaxIterations: 30,
+			MaxRemoved:    5,
+			Seed:          intPointer(5),
+		}
+		c = alg.CreateAdaptiveLargeNeighborhoodSearch(vertices, model2d.BuildPerimiter)
+		solver.FindShortestPathCircuit(c)
+		if expected == nil {
+			expected = c.GetAttachedVertices()
+		} else {
+			assert.Equal(expected, c.GetAttachedVertices())
+		}
+	}
+
+	alg = &modelapi.Algorithm{
+		AlgorithmType:      modelapi.ALG_ADAPTIVE_LARGE_NEIGHBORHOOD_SEARCH,
+		MaxIterations:      30,
+		PrecursorAlgorithm: &modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY},
+	}
+	c = alg.GetCircuitFunction()(vertices, model2d.BuildPerimiter)
+	pipeline := c.(*circuit.Pipeline)
+	solver.FindShortestPathCircuit(c)
+	assert.IsType(&circuit.AdaptiveLargeNeighborhoodSearch{}, pipeline.GetCurrentCircuit())
+	assert.Equal(30, pipeline.GetCurrentCircuit().(*circuit.AdaptiveLargeNeighborhoodSearch).GetNumIterations())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	stageLengths := pipeline.GetStageLengths()
+	assert.Len(stageLengths, 2)
+	// Adaptive large neighborhood search retains the best circuit, which is at least as short as its initial circuit.
+	assert.LessOrEqual(stageLengths[1], stageLengths[0]+model.Threshold)
+}
+
+func TestCreateGuidedLocalSearch(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_GUIDED_LOCAL_SEARCH, MaxIterations: 50}
+	c := alg.CreateGuidedLocalSearch(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.GuidedLocalSearch{}, c)
+	assert.Equal(circuit.GuidedLocalSearchDefaultPenaltyFactor, c.(*circuit.GuidedLocalSearch).GetPenaltyFactor())
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(50, c.(*circuit.GuidedLocalSearch).GetNumIterations())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Less(c.GetLength(), model.Length(vertices))
+
+	alg = &modelapi.Algorithm{
+		AlgorithmType:      modelapi.ALG_GUIDED_LOCAL_SEARCH,
+		MaxIterations:      30,
+		PenaltyFactor:      float64Pointer(0.5),
+		PrecursorAlgorithm: &modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY},
+	}
+	c = alg.GetCircuitFunction()(vertices, model2d.BuildPerimiter)
+	pipeline := c.(*circuit.Pipeline)
+	solver.FindShortestPathCircuit(c)
+	assert.IsType(&circuit.GuidedLocalSearch{}, pipeline.GetCurrentCircuit())
+	assert.Equal(0.5, pipeline.GetCurrentCircuit().(*circuit.GuidedLocalSearch).GetPenaltyFactor())
+	assert.Equal(30, pipeline.GetCurrentCircuit().(*circuit.GuidedLocalSearch).GetNumIterations())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	stageLengths := pipeline.GetStageLengths()
+	assert.Len(stageLengths, 2)
+	// Guided local search retains the best circuit, which is at least as short as its initial circuit.
+	assert.LessOrEqual(stageLengths[1], stageLengths[0]+model.Threshold)
+}
+
+func TestCreateIteratedLocalSearch(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_ITERATED_LOCAL_SEARCH, MaxIterations: 50}
+	c := alg.CreateIteratedLocalSearch(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.IteratedLocalSearch{}, c)
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(50, c.(*circuit.IteratedLocalSearch).GetNumIterations())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Less(c.GetLength(), model.Length(vertices))
+
+	alg = &modelapi.Algorithm{
+		AlgorithmType: modelapi.ALG_ITERATED_LOCAL_SEARCH,
+		Acceptance:    modelapi.ACCEPTANCE_RANDOM_WALK,
+		MaxIterations: 30,
+		Perturbation:  modelapi.PERTURBATION_SEGMENT_REVERSAL,
+		Seed:          intPointer(5),
+	}
+	c = alg.CreateIteratedLocalSearch(vertices, model2d.BuildPerimiter)
+	solver.FindShortestPathCircuit(c)
+	// Random walk acceptance accepts every iteration.
+	assert.Equal(30, c.(*circuit.IteratedLocalSearch).GetNumAccepted())
+
+	alg = &modelapi.Algorithm{
+		AlgorithmType:   modelapi.ALG_ITERATED_LOCAL_SEARCH,
+		MaxIterations:   100000,
+		MaxStagnation:   3,
+		TimeLimitMillis: 10000,
+	}
+	c = alg.CreateIteratedLocalSearch(vertices, model2d.BuildPerimiter)
+	solver.FindShortestPathCircuit(c)
+	assert.Less(c.(*circuit.IteratedLocalSearch).GetNumIterations(), 100000)
+
+	alg = &modelapi.Algorithm{
+		AlgorithmType:      modelapi.ALG_ITERATED_LOCAL_SEARCH,
+		Acceptance:         modelapi.ACCEPTANCE_ANNEALING,
+		Improver:           &modelapi.Algorithm{AlgorithmType: modelapi.ALG_ANNEALING, MaxIterations: 500, PreferCloseNeighbors: boolPointer(true)},
+		MaxIterations:      5,
+		PrecursorAlgorithm: &modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY},
+	}
+	c = alg.GetCircuitFunction()(vertices, model2d.BuildPerimiter)
+	pipeline := c.(*circuit.Pipeline)
+	solver.FindShortestPathCircuit(c)
+	assert.IsType(&circuit.IteratedLocalSearch{}, pipeline.GetCurrentCircuit())
+	assert.Equal(5, pipeline.GetCurrentCircuit().(*circuit.IteratedLocalSearch).GetNumIterations())
+	// The simulated annealing improver replaces the 2-opt and Or-opt moves.
+	assert.Equal(0, pipeline.GetCurrentCircuit().(*circuit.IteratedLocalSearch).GetNumImprovements())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.InDelta(model.Length(c.GetAttachedVertices()), c.GetLength(), model.Threshold)
+	stageLengths := pipeline.GetStageLengths()
+	assert.Len(stageLengths, 2)
+}
+
+func TestCreateLinKernighan(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_LIN_KERNIGHAN}
+	c := alg.CreateLinKernighan(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.LinKernighan{}, c)
+	assert.Equal(len(vertices), c.(*circuit.LinKernighan).GetMaxTrials())
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Less(c.GetLength(), model.Length(vertices))
+
+	maxTrials := 10
+	alg = &modelapi.Algorithm{
+		AlgorithmType:      modelapi.ALG_LIN_KERNIGHAN,
+		MaxTrials:          &maxTrials,
+		PrecursorAlgorithm: &modelapi.Algorithm{AlgorithmType: modelapi.ALG_GREEDY_EDGE},
+		Seed:               intPointer(7),
+	}
+	c = alg.GetCircuitFunction()(vertices, model2d.BuildPerimiter)
+	pipeline := c.(*circuit.Pipeline)
+	solver.FindShortestPathCircuit(c)
+	assert.IsType(&circuit.LinKernighan{}, pipeline.GetCurrentCircuit())
+	assert.Equal(10, pipeline.GetCurrentCircuit().(*circuit.LinKernighan).GetNumTrials())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	stageLengths := pipeline.GetStageLengths()
+	assert.Len(stageLengths, 2)
+	assert.LessOrEqual(stageLengths[1], stageLengths[0]+model.Threshold)
+
+	// The same seed produces the same circuit.
+	expected := c.GetAttachedVertices()
+	c = alg.GetCircuitFunction()(vertices, model2d.BuildPerimiter)
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(expected, c.GetAttachedVertices())
+}
+
+func TestCreateOrOpt(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_OR_OPT}
+	c := alg.CreateOrOpt(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.OrOpt{}, c)
+	assert.Equal(circuit.OrOptDefaultMaxSegmentLength, c.(*circuit.OrOpt).GetMaxSegmentLength())
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Less(c.GetLength(), model.Length(vertices))
+
+	alg = &modelapi.Algorithm{
+		AlgorithmType: modelapi.ALG_PIPELINE,
+		Stages: []*modelapi.Algorithm{
+			{AlgorithmType: modelapi.ALG_DISPARITY_GREEDY},
+			{AlgorithmType: modelapi.ALG_OR_OPT, MaxSegmentLength: 2},
+		},
+	}
+	c = alg.GetCircuitFunction()(vertices, model2d.BuildPerimiter)
+	pipeline := c.(*circuit.Pipeline)
+	solver.FindShortestPathCircuit(c)
+	assert.IsType(&circuit.OrOpt{}, pipeline.GetCurrentCircuit())
+	assert.Equal(2, pipeline.GetCurrentCircuit().(*circuit.OrOpt).GetMaxSegmentLength())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	stageLengths := pipeline.GetStageLengths()
+	assert.Len(stageLengths, 2)
+	assert.LessOrEqual(stageLengths[1], stageLengths[0]+model.Threshold)
+}
+
+func TestCreateTabuSearch(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_TABU_SEARCH, MaxIterations: 50}
+	c := alg.CreateTabuSearch(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.TabuSearch{}, c)
+	assert.Equal(circuit.TabuSearchDefaultTenure, c.(*circuit.TabuSearch).GetTenure())
+	solver.FindShortestPathCircuit(c)
+	assert.Equal(50, c.(*circuit.TabuSearch).GetNumIterations())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Less(c.GetLength(), model.Length(vertices))
+
+	alg = &modelapi.Algorithm{
+		AlgorithmType:      modelapi.ALG_TABU_SEARCH,
+		FrequencyWeight:    float64Pointer(0.25),
+		MaxIterations:      30,
+		PrecursorAlgorithm: &modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY},
+		Tenure:             7,
+	}
+	c = alg.GetCircuitFunction()(vertices, model2d.BuildPerimiter)
+	pipeline := c.(*circuit.Pipeline)
+	solver.FindShortestPathCircuit(c)
+	assert.IsType(&circuit.TabuSearch{}, pipeline.GetCurrentCircuit())
+	assert.Equal(7, pipeline.GetCurrentCircuit().(*circuit.TabuSearch).GetTenure())
+	assert.Equal(30, pipeline.GetCurrentCircuit().(*circuit.TabuSearch).GetNumIterations())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	stageLengths := pipeline.GetStageLengths()
+	assert.Len(stageLengths, 2)
+	// Tabu search retains the best circuit, which is at least as short as its initial circuit.
+	assert.LessOrEqual(stageLengths[1], stageLengths[0]+model.Threshold)
+}
+
+func TestCreateTwoOpt(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_TWO_OPT}
+	c := alg.CreateTwoOpt(vertices, model2d.BuildPerimiter)
+	assert.IsType(&circuit.TwoOpt{}, c)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	assert.Less(c.GetLength(), model.Length(vertices))
+
+	// TwoOpt is intended as the final stage after a construction, such as the convex-concave algorithms.
+	alg = &modelapi.Algorithm{
+		AlgorithmType:      modelapi.ALG_TWO_OPT,
+		NumNeighbors:       5,
+		PrecursorAlgorithm: &modelapi.Algorithm{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY},
+	}
+	c = alg.GetCircuitFunction()(vertices, model2d.BuildPerimiter)
+	pipeline := c.(*circuit.Pipeline)
+	solver.FindShortestPathCircuit(c)
+	assert.IsType(&circuit.TwoOpt{}, pipeline.GetCurrentCircuit())
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+	stageLengths := pipeline.GetStageLengths()
+	assert.Len(stageLengths, 2)
+	assert.LessOrEqual(stageLengths[1], stageLengths[0]+model.Threshold)
 }
 
 func boolPointer(b bool) *bool {
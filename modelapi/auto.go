@@ -0,0 +1,141 @@
+package modelapi
+
+import (
+	"math"
+)
+
+// These estimates are used by AUTO to determine how much work fits in a request's time limit.
+// They are deliberately conservative (roughly half the throughput measured on a single core), since the portfolio shares cores between algorithms.
+const (
+	// autoGreedyNanosPerPointSquared is the approximate cost of the ClosestGreedy algorithm, which is O(n^2).
+	autoGreedyNanosPerPointSquared = 50.0
+	// autoAnnealingIterationsPerMilli is the approximate number of SimulatedAnnealing iterations per millisecond for 2D and 3D points.
+	autoAnnealingIterationsPerMilli = 2000.0
+	// autoAnnealingIterationsPerMilliGraph is the approximate number of SimulatedAnnealing iterations per millisecond for graphs, whose distances are map lookups.
+	autoAnnealingIterationsPerMilliGraph = 500.0
+	// autoAnnealingTimeFraction is the fraction of the remaining time that SimulatedAnnealing is sized for, so that the pipeline can complete within the time limit on slower (or shared) cores.
+	autoAnnealingTimeFraction = 0.5
+	// autoGraphCostMultiplier scales the cost of the ClosestGreedy algorithm for graphs, since their perimeter and distances are more expensive to compute.
+	autoGraphCostMultiplier = 2.0
+	// autoMaxGreedyPoints is the largest number of points that ClosestGreedy is used for, when the request does not have a time limit, since it is O(n^2) (roughly a second for this many points).
+	autoMaxGreedyPoints = 5000
+	// autoIterationsPerPoint is the number of SimulatedAnnealing iterations per point, when the request does not have a time limit.
+	autoIterationsPerPoint = 1000
+	// autoMinIterations and autoMaxIterations bound the number of SimulatedAnnealing iterations.
+	autoMinIterations = 10000
+	autoMaxIterations = 10000000
+)
+
+// Resolve returns the algorithm that should be computed for the supplied request, replacing any AUTO algorithm (including precursors, pipeline stages, and improvers) with the configuration selected for the request.
+// It also records the hub point of any SAVINGS algorithm with a HubIndex, since the circuit functions only receive the (possibly deduplicated and reordered) vertices.
+// If the algorithm does not contain an AUTO algorithm or a SAVINGS hub, it is returned unchanged, otherwise a modified copy is returned so that the original algorithm is not altered.
+//
+// AUTO selects its configuration from the number of points, the type of points, and the request's time limit:
+// 1. The points are attached by the ClosestGreedy algorithm, since it is O(n^2), rather than a cloning algorithm, which can be O(n!).
+//     * If ClosestGreedy is estimated to take more than half of the time limit, the faster (but less accurate) NearestNeighbor algorithm is used instead.
+//     * If there is no time limit and there are more than autoMaxGreedyPoints points, GreedyEdge, which is O(n*log(n)), is used for 2D and 3D points, and NearestNeighbor for graphs.
+// 2. Half of the remaining time (or, if there is no time limit, a number of iterations proportional to the number of points) is spent refining the circuit with SimulatedAnnealing.
+// 3. If that time is too short for at least one iteration per point, SimulatedAnnealing is skipped.
+func (alg *Algorithm) Resolve(request *TspRequest) *Algorithm {
+	if alg.AlgorithmType == ALG_AUTO {
+		return alg.resolveAuto(request)
+	}
+
+	var precursor *Algorithm
+	if alg.PrecursorAlgorithm != nil {
+		precursor = alg.PrecursorAlgorithm.Resolve(request)
+	}
+	var improver *Algorithm
+	if alg.Improver != nil {
+		improver = alg.Improver.Resolve(request)
+	}
+	stages := make([]*Algorithm, len(alg.Stages))
+	hub := alg.resolveHub(request)
+	isChanged := precursor != alg.PrecursorAlgorithm || improver != alg.Improver || hub != nil
+	for i, stage := range alg.Stages {
+		stages[i] = stage.Resolve(request)
+		isChanged = isChanged || stages[i] != stage
+	}
+	if !isChanged {
+		return alg
+	}
+
+	resolved := *alg
+	resolved.Improver = improver
+	resolved.PrecursorAlgorithm = precursor
+	resolved.hub = hub
+	resolved.Stages = stages
+	return &resolved
+}
+
+// ContainsAuto returns true if this algorithm, or any of its precursors, pipeline stages, or improvers, is an AUTO algorithm.
+func (alg *Algorithm) ContainsAuto() bool {
+	if alg.AlgorithmType == ALG_AUTO {
+		return true
+	}
+	if (alg.PrecursorAlgorithm != nil && alg.PrecursorAlgorithm.ContainsAuto()) || (alg.Improver != nil && alg.Improver.ContainsAuto()) {
+		return true
+	}
+	for _, stage := range alg.Stages {
+		if stage.ContainsAuto() {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveAuto selects the configuration of an AUTO algorithm, see Resolve.
+func (alg *Algorithm) resolveAuto(request *TspRequest) *Algorithm {
+	numPoints := len(request.Points2D) + len(request.Points3D) + len(request.PointsGraph)
+	isGraph := len(request.PointsGraph) > 0
+
+	construction := &Algorithm{AlgorithmType: ALG_CLOSEST_GREEDY}
+
+	var maxIterations int
+	if request.TimeLimitMillis > 0 {
+		timeLimitMillis := float64(request.TimeLimitMillis)
+		greedyMillis := autoGreedyNanosPerPointSquared * float64(numPoints) * float64(numPoints) / 1e6
+		iterationsPerMilli := autoAnnealingIterationsPerMilli
+		if isGraph {
+			greedyMillis *= autoGraphCostMultiplier
+			iterationsPerMilli = autoAnnealingIterationsPerMilliGraph
+		}
+		// NearestNeighbor is O(n*log(n)) for 2D and 3D points, and has a small constant factor for graphs, so its duration is treated as negligible.
+		remainingMillis := timeLimitMillis - greedyMillis
+		if greedyMillis > timeLimitMillis/2 {
+			construction = &Algorithm{AlgorithmType: ALG_NEAREST_NEIGHBOR}
+			remainingMillis = timeLimitMillis
+		}
+		maxIterations = int(math.Min(autoAnnealingTimeFraction*remainingMillis*iterationsPerMilli, autoMaxIterations))
+	} else {
+		if numPoints > autoMaxGreedyPoints {
+			if isGraph {
+				construction = &Algorithm{AlgorithmType: ALG_NEAREST_NEIGHBOR}
+			} else {
+				construction = &Algorithm{AlgorithmType: ALG_GREEDY_EDGE}
+			}
+		}
+		maxIterations = numPoints * autoIterationsPerPoint
+		if maxIterations < autoMinIterations {
+			maxIterations = autoMinIterations
+		} else if maxIterations > autoMaxIterations {
+			maxIterations = autoMaxIterations
+		}
+	}
+
+	if maxIterations < numPoints {
+		return construction
+	}
+
+	return &Algorithm{
+		AlgorithmType: ALG_PIPELINE,
+		Stages: []*Algorithm{
+			construction,
+			{
+				AlgorithmType: ALG_ANNEALING,
+				MaxIterations: maxIterations,
+				Seed:          alg.Seed,
+			},
+		},
+	}
+}
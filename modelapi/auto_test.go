@@ -0,0 +1,237 @@
+package modelapi_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/modelapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve_Auto(t *testing.T) {
+	assert := assert.New(t)
+
+	request := modelapi.ToApiFrom2D(model2d.GenerateVertices(10))
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_AUTO, Seed: intPointer(42)}
+	resolved := alg.Resolve(request)
+	assert.Equal(modelapi.ALG_PIPELINE, resolved.AlgorithmType)
+	assert.Len(resolved.Stages, 2)
+	assert.Equal(modelapi.ALG_CLOSEST_GREEDY, resolved.Stages[0].AlgorithmType)
+	assert.Equal(modelapi.ALG_ANNEALING, resolved.Stages[1].AlgorithmType)
+	assert.Equal(alg.Seed, resolved.Stages[1].Seed)
+	// Small requests use the minimum number of iterations.
+	assert.Equal(10000, resolved.Stages[1].MaxIterations)
+	assert.Equal(modelapi.ALG_AUTO, alg.AlgorithmType)
+
+	// Without a time limit, the number of iterations is proportional to the number of points.
+	request = modelapi.ToApiFrom3D(model3d.GenerateVertices(50))
+	resolved = alg.Resolve(request)
+	assert.Equal(modelapi.ALG_PIPELINE, resolved.AlgorithmType)
+	assert.Equal(50000, resolved.Stages[1].MaxIterations)
+
+	// With a time limit, the number of iterations is limited by the time remaining after the greedy algorithm.
+	request.TimeLimitMillis = 100
+	resolved = alg.Resolve(request)
+	assert.Equal(modelapi.ALG_PIPELINE, resolved.AlgorithmType)
+	assert.Greater(resolved.Stages[1].MaxIterations, 50)
+	assert.Less(resolved.Stages[1].MaxIterations, 200000)
+
+	// If there is not enough time for the greedy algorithm, the nearest neighbor algorithm is used instead.
+	request = modelapi.ToApiFrom2D(model2d.GenerateVertices(2000))
+	request.TimeLimitMillis = 100
+	resolved = alg.Resolve(request)
+	assert.Equal(modelapi.ALG_PIPELINE, resolved.AlgorithmType)
+	assert.Equal(modelapi.ALG_NEAREST_NEIGHBOR, resolved.Stages[0].AlgorithmType)
+	// Annealing is sized for half of the time limit, so that the pipeline can complete before the time limit.
+	assert.Equal(100000, resolved.Stages[1].MaxIterations)
+
+	// If there is not enough time for the annealing to be useful, only the construction algorithm is used.
+	request = modelapi.ToApiFrom2D(model2d.GenerateVertices(5000))
+	request.TimeLimitMillis = 1
+	resolved = alg.Resolve(request)
+	assert.Equal(modelapi.ALG_NEAREST_NEIGHBOR, resolved.AlgorithmType)
+
+	// Without a time limit, large requests use a faster construction than the O(n^2) greedy algorithm.
+	request = modelapi.ToApiFrom2D(model2d.GenerateVertices(100000))
+	resolved = alg.Resolve(request)
+	assert.Equal(modelapi.ALG_PIPELINE, resolved.AlgorithmType)
+	assert.Equal(modelapi.ALG_GREEDY_EDGE, resolved.Stages[0].AlgorithmType)
+	assert.Equal(10000000, resolved.Stages[1].MaxIterations)
+
+	request = modelapi.ToApiFrom3D(model3d.GenerateVertices(6000))
+	resolved = alg.Resolve(request)
+	assert.Equal(modelapi.ALG_GREEDY_EDGE, resolved.Stages[0].AlgorithmType)
+
+	request = &modelapi.TspRequest{PointsGraph: make([]*modelapi.PointGraph, 6000)}
+	resolved = alg.Resolve(request)
+	assert.Equal(modelapi.ALG_NEAREST_NEIGHBOR, resolved.Stages[0].AlgorithmType)
+
+	request = modelapi.ToApiFrom2D(model2d.GenerateVertices(5000))
+	resolved = alg.Resolve(request)
+	assert.Equal(modelapi.ALG_CLOSEST_GREEDY, resolved.Stages[0].AlgorithmType)
+
+	// Graphs are more expensive, so receive fewer iterations for the same time limit.
+	request2D := modelapi.ToApiFrom2D(model2d.GenerateVertices(20))
+	request2D.TimeLimitMillis = 50
+	requestGraph := &modelapi.TspRequest{PointsGraph: make([]*modelapi.PointGraph, 20), TimeLimitMillis: 50}
+	assert.Greater(alg.Resolve(request2D).Stages[1].MaxIterations, alg.Resolve(requestGraph).Stages[1].MaxIterations)
+}
+
+func TestResolve_ShouldResolveNestedAlgorithms(t *testing.T) {
+	assert := assert.New(t)
+
+	request := modelapi.ToApiFrom2D(model2d.GenerateVertices(10))
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_DISPARITY_GREEDY}
+	assert.Same(alg, alg.Resolve(request))
+
+	alg = &modelapi.Algorithm{
+		AlgorithmType: modelapi.ALG_PIPELINE,
+		Stages: []*modelapi.Algorithm{
+			{AlgorithmType: modelapi.ALG_DISPARITY_GREEDY},
+			{AlgorithmType: modelapi.ALG_ANNEALING, MaxIterations: 10},
+		},
+	}
+	assert.Same(alg, alg.Resolve(request))
+
+	alg = &modelapi.Algorithm{
+		AlgorithmType: modelapi.ALG_PIPELINE,
+		Stages: []*modelapi.Algorithm{
+			{AlgorithmType: modelapi.ALG_AUTO},
+			{AlgorithmType: modelapi.ALG_GENETIC, MaxIterations: 10, NumChildren: 10, NumParents: 10},
+		},
+	}
+	resolved := alg.Resolve(request)
+	assert.NotSame(alg, resolved)
+	assert.Equal(modelapi.ALG_AUTO, alg.Stages[0].AlgorithmType)
+	assert.Equal(modelapi.ALG_PIPELINE, resolved.Stages[0].AlgorithmType)
+	assert.Same(alg.Stages[1], resolved.Stages[1])
+
+	alg = &modelapi.Algorithm{
+		AlgorithmType:      modelapi.ALG_GENETIC,
+		MaxIterations:      10,
+		NumChildren:        10,
+		NumParents:         10,
+		PrecursorAlgorithm: &modelapi.Algorithm{AlgorithmType: modelapi.ALG_AUTO},
+	}
+	resolved = alg.Resolve(request)
+	assert.NotSame(alg, resolved)
+	assert.Equal(modelapi.ALG_AUTO, alg.PrecursorAlgorithm.AlgorithmType)
+	assert.Equal(modelapi.ALG_PIPELINE, resolved.PrecursorAlgorithm.AlgorithmType)
+	assert.Len(resolved.Stages, 0)
+
+	alg = &modelapi.Algorithm{
+		AlgorithmType: modelapi.ALG_ITERATED_LOCAL_SEARCH,
+		Improver:      &modelapi.Algorithm{AlgorithmType: modelapi.ALG_AUTO},
+		MaxIterations: 10,
+	}
+	resolved = alg.Resolve(request)
+	assert.NotSame(alg, resolved)
+	assert.Equal(modelapi.ALG_AUTO, alg.Improver.AlgorithmType)
+	assert.Equal(modelapi.ALG_PIPELINE, resolved.Improver.AlgorithmType)
+}
+
+func TestContainsAuto(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True((&modelapi.Algorithm{AlgorithmType: modelapi.ALG_AUTO}).ContainsAuto())
+	assert.False((&modelapi.Algorithm{AlgorithmType: modelapi.ALG_SAVINGS}).ContainsAuto())
+	assert.True((&modelapi.Algorithm{AlgorithmType: modelapi.ALG_TWO_OPT, PrecursorAlgorithm: &modelapi.Algorithm{AlgorithmType: modelapi.ALG_AUTO}}).ContainsAuto())
+	assert.True((&modelapi.Algorithm{AlgorithmType: modelapi.ALG_ITERATED_LOCAL_SEARCH, Improver: &modelapi.Algorithm{AlgorithmType: modelapi.ALG_AUTO}}).ContainsAuto())
+	assert.True((&modelapi.Algorithm{
+		AlgorithmType: modelapi.ALG_PIPELINE,
+		Stages: []*modelapi.Algorithm{
+			{AlgorithmType: modelapi.ALG_SAVINGS},
+			{AlgorithmType: modelapi.ALG_AUTO},
+		},
+	}).ContainsAuto())
+	assert.False((&modelapi.Algorithm{
+		AlgorithmType: modelapi.ALG_PIPELINE,
+		Stages: []*modelapi.Algorithm{
+			{AlgorithmType: modelapi.ALG_SAVINGS},
+			{AlgorithmType: modelapi.ALG_TWO_OPT},
+		},
+	}).ContainsAuto())
+}
+
+func TestValidateHubIndex(t *testing.T) {
+	assert := assert.New(t)
+
+	request := modelapi.ToApiFrom2D(model2d.GenerateVertices(10))
+
+	hubIndex := 9
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_SAVINGS}
+	assert.Nil(alg.ValidateHubIndex(request))
+	alg.HubIndex = &hubIndex
+	assert.Nil(alg.ValidateHubIndex(request))
+
+	hubIndex = 10
+	assert.EqualError(alg.ValidateHubIndex(request), "hubIndex must be the index of a point in the request, found 10 for 10 points")
+
+	// Only SAVINGS algorithms use the HubIndex.
+	assert.Nil((&modelapi.Algorithm{AlgorithmType: modelapi.ALG_TWO_OPT, HubIndex: &hubIndex}).ValidateHubIndex(request))
+
+	// Nested algorithms are also validated.
+	assert.NotNil((&modelapi.Algorithm{AlgorithmType: modelapi.ALG_TWO_OPT, PrecursorAlgorithm: alg}).ValidateHubIndex(request))
+	assert.NotNil((&modelapi.Algorithm{AlgorithmType: modelapi.ALG_ITERATED_LOCAL_SEARCH, Improver: alg}).ValidateHubIndex(request))
+	assert.NotNil((&modelapi.Algorithm{AlgorithmType: modelapi.ALG_PIPELINE, Stages: []*modelapi.Algorithm{alg, {AlgorithmType: modelapi.ALG_TWO_OPT}}}).ValidateHubIndex(request))
+}
+
+func TestValidateNumPoints(t *testing.T) {
+	assert := assert.New(t)
+
+	request := modelapi.ToApiFrom2D(model2d.GenerateVertices(circuit.ChristofidesMaxVertices))
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_CHRISTOFIDES}
+	assert.Nil(alg.ValidateNumPoints(request))
+
+	request = modelapi.ToApiFrom2D(model2d.GenerateVertices(circuit.ChristofidesMaxVertices + 1))
+	assert.EqualError(alg.ValidateNumPoints(request), "CHRISTOFIDES supports at most 2000 points, found 2001")
+	assert.Nil((&modelapi.Algorithm{AlgorithmType: modelapi.ALG_GREEDY_EDGE}).ValidateNumPoints(request))
+	assert.NotNil((&modelapi.Algorithm{AlgorithmType: modelapi.ALG_TWO_OPT, PrecursorAlgorithm: alg}).ValidateNumPoints(request))
+	assert.NotNil((&modelapi.Algorithm{AlgorithmType: modelapi.ALG_ITERATED_LOCAL_SEARCH, Improver: alg}).ValidateNumPoints(request))
+	assert.NotNil((&modelapi.Algorithm{AlgorithmType: modelapi.ALG_PIPELINE, Stages: []*modelapi.Algorithm{alg, {AlgorithmType: modelapi.ALG_TWO_OPT}}}).ValidateNumPoints(request))
+
+	// AUTO does not select CHRISTOFIDES, so it can be used for any number of points.
+	assert.Nil((&modelapi.Algorithm{AlgorithmType: modelapi.ALG_AUTO}).Resolve(request).ValidateNumPoints(request))
+}
+
+func TestResolve_ShouldResolveSavingsHub(t *testing.T) {
+	assert := assert.New(t)
+
+	request := modelapi.ToApiFrom2D(model2d.GenerateVertices(10))
+	vertices := request.To2D()
+
+	alg := &modelapi.Algorithm{AlgorithmType: modelapi.ALG_SAVINGS}
+	assert.Same(alg, alg.Resolve(request))
+
+	// The vertices are sorted when they are deduplicated, so the hub is found by its position rather than its index.
+	hubIndex := 6
+	alg.HubIndex = &hubIndex
+	resolved := alg.Resolve(request)
+	assert.NotSame(alg, resolved)
+	hub := resolved.CreateSavings(vertices, model2d.BuildPerimiter).(*circuit.Savings).GetHub().(*model2d.Vertex2D)
+	assert.Equal(*request.Points2D[6].X, hub.X)
+	assert.Equal(*request.Points2D[6].Y, hub.Y)
+
+	// An index outside of the request's points uses the default hub, although the API rejects such requests (see ValidateHubIndex).
+	hubIndex = 10
+	resolved = alg.Resolve(request)
+	assert.Same(alg, resolved)
+	assert.Equal(circuit.NewSavings(vertices, nil).GetHub(), resolved.CreateSavings(vertices, model2d.BuildPerimiter).(*circuit.Savings).GetHub())
+
+	requestGraph := &modelapi.TspRequest{
+		PointsGraph: []*modelapi.PointGraph{
+			{Id: "a", Neighbors: []modelapi.PointGraphNeighbor{{Id: "b", Distance: 1}, {Id: "c", Distance: 2}}},
+			{Id: "b", Neighbors: []modelapi.PointGraphNeighbor{{Id: "a", Distance: 1}, {Id: "c", Distance: 1}}},
+			{Id: "c", Neighbors: []modelapi.PointGraphNeighbor{{Id: "a", Distance: 2}, {Id: "b", Distance: 1}}},
+		},
+	}
+	g := requestGraph.ToGraph()
+	defer g.Delete()
+	hubIndex = 2
+	hubGraph := alg.Resolve(requestGraph).CreateSavings(graph.ToCircuitVertexArray(g.GetVertices()), nil).(*circuit.Savings).GetHub()
+	assert.Equal("c", hubGraph.(*graph.GraphVertex).GetId())
+}
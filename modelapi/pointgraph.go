@@ -1,6 +1,9 @@
 package modelapi
 
-import "github.com/heustis/tsp-solver-go/graph"
+import (
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+)
 
 // PointGraph is the API representation of a single point in a graph.
 // It references its neighbors by name, in an array, to avoid circular references and have consistent field names in its JSON representation.
@@ -60,23 +63,39 @@ func (api *TspRequest) ToGraph() *graph.Graph {
 // ToApiFromGraph converts a graph into an API response.
 func ToApiFromGraph(g *graph.Graph) *TspRequest {
 	api := &TspRequest{
-		PointsGraph: []*PointGraph{},
+		PointsGraph: make([]*PointGraph, 0, len(g.GetVertices())),
 	}
 
 	for _, v := range g.GetVertices() {
-		vApi := &PointGraph{
-			Id:        v.GetId(),
-			Neighbors: make([]PointGraphNeighbor, 0, len(v.GetAdjacentVertices())),
-		}
+		api.PointsGraph = append(api.PointsGraph, toApiFromGraphVertex(v))
+	}
+	return api
+}
 
-		for adj, distance := range v.GetAdjacentVertices() {
-			vApi.Neighbors = append(vApi.Neighbors, PointGraphNeighbor{
-				Id:       adj.GetId(),
-				Distance: distance,
-			})
-		}
+// ToApiFromGraphVertices converts an ordered array of graph vertices (e.g. a completed circuit) into an API response, preserving the order of the vertices.
+// This must be called prior to deleting the graph, since deleting the graph removes each vertex's neighbors.
+func ToApiFromGraphVertices(vertices []model.CircuitVertex) *TspRequest {
+	api := &TspRequest{
+		PointsGraph: make([]*PointGraph, len(vertices)),
+	}
 
-		api.PointsGraph = append(api.PointsGraph, vApi)
+	for i, v := range vertices {
+		api.PointsGraph[i] = toApiFromGraphVertex(v.(*graph.GraphVertex))
 	}
 	return api
 }
+
+func toApiFromGraphVertex(v *graph.GraphVertex) *PointGraph {
+	vApi := &PointGraph{
+		Id:        v.GetId(),
+		Neighbors: make([]PointGraphNeighbor, 0, len(v.GetAdjacentVertices())),
+	}
+
+	for adj, distance := range v.GetAdjacentVertices() {
+		vApi.Neighbors = append(vApi.Neighbors, PointGraphNeighbor{
+			Id:       adj.GetId(),
+			Distance: distance,
+		})
+	}
+	return vApi
+}
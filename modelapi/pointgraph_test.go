@@ -122,3 +122,43 @@ func TestToApiGraph_ShouldPreserveGraphData(t *testing.T) {
 		}
 	}
 }
+
+func TestToApiFromGraphVertices_ShouldPreserveOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    4,
+		MinEdges:    2,
+		NumVertices: uint32(10),
+	}
+
+	g := gen.Create()
+	assert.NotNil(g)
+	defer g.Delete()
+
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+	vertices[0], vertices[5] = vertices[5], vertices[0]
+	vertices[2], vertices[7] = vertices[7], vertices[2]
+
+	api := modelapi.ToApiFromGraphVertices(vertices)
+	assert.NotNil(api)
+	assert.Len(api.PointsGraph, len(vertices))
+	assert.Len(api.Points2D, 0)
+	assert.Len(api.Points3D, 0)
+
+	for i, v := range vertices {
+		gv := v.(*graph.GraphVertex)
+		assert.Equal(gv.GetId(), api.PointsGraph[i].Id)
+		assert.Len(api.PointsGraph[i].Neighbors, len(gv.GetAdjacentVertices()))
+		for _, n := range api.PointsGraph[i].Neighbors {
+			found := false
+			for adj, dist := range gv.GetAdjacentVertices() {
+				if adj.GetId() == n.Id {
+					found = true
+					assert.Equal(dist, n.Distance)
+				}
+			}
+			assert.True(found)
+		}
+	}
+}
@@ -7,4 +7,8 @@ type TspRequest struct {
 	Points2D    []*Point2D    `json:"points2d,omitempty" validate:"required_without_all=Points3D PointsGraph,excluded_with=Points3D PointsGraph,isdefault|min=3,dive,required"`
 	Points3D    []*Point3D    `json:"points3d,omitempty" validate:"required_without_all=Points2D PointsGraph,excluded_with=Points2D PointsGraph,isdefault|min=3,dive,required"`
 	PointsGraph []*PointGraph `json:"pointsGraph,omitempty" validate:"required_without_all=Points2D Points3D,excluded_with=Points2D Points3D,isdefault|min=3,dive,required"`
+	// TargetLength, if greater than 0, stops the remaining algorithms once any algorithm produces a circuit at least this short.
+	TargetLength float64 `json:"targetLength,omitempty" validate:"min=0"`
+	// TimeLimitMillis, if greater than 0, is the overall time that the request's algorithms may run for.
+	TimeLimitMillis int64 `json:"timeLimitMillis,omitempty" validate:"min=0"`
 }
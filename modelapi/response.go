@@ -0,0 +1,29 @@
+package modelapi
+
+// TspResponse is the API representation of the best computed circuit through the points in a TspRequest.
+// Only the array matching the type of points in the request is populated, and its points are ordered so that travelling from index-0 to index-1, ..., and finally from index-n to index-0 produces the computed circuit.
+type TspResponse struct {
+	Points2D    []*Point2D    `json:"points2d,omitempty"`
+	Points3D    []*Point3D    `json:"points3d,omitempty"`
+	PointsGraph []*PointGraph `json:"pointsGraph,omitempty"`
+	// Results contains the outcome of each algorithm in the request, in the same order as the request's algorithms.
+	Results []*AlgorithmResult `json:"results,omitempty"`
+}
+
+// AlgorithmResult is the API representation of the outcome of one of the algorithms in a TspRequest.
+type AlgorithmResult struct {
+	AlgorithmType AlgorithmType `json:"algorithmType"`
+	// Best is true for the algorithm whose circuit was returned in the response.
+	Best           bool    `json:"best,omitempty"`
+	DurationMillis float64 `json:"durationMillis"`
+	// Error describes why the algorithm did not produce a circuit, e.g. because the time limit ended before it started.
+	Error  string  `json:"error,omitempty"`
+	Length float64 `json:"length,omitempty"`
+	// ResolvedAlgorithm is the configuration that was computed, if the requested algorithm was, or contained, an AUTO algorithm.
+	ResolvedAlgorithm *Algorithm `json:"resolvedAlgorithm,omitempty"`
+	// StageLengths contains the length of the circuit produced by each stage of a PIPELINE, or by the precursor and then the algorithm itself, in the order they were computed.
+	// If the algorithm was truncated, this only contains the stages that were completed.
+	StageLengths []float64 `json:"stageLengths,omitempty"`
+	// Truncated is true if the algorithm was stopped before it completed, so its remaining points were attached by cheapest insertion.
+	Truncated bool `json:"truncated,omitempty"`
+}
@@ -0,0 +1,37 @@
+package mst
+
+import (
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/spatial"
+)
+
+// FindAllEdges returns every pair of vertices as a candidate edge, from the lower index to the higher index, using the distance in that direction.
+func FindAllEdges(vertices []model.CircuitVertex) []Edge {
+	edges := make([]Edge, 0, len(vertices)*(len(vertices)-1)/2)
+	for i, a := range vertices {
+		for j := i + 1; j < len(vertices); j++ {
+			edges = append(edges, Edge{From: i, To: j, Length: a.DistanceTo(vertices[j])})
+		}
+	}
+	return edges
+}
+
+// FindNeighborEdges returns the edges from each vertex to its nearest neighbors, using the supplied k-d tree, which must contain the vertices.
+// Each edge is from the lower index to the higher index. An edge may be included twice, if each vertex is one of the other's nearest neighbors.
+func FindNeighborEdges(vertices []model.CircuitVertex, tree *spatial.KDTree, numNeighbors int) []Edge {
+	indices := make(map[model.CircuitVertex]int, len(vertices))
+	for i, v := range vertices {
+		indices[v] = i
+	}
+	edges := make([]Edge, 0, len(vertices)*numNeighbors)
+	for i, v := range vertices {
+		for _, neighbor := range tree.KNearest(v, numNeighbors+1) {
+			if j := indices[neighbor]; j > i {
+				edges = append(edges, Edge{From: i, To: j, Length: v.DistanceTo(neighbor)})
+			} else if j < i {
+				edges = append(edges, Edge{From: j, To: i, Length: neighbor.DistanceTo(v)})
+			}
+		}
+	}
+	return edges
+}
@@ -0,0 +1,55 @@
+package mst_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/mst"
+	"github.com/heustis/tsp-solver-go/spatial"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindAllEdges(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(3, 4),
+		model2d.NewVertex2D(0, 1),
+	}
+	assert.Equal([]mst.Edge{
+		{From: 0, To: 1, Length: 5},
+		{From: 0, To: 2, Length: 1},
+		{From: 1, To: 2, Length: vertices[1].DistanceTo(vertices[2])},
+	}, mst.FindAllEdges(vertices))
+	assert.Len(mst.FindAllEdges([]model.CircuitVertex{}), 0)
+}
+
+func TestFindNeighborEdges(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(1, 0),
+		model2d.NewVertex2D(12, 0),
+	}
+	tree, okay := spatial.NewKDTree(vertices)
+	assert.True(okay)
+
+	// Each vertex is its own nearest neighbor, which is skipped, so each vertex contributes one edge, and mutual neighbors are included twice.
+	assert.Equal([]mst.Edge{
+		{From: 0, To: 2, Length: 1},
+		{From: 1, To: 3, Length: 2},
+		{From: 0, To: 2, Length: 1},
+		{From: 1, To: 3, Length: 2},
+	}, mst.FindNeighborEdges(vertices, tree, 1))
+
+	edges := mst.FindNeighborEdges(vertices, tree, 3)
+	assert.Len(edges, 12)
+	for _, e := range edges {
+		assert.Less(e.From, e.To)
+		assert.InDelta(vertices[e.From].DistanceTo(vertices[e.To]), e.Length, model.Threshold)
+	}
+}
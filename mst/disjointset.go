@@ -0,0 +1,48 @@
+package mst
+
+// DisjointSet is a [union-find](https://en.wikipedia.org/wiki/Disjoint-set_data_structure) structure over the indices 0..n-1,
+// which tracks which vertices are connected as edges are added (e.g. by Kruskal's algorithm or the greedy edge construction).
+// It uses path compression and union by size, so each operation is effectively O(1).
+type DisjointSet struct {
+	parents []int
+	sizes   []int
+}
+
+// NewDisjointSet creates a DisjointSet in which each of the supplied number of indices is in its own set.
+func NewDisjointSet(size int) *DisjointSet {
+	d := &DisjointSet{
+		parents: make([]int, size),
+		sizes:   make([]int, size),
+	}
+	for i := range d.parents {
+		d.parents[i] = i
+		d.sizes[i] = 1
+	}
+	return d
+}
+
+// Find returns the representative index of the set containing the supplied index.
+func (d *DisjointSet) Find(index int) int {
+	root := index
+	for d.parents[root] != root {
+		root = d.parents[root]
+	}
+	for d.parents[index] != root {
+		d.parents[index], index = root, d.parents[index]
+	}
+	return root
+}
+
+// Union merges the sets containing the supplied indices. It returns false if they were already in the same set.
+func (d *DisjointSet) Union(a int, b int) bool {
+	rootA, rootB := d.Find(a), d.Find(b)
+	if rootA == rootB {
+		return false
+	}
+	if d.sizes[rootA] < d.sizes[rootB] {
+		rootA, rootB = rootB, rootA
+	}
+	d.parents[rootB] = rootA
+	d.sizes[rootA] += d.sizes[rootB]
+	return true
+}
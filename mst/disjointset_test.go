@@ -0,0 +1,31 @@
+package mst_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/mst"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisjointSet(t *testing.T) {
+	assert := assert.New(t)
+
+	d := mst.NewDisjointSet(6)
+	for i := 0; i < 6; i++ {
+		assert.Equal(i, d.Find(i))
+	}
+
+	assert.True(d.Union(0, 1))
+	assert.True(d.Union(2, 3))
+	assert.False(d.Union(1, 0))
+	assert.Equal(d.Find(0), d.Find(1))
+	assert.NotEqual(d.Find(1), d.Find(2))
+
+	assert.True(d.Union(1, 3))
+	assert.False(d.Union(0, 2))
+	for i := 1; i < 4; i++ {
+		assert.Equal(d.Find(0), d.Find(i))
+	}
+	assert.NotEqual(d.Find(0), d.Find(4))
+	assert.NotEqual(d.Find(4), d.Find(5))
+}
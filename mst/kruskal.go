@@ -0,0 +1,106 @@
+package mst
+
+import (
+	"sort"
+
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/spatial"
+)
+
+// NewKruskal creates a spanning tree of 2D or 3D vertices with [Kruskal's algorithm](https://en.wikipedia.org/wiki/Kruskal%27s_algorithm),
+// using the edges from each vertex to its nearest neighbors as candidates, which is O(n*log(n)) rather than the O(n^2) of NewPrim.
+// If the candidates do not connect every vertex (e.g. because the vertices are in distant clusters), the number of neighbors is doubled until they do.
+//
+// The tree is a minimum spanning tree unless one of the minimum spanning tree's edges is not a candidate, which is rare with 10 or more neighbors;
+// when it does occur, the tree is only slightly longer than the minimum spanning tree.
+// If the vertices are not all 2D or all 3D vertices (e.g. graphs), or the number of neighbors is less than 1, this returns NewPrim(vertices) instead.
+// Duplicate references to the same vertex are ignored.
+func NewKruskal(vertices []model.CircuitVertex, numNeighbors int) *Tree {
+	unique := deduplicate(vertices)
+	tree, isSpatial := spatial.NewKDTree(unique)
+	if !isSpatial || numNeighbors < 1 {
+		return NewPrim(unique)
+	}
+
+	for {
+		if t := newKruskalFromCandidates(unique, FindNeighborEdges(unique, tree, numNeighbors)); len(t.edges) >= len(unique)-1 {
+			return t
+		}
+		numNeighbors *= 2
+	}
+}
+
+// NewGraphKruskal creates a minimum spanning tree of graph vertices with Kruskal's algorithm, using the edges between adjacent vertices as candidates.
+// This is O(e*log(e)) for e edges, rather than NewPrim's O(n^2) shortest path computations, and produces a tree of the same length,
+// since the shortest path between any pair of vertices consists of edges between adjacent vertices.
+// Edges are treated as undirected: if the vertices are adjacent in both directions with different distances, the shorter distance silently replaces the longer one,
+// so for asymmetric graphs the tree (and its length) does not reflect the distances in the other direction.
+//
+// If the vertices are not all graph vertices, or the edges between them do not connect every vertex (e.g. if only some of the graph's vertices are supplied), this returns NewPrim(vertices) instead.
+// Duplicate references to the same vertex are ignored.
+func NewGraphKruskal(vertices []model.CircuitVertex) *Tree {
+	unique := deduplicate(vertices)
+	indices := make(map[*graph.GraphVertex]int, len(unique))
+	for i, v := range unique {
+		graphVertex, okay := v.(*graph.GraphVertex)
+		if !okay {
+			return NewPrim(unique)
+		}
+		indices[graphVertex] = i
+	}
+
+	type pair struct{ from, to int }
+	lengths := make(map[pair]float64)
+	for graphVertex, i := range indices {
+		for adjacent, distance := range graphVertex.GetAdjacentVertices() {
+			j, okay := indices[adjacent]
+			if !okay || i == j {
+				continue
+			}
+			key := pair{i, j}
+			if j < i {
+				key = pair{j, i}
+			}
+			if existing, isDuplicate := lengths[key]; !isDuplicate || distance < existing {
+				lengths[key] = distance
+			}
+		}
+	}
+	candidates := make([]Edge, 0, len(lengths))
+	for key, length := range lengths {
+		candidates = append(candidates, Edge{From: key.from, To: key.to, Length: length})
+	}
+	// The candidates are collected from maps, so they are sorted by index first, to ensure that ties in length are resolved consistently.
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].From < candidates[j].From || (candidates[i].From == candidates[j].From && candidates[i].To < candidates[j].To)
+	})
+
+	if t := newKruskalFromCandidates(unique, candidates); len(t.edges) >= len(unique)-1 {
+		return t
+	}
+	return NewPrim(unique)
+}
+
+// newKruskalFromCandidates adds the candidate edges to the tree, shortest first, skipping any edge that would create a loop.
+// If the candidates do not connect every vertex, the result is a spanning forest.
+func newKruskalFromCandidates(vertices []model.CircuitVertex, candidates []Edge) *Tree {
+	t := &Tree{
+		edges:    make([]Edge, 0, len(vertices)),
+		vertices: vertices,
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Length < candidates[j].Length
+	})
+	components := NewDisjointSet(len(vertices))
+	for _, e := range candidates {
+		if len(t.edges) >= len(vertices)-1 {
+			break
+		}
+		if components.Union(e.From, e.To) {
+			t.edges = append(t.edges, e)
+			t.length += e.Length
+		}
+	}
+	return t
+}
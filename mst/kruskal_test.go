@@ -0,0 +1,130 @@
+package mst_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/mst"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKruskal(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(1, 0),
+		model2d.NewVertex2D(1, 5),
+		model2d.NewVertex2D(9, 1),
+	}
+	tree := mst.NewKruskal(vertices, 2)
+	assert.Equal(vertices, tree.GetVertices())
+	// The edges are added from shortest to longest.
+	assert.Equal([]mst.Edge{
+		{From: 0, To: 2, Length: 1},
+		{From: 1, To: 4, Length: vertices[1].DistanceTo(vertices[4])},
+		{From: 2, To: 3, Length: 5},
+		{From: 2, To: 4, Length: vertices[2].DistanceTo(vertices[4])},
+	}, tree.GetEdges())
+	assert.InDelta(mst.NewPrim(vertices).GetLength(), tree.GetLength(), model.Threshold)
+}
+
+func TestNewKruskal_ShouldMatchPrim(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, vertices := range [][]model.CircuitVertex{
+		model2d.DeduplicateVertices(model2d.GenerateVertices(500)),
+		model3d.GenerateVertices(500),
+	} {
+		prim := mst.NewPrim(vertices)
+		kruskal := mst.NewKruskal(vertices, mst.KruskalDefaultNeighbors)
+		assert.Len(kruskal.GetEdges(), len(vertices)-1)
+		assert.InDelta(prim.GetLength(), kruskal.GetLength(), 1e-6*prim.GetLength())
+	}
+}
+
+func TestNewKruskal_ShouldConnectClusters(t *testing.T) {
+	assert := assert.New(t)
+
+	// Each vertex's 3 nearest neighbors are in its own cluster, so the neighbors must be increased to connect the clusters.
+	vertices := []model.CircuitVertex{}
+	for _, offset := range []float64{0, 1000, 5000} {
+		for i := 0; i < 5; i++ {
+			vertices = append(vertices, model2d.NewVertex2D(offset+float64(i), float64(i%2)))
+		}
+	}
+	tree := mst.NewKruskal(vertices, 3)
+	assert.Len(tree.GetEdges(), len(vertices)-1)
+	assert.InDelta(mst.NewPrim(vertices).GetLength(), tree.GetLength(), model.Threshold)
+}
+
+func TestNewKruskal_ShouldUsePrimForGraphs(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    5,
+		MinEdges:    2,
+		NumVertices: 20,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+
+	assert.Equal(mst.NewPrim(vertices).GetEdges(), mst.NewKruskal(vertices, 5).GetEdges())
+}
+
+func TestNewGraphKruskal(t *testing.T) {
+	assert := assert.New(t)
+
+	a, b, c, d := graph.NewGraphVertex("a"), graph.NewGraphVertex("b"), graph.NewGraphVertex("c"), graph.NewGraphVertex("d")
+	a.AddAdjacentVertex(b, 1)
+	a.AddAdjacentVertex(c, 10)
+	b.AddAdjacentVertex(a, 2)
+	b.AddAdjacentVertex(c, 3)
+	c.AddAdjacentVertex(d, 4)
+	d.AddAdjacentVertex(a, 3.5)
+	g := graph.NewGraph([]*graph.GraphVertex{a, b, c, d})
+	defer g.Delete()
+	vertices := []model.CircuitVertex{a, b, c, d}
+
+	tree := mst.NewGraphKruskal(vertices)
+	// a-b uses the shorter of its two directions, and c-d is rejected since a, b, c and d are already connected.
+	assert.Equal([]mst.Edge{
+		{From: 0, To: 1, Length: 1},
+		{From: 1, To: 2, Length: 3},
+		{From: 0, To: 3, Length: 3.5},
+	}, tree.GetEdges())
+	assert.InDelta(7.5, tree.GetLength(), model.Threshold)
+
+	// Without d, the only edges from c lead to d, so the edges between the supplied vertices still connect them, via b.
+	tree = mst.NewGraphKruskal([]model.CircuitVertex{c, a, b})
+	assert.Equal([]mst.Edge{
+		{From: 1, To: 2, Length: 1},
+		{From: 0, To: 2, Length: 3},
+	}, tree.GetEdges())
+
+	// If the edges between the supplied vertices do not connect them, the shortest paths through the rest of the graph are used instead.
+	tree = mst.NewGraphKruskal([]model.CircuitVertex{d, b})
+	assert.Equal(mst.NewPrim([]model.CircuitVertex{d, b}).GetEdges(), tree.GetEdges())
+}
+
+func TestNewGraphKruskal_ShouldMatchPrim(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    6,
+		MinEdges:    2,
+		NumVertices: 40,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+
+	tree := mst.NewGraphKruskal(vertices)
+	assert.Len(tree.GetEdges(), len(vertices)-1)
+	assert.InDelta(mst.NewPrim(vertices).GetLength(), tree.GetLength(), model.Threshold)
+}
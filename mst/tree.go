@@ -0,0 +1,136 @@
+// Package mst contains minimum spanning tree algorithms, which are used by tree-based constructions (e.g. Christofides) and provide lower bounds on the length of a circuit.
+package mst
+
+import (
+	"math"
+
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// KruskalDefaultNeighbors is the number of nearest neighbors of each vertex that NewTree uses as candidate edges, for large sets of 2D and 3D vertices.
+const KruskalDefaultNeighbors = 10
+
+// PrimMaxVertices is the largest number of 2D or 3D vertices for which NewTree uses Prim's algorithm, rather than Kruskal's algorithm over each vertex's nearest neighbors.
+const PrimMaxVertices = 1000
+
+// Edge is an edge in a spanning tree, between the vertices at the indices From and To of the tree's vertices.
+// For trees built from a root, From is the vertex that was already in the tree when the edge was added.
+type Edge struct {
+	From   int
+	To     int
+	Length float64
+}
+
+// Tree is a spanning tree over a set of vertices, where each vertex is referenced by its index in GetVertices.
+type Tree struct {
+	edges    []Edge
+	length   float64
+	vertices []model.CircuitVertex
+}
+
+// NewTree creates a minimum spanning tree using the algorithm best suited to the supplied vertices:
+// 1. Graph vertices use NewGraphKruskal, which only considers the edges between adjacent vertices, using the shorter direction of each edge.
+// 2. Up to PrimMaxVertices 2D or 3D vertices use NewPrim.
+// 3. Larger sets of 2D or 3D vertices use NewKruskal, with KruskalDefaultNeighbors nearest neighbors of each vertex as candidates.
+// Duplicate references to the same vertex are ignored.
+func NewTree(vertices []model.CircuitVertex) *Tree {
+	if len(vertices) > 0 {
+		if _, isGraph := vertices[0].(*graph.GraphVertex); isGraph {
+			return NewGraphKruskal(vertices)
+		}
+	}
+	if len(vertices) > PrimMaxVertices {
+		return NewKruskal(vertices, KruskalDefaultNeighbors)
+	}
+	return NewPrim(vertices)
+}
+
+// NewPrim creates a minimum spanning tree with [Prim's algorithm](https://en.wikipedia.org/wiki/Prim%27s_algorithm), starting from the first vertex.
+// This checks the distance between every pair of vertices, so it is O(n^2), which is optimal for dense (complete) graphs, and works with any vertex that implements DistanceTo.
+// If the distances are asymmetric, each edge uses the distance from the vertex in the tree to the vertex being added to the tree.
+// Duplicate references to the same vertex are ignored.
+func NewPrim(vertices []model.CircuitVertex) *Tree {
+	t := &Tree{
+		vertices: deduplicate(vertices),
+	}
+	numVertices := len(t.vertices)
+	if numVertices == 0 {
+		return t
+	}
+	t.edges = make([]Edge, 0, numVertices-1)
+
+	inTree := make([]bool, numVertices)
+	closestDistance := make([]float64, numVertices)
+	closestParent := make([]int, numVertices)
+	for i := range closestDistance {
+		closestDistance[i] = math.MaxFloat64
+		closestParent[i] = -1
+	}
+
+	current := 0
+	for {
+		inTree[current] = true
+		next := -1
+		for i, v := range t.vertices {
+			if inTree[i] {
+				continue
+			}
+			if distance := t.vertices[current].DistanceTo(v); distance < closestDistance[i] {
+				closestDistance[i] = distance
+				closestParent[i] = current
+			}
+			if next == -1 || closestDistance[i] < closestDistance[next] {
+				next = i
+			}
+		}
+		if next == -1 {
+			return t
+		}
+		t.edges = append(t.edges, Edge{
+			From:   closestParent[next],
+			To:     next,
+			Length: closestDistance[next],
+		})
+		t.length += closestDistance[next]
+		current = next
+	}
+}
+
+// GetAdjacency returns the indices of the vertices adjacent to each vertex in the tree.
+func (t *Tree) GetAdjacency() [][]int {
+	adjacency := make([][]int, len(t.vertices))
+	for _, e := range t.edges {
+		adjacency[e.From] = append(adjacency[e.From], e.To)
+		adjacency[e.To] = append(adjacency[e.To], e.From)
+	}
+	return adjacency
+}
+
+// GetEdges returns the edges in the tree, in the order they were added to it.
+func (t *Tree) GetEdges() []Edge {
+	return t.edges
+}
+
+// GetLength returns the total length of the edges in the tree.
+// For a minimum spanning tree this is a lower bound on the length of the optimal circuit, when distances are symmetric.
+func (t *Tree) GetLength() float64 {
+	return t.length
+}
+
+// GetVertices returns the vertices in the tree; the edges reference vertices by their index in this array.
+func (t *Tree) GetVertices() []model.CircuitVertex {
+	return t.vertices
+}
+
+func deduplicate(vertices []model.CircuitVertex) []model.CircuitVertex {
+	unique := make([]model.CircuitVertex, 0, len(vertices))
+	seen := make(map[model.CircuitVertex]bool, len(vertices))
+	for _, v := range vertices {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,116 @@
+package mst_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/mst"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPrim(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(1, 0),
+		model2d.NewVertex2D(1, 5),
+		model2d.NewVertex2D(9, 1),
+	}
+	tree := mst.NewPrim(vertices)
+	assert.Equal(vertices, tree.GetVertices())
+	assert.Equal([]mst.Edge{
+		{From: 0, To: 2, Length: 1},
+		{From: 2, To: 3, Length: 5},
+		{From: 2, To: 4, Length: vertices[2].DistanceTo(vertices[4])},
+		{From: 4, To: 1, Length: vertices[4].DistanceTo(vertices[1])},
+	}, tree.GetEdges())
+	assert.InDelta(6+vertices[2].DistanceTo(vertices[4])+vertices[4].DistanceTo(vertices[1]), tree.GetLength(), model.Threshold)
+	assert.Equal([][]int{{2}, {4}, {0, 3, 4}, {2}, {2, 1}}, tree.GetAdjacency())
+}
+
+func TestNewPrim_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	tree := mst.NewPrim([]model.CircuitVertex{})
+	assert.Len(tree.GetVertices(), 0)
+	assert.Len(tree.GetEdges(), 0)
+	assert.Equal(0.0, tree.GetLength())
+
+	v := model2d.NewVertex2D(1, 2)
+	tree = mst.NewPrim([]model.CircuitVertex{v, v})
+	assert.Equal([]model.CircuitVertex{v}, tree.GetVertices())
+	assert.Len(tree.GetEdges(), 0)
+	assert.Equal([][]int{nil}, tree.GetAdjacency())
+}
+
+func TestNewPrim_LowerBound(t *testing.T) {
+	assert := assert.New(t)
+
+	// A minimum spanning tree is no longer than the optimal circuit, since removing any edge from the circuit produces a spanning tree.
+	for i := 0; i < 5; i++ {
+		vertices := model3d.GenerateVertices(8)
+		tree := mst.NewPrim(vertices)
+		assert.Len(tree.GetEdges(), len(vertices)-1)
+		_, optimalLength := solver.FindShortestPathNPHeap(vertices)
+		assert.LessOrEqual(tree.GetLength(), optimalLength+model.Threshold)
+	}
+}
+
+func TestNewPrim_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    5,
+		MinEdges:    2,
+		NumVertices: 20,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	vertices := graph.ToCircuitVertexArray(g.GetVertices())
+
+	tree := mst.NewPrim(vertices)
+	assert.Len(tree.GetEdges(), len(vertices)-1)
+	// Every vertex is reachable from the first vertex.
+	visited := map[int]bool{0: true}
+	adjacency := tree.GetAdjacency()
+	for stack := []int{0}; len(stack) > 0; {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, next := range adjacency[current] {
+			if !visited[next] {
+				visited[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	assert.Len(visited, len(vertices))
+}
+
+func TestNewTree(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(mst.PrimMaxVertices + 200))
+	tree := mst.NewTree(vertices)
+	assert.Len(tree.GetEdges(), len(vertices)-1)
+	assert.Equal(mst.NewKruskal(vertices, mst.KruskalDefaultNeighbors).GetEdges(), tree.GetEdges())
+
+	vertices = vertices[:100]
+	assert.Equal(mst.NewPrim(vertices).GetEdges(), mst.NewTree(vertices).GetEdges())
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    5,
+		MinEdges:    2,
+		NumVertices: 20,
+	}
+	g := gen.Create()
+	defer g.Delete()
+	graphVertices := graph.ToCircuitVertexArray(g.GetVertices())
+	assert.Equal(mst.NewGraphKruskal(graphVertices).GetEdges(), mst.NewTree(graphVertices).GetEdges())
+	assert.Len(mst.NewTree([]model.CircuitVertex{}).GetEdges(), 0)
+}
@@ -0,0 +1,193 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/modelapi"
+)
+
+// validate is shared between requests, since a validator caches the structure of each type it validates and is safe for concurrent use.
+var validate = validator.New()
+
+// FindShortestPathApi validates the supplied request, computes a circuit through its points using each of the request's algorithms, and returns the shortest of those circuits.
+// If the request does not specify any algorithms, the ClosestGreedy algorithm is used.
+// An error is returned, rather than a panic, if the request is invalid or if every algorithm fails to process the points (e.g. a graph in which some points cannot reach the others).
+func FindShortestPathApi(request *modelapi.TspRequest) (response *modelapi.TspResponse, err error) {
+	return FindShortestPathApiContext(context.Background(), request)
+}
+
+// FindShortestPathApiContext behaves like FindShortestPathApi, but also stops computing circuits once the supplied context is done.
+// The request's algorithms are computed concurrently as a portfolio (see FindShortestPathPortfolio), limited by the request's TimeLimitMillis and TargetLength,
+// and the response reports the length and wall-clock time of each algorithm alongside the best circuit.
+func FindShortestPathApiContext(ctx context.Context, request *modelapi.TspRequest) (response *modelapi.TspResponse, err error) {
+	problem, err := newApiProblem(request)
+	if err != nil {
+		return nil, err
+	}
+	defer problem.delete()
+
+	// The algorithms in this package panic on unexpected data, convert those panics into errors for API clients.
+	defer func() {
+		if r := recover(); r != nil {
+			response = nil
+			err = fmt.Errorf("failed to compute circuit: %v", r)
+		}
+	}()
+
+	// Retain the circuit created for each algorithm, since the portfolio's results may wrap it (e.g. when it is truncated), and pipelines report the length of each of their stages.
+	circuits := make([]model.Circuit, len(problem.algorithms))
+	algorithms := make([]PortfolioAlgorithm, len(problem.algorithms))
+	for i, alg := range problem.algorithms {
+		index := i
+		circuitFunction := alg.GetCircuitFunction()
+		algorithms[i] = func() model.Circuit {
+			circuits[index] = circuitFunction(problem.copyVertices(), problem.perimeterBuilder)
+			return circuits[index]
+		}
+	}
+
+	best, results := FindShortestPathPortfolio(ctx, algorithms, PortfolioOptions{
+		TargetLength: request.TargetLength,
+		TimeBudget:   time.Duration(request.TimeLimitMillis) * time.Millisecond,
+	})
+	if best < 0 {
+		return nil, results[0].Err
+	}
+
+	response = problem.toResponse(results[best].Circuit.GetAttachedVertices())
+	response.Results = make([]*modelapi.AlgorithmResult, len(results))
+	for i, result := range results {
+		response.Results[i] = &modelapi.AlgorithmResult{
+			AlgorithmType:  problem.requested[i].AlgorithmType,
+			Best:           i == best,
+			DurationMillis: float64(result.Duration) / float64(time.Millisecond),
+			Length:         result.Length,
+			Truncated:      result.Truncated,
+		}
+		if result.Err != nil {
+			response.Results[i].Error = result.Err.Error()
+		}
+		// Resolve also copies SAVINGS algorithms to record their hub, but only AUTO selects a configuration that the caller did not request.
+		if problem.requested[i].ContainsAuto() {
+			response.Results[i].ResolvedAlgorithm = problem.algorithms[i]
+		}
+		if pipeline, isPipeline := circuits[i].(*circuit.Pipeline); isPipeline {
+			response.Results[i].StageLengths = pipeline.GetStageLengths()
+		}
+	}
+	return response, nil
+}
+
+// apiProblem contains the data, derived from an API request, that is needed to compute and return circuits in the format of the request.
+type apiProblem struct {
+	// algorithms are the algorithms to compute, which are the requested algorithms with any AUTO algorithms resolved.
+	algorithms       []*modelapi.Algorithm
+	g                *graph.Graph
+	perimeterBuilder model.PerimeterBuilder
+	requested        []*modelapi.Algorithm
+	vertices         []model.CircuitVertex
+}
+
+func newApiProblem(request *modelapi.TspRequest) (*apiProblem, error) {
+	if request == nil {
+		return nil, errors.New("request must not be nil")
+	}
+	if err := validate.Struct(request); err != nil {
+		return nil, err
+	}
+
+	problem := &apiProblem{
+		requested: request.Algorithms,
+	}
+	if len(problem.requested) == 0 {
+		problem.requested = []*modelapi.Algorithm{{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY}}
+	}
+	for _, alg := range problem.requested {
+		if err := alg.ValidateHubIndex(request); err != nil {
+			return nil, err
+		}
+		if err := alg.ValidateNumPoints(request); err != nil {
+			return nil, err
+		}
+	}
+	problem.algorithms = make([]*modelapi.Algorithm, len(problem.requested))
+	for i, alg := range problem.requested {
+		problem.algorithms[i] = alg.Resolve(request)
+	}
+
+	if len(request.Points2D) > 0 {
+		problem.vertices = request.To2D()
+		problem.perimeterBuilder = model2d.BuildPerimiter
+	} else if len(request.Points3D) > 0 {
+		problem.vertices = request.To3D()
+		problem.perimeterBuilder = model3d.BuildPerimiter
+	} else {
+		problem.g = request.ToGraph()
+		problem.vertices = graph.ToCircuitVertexArray(problem.g.GetVertices())
+		problem.perimeterBuilder = graph.BuildPerimiter
+		if err := problem.checkGraphIsConnected(); err != nil {
+			problem.delete()
+			return nil, err
+		}
+	}
+
+	if len(problem.vertices) < 3 {
+		problem.delete()
+		return nil, fmt.Errorf("request must contain at least 3 unique points, found %d", len(problem.vertices))
+	}
+	return problem, nil
+}
+
+// checkGraphIsConnected returns an error if any point in the graph cannot reach every other point, since a circuit through the graph would be impossible.
+func (p *apiProblem) checkGraphIsConnected() error {
+	for _, start := range p.g.GetVertices() {
+		paths := start.GetPaths()
+		for _, end := range p.g.GetVertices() {
+			if paths[end] == nil {
+				return fmt.Errorf("point %s cannot reach point %s", start.GetId(), end.GetId())
+			}
+		}
+	}
+	return nil
+}
+
+// copyVertices returns a copy of the problem's vertices, since some algorithms (e.g. SimulatedAnnealing) reorder the array they are supplied.
+func (p *apiProblem) copyVertices() []model.CircuitVertex {
+	vertices := make([]model.CircuitVertex, len(p.vertices))
+	copy(vertices, p.vertices)
+	return vertices
+}
+
+// delete cleans up the graph, if the request contained a graph.
+func (p *apiProblem) delete() {
+	if p.g != nil {
+		p.g.Delete()
+		p.g = nil
+	}
+}
+
+// toResponse converts the supplied circuit into the API format of the request.
+func (p *apiProblem) toResponse(circuit []model.CircuitVertex) *modelapi.TspResponse {
+	var points *modelapi.TspRequest
+	if p.g != nil {
+		points = modelapi.ToApiFromGraphVertices(circuit)
+	} else if _, is2D := p.vertices[0].(*model2d.Vertex2D); is2D {
+		points = modelapi.ToApiFrom2D(circuit)
+	} else {
+		points = modelapi.ToApiFrom3D(circuit)
+	}
+	return &modelapi.TspResponse{
+		Points2D:    points.Points2D,
+		Points3D:    points.Points3D,
+		PointsGraph: points.PointsGraph,
+	}
+}
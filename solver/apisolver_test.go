@@ -0,0 +1,256 @@
+package solver_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/modelapi"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindShortestPathApi_2D(t *testing.T) {
+	assert := assert.New(t)
+
+	requestJson := `{
+		"algorithms":[
+			{"algorithmType":"CLOSEST_GREEDY"},
+			{"algorithmType":"ANNEALING","maxIterations":10,"seed":1234},
+			{"algorithmType":"DISPARITY_GREEDY"}
+		],
+		"points2d":[{"x":0,"y":0},{"x":4,"y":4},{"x":4,"y":0},{"x":0,"y":4},{"x":2,"y":1},{"x":0,"y":0}]
+	}`
+	var request *modelapi.TspRequest
+	assert.Nil(json.Unmarshal([]byte(requestJson), &request))
+
+	response, err := solver.FindShortestPathApi(request)
+	assert.Nil(err)
+	assert.NotNil(response)
+	assert.Len(response.Points2D, 5)
+	assert.Len(response.Points3D, 0)
+	assert.Len(response.PointsGraph, 0)
+
+	vertices := make([]model.CircuitVertex, len(response.Points2D))
+	for i, p := range response.Points2D {
+		vertices[i] = model2d.NewVertex2D(*p.X, *p.Y)
+	}
+	assert.InDelta(12.0+2.0*math.Sqrt(5.0), model.Length(vertices), model.Threshold)
+
+	assert.Len(response.Results, 3)
+	numBest := 0
+	for i, r := range response.Results {
+		assert.Equal(request.Algorithms[i].AlgorithmType, r.AlgorithmType)
+		assert.Empty(r.Error)
+		assert.False(r.Truncated)
+		assert.GreaterOrEqual(r.Length, 12.0+2.0*math.Sqrt(5.0)-model.Threshold)
+		if r.Best {
+			numBest++
+			assert.InDelta(model.Length(vertices), r.Length, model.Threshold)
+		}
+	}
+	assert.Equal(1, numBest)
+}
+
+func TestFindShortestPathApi_ShouldRespectTimeLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	request := &modelapi.TspRequest{
+		Algorithms: []*modelapi.Algorithm{
+			{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY},
+			{AlgorithmType: modelapi.ALG_ANNEALING, MaxIterations: 1000000000},
+		},
+		Points2D:        modelapi.ToApiFrom2D(model2d.DeduplicateVertices(model2d.GenerateVertices(50))).Points2D,
+		TimeLimitMillis: 100,
+	}
+
+	start := time.Now()
+	response, err := solver.FindShortestPathApi(request)
+	assert.Less(time.Since(start), 5*time.Second)
+	assert.Nil(err)
+	assert.Len(response.Points2D, len(request.Points2D))
+	assert.Len(response.Results, 2)
+	assert.False(response.Results[0].Truncated)
+	assert.True(response.Results[1].Truncated || response.Results[1].Error != "")
+}
+
+func TestFindShortestPathApi_ShouldReportStageLengths(t *testing.T) {
+	assert := assert.New(t)
+
+	requestJson := `{
+		"algorithms":[
+			{"algorithmType":"PIPELINE","stages":[
+				{"algorithmType":"CLOSEST_GREEDY"},
+				{"algorithmType":"ANNEALING","maxIterations":200,"seed":5},
+				{"algorithmType":"GENETIC","maxIterations":5,"numChildren":10,"numParents":10,"seed":6}
+			]},
+			{"algorithmType":"GENETIC","maxIterations":5,"numChildren":10,"numParents":10,"precursorAlgorithm":{"algorithmType":"DISPARITY_GREEDY"}},
+			{"algorithmType":"CLOSEST_GREEDY"}
+		],
+		"points2d":[{"x":0,"y":0},{"x":4,"y":4},{"x":4,"y":0},{"x":0,"y":4},{"x":2,"y":1},{"x":1,"y":3},{"x":3,"y":2}]
+	}`
+	var request *modelapi.TspRequest
+	assert.Nil(json.Unmarshal([]byte(requestJson), &request))
+
+	response, err := solver.FindShortestPathApi(request)
+	assert.Nil(err)
+	assert.Len(response.Points2D, 7)
+	assert.Len(response.Results, 3)
+
+	assert.Equal(modelapi.ALG_PIPELINE, response.Results[0].AlgorithmType)
+	assert.Len(response.Results[0].StageLengths, 3)
+	assert.InDelta(response.Results[0].Length, response.Results[0].StageLengths[2], model.Threshold)
+
+	assert.Equal(modelapi.ALG_GENETIC, response.Results[1].AlgorithmType)
+	assert.Len(response.Results[1].StageLengths, 2)
+	assert.InDelta(response.Results[1].Length, response.Results[1].StageLengths[1], model.Threshold)
+
+	assert.Nil(response.Results[2].StageLengths)
+}
+
+func TestFindShortestPathApi_ShouldReportResolvedAutoAlgorithm(t *testing.T) {
+	assert := assert.New(t)
+
+	request := &modelapi.TspRequest{
+		Algorithms: []*modelapi.Algorithm{
+			{AlgorithmType: modelapi.ALG_AUTO},
+			{AlgorithmType: modelapi.ALG_CLOSEST_GREEDY},
+		},
+		Points2D:        modelapi.ToApiFrom2D(model2d.DeduplicateVertices(model2d.GenerateVertices(30))).Points2D,
+		TimeLimitMillis: 2000,
+	}
+
+	response, err := solver.FindShortestPathApi(request)
+	assert.Nil(err)
+	assert.Len(response.Points2D, len(request.Points2D))
+	assert.Len(response.Results, 2)
+
+	assert.Equal(modelapi.ALG_AUTO, response.Results[0].AlgorithmType)
+	assert.Empty(response.Results[0].Error)
+	assert.NotNil(response.Results[0].ResolvedAlgorithm)
+	assert.Equal(modelapi.ALG_PIPELINE, response.Results[0].ResolvedAlgorithm.AlgorithmType)
+	assert.Len(response.Results[0].ResolvedAlgorithm.Stages, 2)
+	// The annealing stage may be truncated by the time limit on slow machines (e.g. with the race detector), so only the construction stage is guaranteed to complete.
+	assert.GreaterOrEqual(len(response.Results[0].StageLengths), 1)
+	assert.Equal(modelapi.ALG_AUTO, request.Algorithms[0].AlgorithmType)
+
+	assert.Nil(response.Results[1].ResolvedAlgorithm)
+}
+
+func TestFindShortestPathApi_ShouldNotReportResolvedSavingsHub(t *testing.T) {
+	assert := assert.New(t)
+
+	hubIndex := 3
+	request := &modelapi.TspRequest{
+		Algorithms: []*modelapi.Algorithm{{AlgorithmType: modelapi.ALG_SAVINGS, HubIndex: &hubIndex}},
+		Points2D:   modelapi.ToApiFrom2D(model2d.DeduplicateVertices(model2d.GenerateVertices(10))).Points2D,
+	}
+
+	response, err := solver.FindShortestPathApi(request)
+	assert.Nil(err)
+	assert.Len(response.Points2D, len(request.Points2D))
+	assert.Equal(*request.Points2D[3].X, *response.Points2D[0].X)
+	assert.Equal(*request.Points2D[3].Y, *response.Points2D[0].Y)
+	assert.Nil(response.Results[0].ResolvedAlgorithm)
+}
+
+func TestFindShortestPathApi_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	requestJson := `{"points3d":[{"x":0,"y":0,"z":0},{"x":4,"y":4,"z":0},{"x":4,"y":0,"z":0},{"x":0,"y":4,"z":0},{"x":2,"y":2,"z":5}]}`
+	var request *modelapi.TspRequest
+	assert.Nil(json.Unmarshal([]byte(requestJson), &request))
+
+	response, err := solver.FindShortestPathApi(request)
+	assert.Nil(err)
+	assert.NotNil(response)
+	assert.Len(response.Points2D, 0)
+	assert.Len(response.Points3D, 5)
+	assert.Len(response.PointsGraph, 0)
+}
+
+func TestFindShortestPathApi_Graph(t *testing.T) {
+	assert := assert.New(t)
+
+	requestJson := `{
+		"algorithms":[{"algorithmType":"CLOSEST_GREEDY"},{"algorithmType":"CLOSEST_CLONE"}],
+		"pointsGraph":[
+			{"id":"a","neighbors":[{"id":"b","distance":5.1},{"id":"d","distance":0.25}]},
+			{"id":"b","neighbors":[{"id":"a","distance":3.999},{"id":"c","distance":1.1}]},
+			{"id":"c","neighbors":[{"id":"b","distance":1.1},{"id":"d","distance":10}]},
+			{"id":"d","neighbors":[{"id":"a","distance":2.2}]}
+		]
+	}`
+	var request *modelapi.TspRequest
+	assert.Nil(json.Unmarshal([]byte(requestJson), &request))
+
+	response, err := solver.FindShortestPathApi(request)
+	assert.Nil(err)
+	assert.NotNil(response)
+	assert.Len(response.Points2D, 0)
+	assert.Len(response.Points3D, 0)
+	assert.Len(response.PointsGraph, 4)
+
+	ids := make(map[string]bool)
+	for _, p := range response.PointsGraph {
+		ids[p.Id] = true
+		assert.Greater(len(p.Neighbors), 0)
+	}
+	assert.Len(ids, 4)
+}
+
+func TestFindShortestPathApi_ShouldReturnErrorsForInvalidRequests(t *testing.T) {
+	assert := assert.New(t)
+
+	response, err := solver.FindShortestPathApi(nil)
+	assert.Nil(response)
+	assert.EqualError(err, "request must not be nil")
+
+	response, err = solver.FindShortestPathApi(&modelapi.TspRequest{})
+	assert.Nil(response)
+	assert.NotNil(err)
+
+	// Points must have all coordinates.
+	x, y := 1.0, 2.0
+	response, err = solver.FindShortestPathApi(&modelapi.TspRequest{Points2D: []*modelapi.Point2D{{X: &x, Y: &y}, {X: &y, Y: &x}, {X: &x}}})
+	assert.Nil(response)
+	assert.EqualError(err, `Key: 'TspRequest.Points2D[2].Y' Error:Field validation for 'Y' failed on the 'required' tag`)
+
+	// Deduplication must leave at least 3 points.
+	response, err = solver.FindShortestPathApi(&modelapi.TspRequest{Points2D: []*modelapi.Point2D{{X: &x, Y: &y}, {X: &y, Y: &x}, {X: &x, Y: &y}}})
+	assert.Nil(response)
+	assert.EqualError(err, `request must contain at least 3 unique points, found 2`)
+
+	// Every point in a graph must be able to reach every other point.
+	requestJson := `{"pointsGraph":[
+		{"id":"a","neighbors":[{"id":"b","distance":1}]},
+		{"id":"b","neighbors":[{"id":"a","distance":1}]},
+		{"id":"c","neighbors":[{"id":"a","distance":1}]}
+	]}`
+	var request *modelapi.TspRequest
+	assert.Nil(json.Unmarshal([]byte(requestJson), &request))
+	response, err = solver.FindShortestPathApi(request)
+	assert.Nil(response)
+	assert.Regexp(`^point [ab] cannot reach point c$`, err.Error())
+
+	// The hub of a SAVINGS algorithm must be one of the request's points.
+	hubIndex := 3
+	response, err = solver.FindShortestPathApi(&modelapi.TspRequest{
+		Algorithms: []*modelapi.Algorithm{{AlgorithmType: modelapi.ALG_SAVINGS, HubIndex: &hubIndex}},
+		Points2D:   []*modelapi.Point2D{{X: &x, Y: &y}, {X: &y, Y: &x}, {X: &x, Y: &x}},
+	})
+	assert.Nil(response)
+	assert.EqualError(err, "hubIndex must be the index of a point in the request, found 3 for 3 points")
+
+	// CHRISTOFIDES is limited to circuit.ChristofidesMaxVertices points, since its matching is O(n^3).
+	response, err = solver.FindShortestPathApi(&modelapi.TspRequest{
+		Algorithms: []*modelapi.Algorithm{{AlgorithmType: modelapi.ALG_CHRISTOFIDES}},
+		Points2D:   modelapi.ToApiFrom2D(model2d.GenerateVertices(circuit.ChristofidesMaxVertices + 1)).Points2D,
+	})
+	assert.Nil(response)
+	assert.EqualError(err, "CHRISTOFIDES supports at most 2000 points, found 2001")
+}
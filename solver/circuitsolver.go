@@ -1,6 +1,11 @@
 package solver
 
-import "github.com/heustis/tsp-solver-go/model"
+import (
+	"context"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/model"
+)
 
 // FindShortestPathCircuit use a model.Circuit to approximate or solve the shortest path through a series of points.
 // The complexity of this depends on the complexity of the algorithm used by the model.Circuit.
@@ -9,3 +14,59 @@ func FindShortestPathCircuit(circuit model.Circuit) {
 		circuit.Update(nextVertex, nextEdge)
 	}
 }
+
+// FindShortestPathCircuitContext behaves like FindShortestPathCircuit, but stops updating the circuit once the supplied context is cancelled or its deadline is exceeded.
+// If the circuit completes before the context is done, the supplied circuit is returned and truncated is false.
+// If the context is done first, the circuit's attached vertices are used as the perimeter of a ClosestGreedy circuit, which attaches the remaining unattached vertices
+// by cheapest insertion. That completed circuit is returned, and truncated is true, so that callers always receive a complete and valid tour.
+// Note: the cheapest insertion pass is O(n^2) and does not check the context, so that its result is always complete.
+func FindShortestPathCircuitContext(ctx context.Context, c model.Circuit) (result model.Circuit, truncated bool) {
+	for nextVertex, nextEdge := c.FindNextVertexAndEdge(); nextVertex != nil; nextVertex, nextEdge = c.FindNextVertexAndEdge() {
+		if ctx.Err() != nil {
+			return completeCircuit(c), true
+		}
+		c.Update(nextVertex, nextEdge)
+	}
+	return c, false
+}
+
+// completeCircuit attaches any unattached vertices in the supplied circuit by cheapest insertion, and returns the result as a CompletedCircuit.
+// The supplied circuit is not modified, so it remains safe to inspect or delete after this returns.
+func completeCircuit(c model.Circuit) *circuit.CompletedCircuit {
+	attached := c.GetAttachedVertices()
+	unattached := make(map[model.CircuitVertex]bool)
+	for v := range c.GetUnattachedVertices() {
+		unattached[v] = true
+	}
+
+	// Copy the attached vertices, since some circuits (e.g. SimulatedAnnealing) return the slice they mutate during Update.
+	vertices := make([]model.CircuitVertex, len(attached), len(attached)+1)
+	copy(vertices, attached)
+
+	if len(unattached) == 0 {
+		return &circuit.CompletedCircuit{
+			Circuit: vertices,
+			Length:  model.Length(vertices),
+		}
+	}
+
+	// If nothing has been attached yet, seed the circuit with an arbitrary unattached vertex so that there is at least one edge to split.
+	if len(vertices) == 0 {
+		for v := range unattached {
+			vertices = append(vertices, v)
+			delete(unattached, v)
+			break
+		}
+	}
+
+	greedy := circuit.NewClosestGreedy(vertices, func(verticesArg []model.CircuitVertex) ([]model.CircuitEdge, map[model.CircuitVertex]bool) {
+		edges := make([]model.CircuitEdge, len(verticesArg))
+		for i, v := range verticesArg {
+			edges[i] = v.EdgeTo(verticesArg[(i+1)%len(verticesArg)])
+		}
+		return edges, unattached
+	}, false)
+	FindShortestPathCircuit(greedy)
+
+	return circuit.NewCompletedCircuit(greedy)
+}
@@ -1,10 +1,12 @@
 package solver_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/heustis/tsp-solver-go/circuit"
 	"github.com/heustis/tsp-solver-go/model"
@@ -94,3 +96,82 @@ func TestFindShortestPathCircuit_HeapMinClones_DataFromOldProject(t *testing.T)
 		assert.InDelta(testEntry.Expected, actual, model.Threshold, fmt.Sprintf("test=%d pathLength=%f shortestPath=%s", testIndex, actual, shortestString))
 	}
 }
+
+func TestFindShortestPathCircuitContext_ShouldCompleteWhenNotCancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+	cir := circuit.NewClosestGreedy(vertices, model2d.BuildPerimiter, false)
+
+	result, truncated := solver.FindShortestPathCircuitContext(context.Background(), cir)
+	assert.False(truncated)
+	assert.Equal(cir, result)
+	assert.Len(result.GetAttachedVertices(), len(vertices))
+	assert.Len(result.GetUnattachedVertices(), 0)
+}
+
+func TestFindShortestPathCircuitContext_ShouldReturnCompleteTourWhenCancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+	cir := circuit.NewClosestGreedy(vertices, model2d.BuildPerimiter, false)
+	numUnattached := len(cir.GetUnattachedVertices())
+	assert.Greater(numUnattached, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, truncated := solver.FindShortestPathCircuitContext(ctx, cir)
+	assert.True(truncated)
+	assert.IsType(&circuit.CompletedCircuit{}, result)
+	assert.Len(result.GetUnattachedVertices(), 0)
+	assert.InDelta(model.Length(result.GetAttachedVertices()), result.GetLength(), model.Threshold)
+
+	// The supplied circuit should not be modified by completing the tour.
+	assert.Len(cir.GetUnattachedVertices(), numUnattached)
+
+	actual := result.GetAttachedVertices()
+	assert.Len(actual, len(vertices))
+	for _, v := range vertices {
+		assert.NotEqual(-1, model.IndexOfVertex(actual, v))
+	}
+}
+
+func TestFindShortestPathCircuitContext_ShouldStopAtDeadline(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(200))
+	cir := circuit.NewClonableCircuitSolver(circuit.NewClosestClonable(vertices, model2d.BuildPerimiter))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result, truncated := solver.FindShortestPathCircuitContext(ctx, cir)
+	assert.Less(time.Since(start), 5*time.Second)
+	assert.True(truncated)
+	assert.Len(result.GetAttachedVertices(), len(vertices))
+	assert.Len(result.GetUnattachedVertices(), 0)
+}
+
+func TestFindShortestPathCircuitContext_ShouldCopyStochasticCircuits(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(20))
+	cir := circuit.NewSimulatedAnnealing(vertices, 1000000, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, truncated := solver.FindShortestPathCircuitContext(ctx, cir)
+	assert.True(truncated)
+	assert.Equal(cir.GetAttachedVertices(), result.GetAttachedVertices())
+	assert.InDelta(cir.GetLength(), result.GetLength(), model.Threshold)
+
+	// Updating the original circuit must not affect the returned tour.
+	before := append([]model.CircuitVertex{}, result.GetAttachedVertices()...)
+	for i := 0; i < 1000; i++ {
+		cir.Update(nil, nil)
+	}
+	assert.Equal(before, result.GetAttachedVertices())
+}
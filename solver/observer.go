@@ -0,0 +1,73 @@
+package solver
+
+import (
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// Progress is a snapshot of a circuit's state, supplied to an Observer by FindShortestPathCircuitObserved.
+type Progress struct {
+	// Iteration is the number of times the circuit has been updated by the solver.
+	Iteration int
+	// Length is the current length of the circuit, see model.Circuit.GetLength.
+	Length float64
+	// NumClones is the number of clones created by the circuit, or 0 if the circuit does not clone itself (e.g. ClonableCircuitSolver and DisparityClonable do).
+	NumClones int
+	// NumUnattached is the number of vertices that have not yet been attached to the circuit.
+	NumUnattached int
+}
+
+// Observer is notified of a circuit's progress while it is being solved by FindShortestPathCircuitObserved.
+type Observer interface {
+	// OnProgress is called with the circuit's current progress, and returns false to stop updating the circuit.
+	OnProgress(progress *Progress) bool
+}
+
+// ObserverFunc allows a function to be used as an Observer.
+type ObserverFunc func(progress *Progress) bool
+
+// OnProgress calls the function with the supplied progress.
+func (f ObserverFunc) OnProgress(progress *Progress) bool {
+	return f(progress)
+}
+
+// clonableProgress is implemented by circuits that can report how many clones they have created.
+type clonableProgress interface {
+	GetNumClones() int
+}
+
+// FindShortestPathCircuitObserved behaves like FindShortestPathCircuit, but notifies the supplied observer of the circuit's progress every 'interval' iterations, and once more when the circuit completes.
+// If the observer returns false, the circuit stops being updated and its remaining unattached vertices are attached by cheapest insertion,
+// the same as FindShortestPathCircuitContext, so the returned circuit is always complete and stopped indicates whether the observer stopped it.
+// Note: computing the progress calls GetLength and GetUnattachedVertices, which are O(n) for some circuits, so a larger interval reduces the overhead of observing fast iterations (e.g. SimulatedAnnealing).
+func FindShortestPathCircuitObserved(c model.Circuit, observer Observer, interval int) (result model.Circuit, stopped bool) {
+	if interval < 1 {
+		interval = 1
+	}
+
+	iteration := 0
+	for nextVertex, nextEdge := c.FindNextVertexAndEdge(); nextVertex != nil; nextVertex, nextEdge = c.FindNextVertexAndEdge() {
+		c.Update(nextVertex, nextEdge)
+		iteration++
+		if iteration%interval == 0 && !observer.OnProgress(newProgress(c, iteration)) {
+			return completeCircuit(c), true
+		}
+	}
+
+	// Always report the final state, unless it was just reported.
+	if iteration%interval != 0 || iteration == 0 {
+		observer.OnProgress(newProgress(c, iteration))
+	}
+	return c, false
+}
+
+func newProgress(c model.Circuit, iteration int) *Progress {
+	progress := &Progress{
+		Iteration:     iteration,
+		Length:        c.GetLength(),
+		NumUnattached: len(c.GetUnattachedVertices()),
+	}
+	if clonable, okay := c.(clonableProgress); okay {
+		progress.NumClones = clonable.GetNumClones()
+	}
+	return progress
+}
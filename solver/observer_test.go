@@ -0,0 +1,92 @@
+package solver_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindShortestPathCircuitObserved_ClosestGreedy(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+	cir := circuit.NewClosestGreedy(vertices, model2d.BuildPerimiter, false)
+	numUnattached := len(cir.GetUnattachedVertices())
+
+	progress := []*solver.Progress{}
+	result, stopped := solver.FindShortestPathCircuitObserved(cir, solver.ObserverFunc(func(p *solver.Progress) bool {
+		progress = append(progress, p)
+		return true
+	}), 1)
+
+	assert.False(stopped)
+	assert.Equal(cir, result)
+	assert.Len(progress, numUnattached)
+	for i, p := range progress {
+		assert.Equal(i+1, p.Iteration)
+		assert.Equal(numUnattached-i-1, p.NumUnattached)
+		assert.Equal(0, p.NumClones)
+	}
+	assert.InDelta(cir.GetLength(), progress[len(progress)-1].Length, model.Threshold)
+}
+
+func TestFindShortestPathCircuitObserved_ShouldReportClones(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(20))
+	cir := circuit.NewClonableCircuitSolver(circuit.NewClosestClonable(vertices, model2d.BuildPerimiter))
+
+	var last *solver.Progress
+	solver.FindShortestPathCircuitObserved(cir, solver.ObserverFunc(func(p *solver.Progress) bool {
+		if last != nil {
+			assert.GreaterOrEqual(p.NumClones, last.NumClones)
+		}
+		last = p
+		return true
+	}), 1)
+
+	assert.NotNil(last)
+	assert.Equal(cir.GetNumClones(), last.NumClones)
+	assert.Equal(cir.GetNumIterations(), last.Iteration)
+	assert.Equal(0, last.NumUnattached)
+}
+
+func TestFindShortestPathCircuitObserved_ShouldUseInterval(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(20))
+	cir := circuit.NewSimulatedAnnealing(vertices, 1005, false)
+
+	iterations := []int{}
+	_, stopped := solver.FindShortestPathCircuitObserved(cir, solver.ObserverFunc(func(p *solver.Progress) bool {
+		iterations = append(iterations, p.Iteration)
+		assert.Equal(0, p.NumUnattached)
+		return true
+	}), 100)
+
+	assert.False(stopped)
+	assert.Equal([]int{100, 200, 300, 400, 500, 600, 700, 800, 900, 1000, 1005}, iterations)
+}
+
+func TestFindShortestPathCircuitObserved_ShouldStopEarly(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+	cir := circuit.NewClosestGreedy(vertices, model2d.BuildPerimiter, false)
+
+	numCalls := 0
+	result, stopped := solver.FindShortestPathCircuitObserved(cir, solver.ObserverFunc(func(p *solver.Progress) bool {
+		numCalls++
+		return p.Iteration < 3
+	}), 1)
+
+	assert.True(stopped)
+	assert.Equal(3, numCalls)
+	assert.Greater(len(cir.GetUnattachedVertices()), 0)
+	assert.Len(result.GetAttachedVertices(), len(vertices))
+	assert.Len(result.GetUnattachedVertices(), 0)
+}
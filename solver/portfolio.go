@@ -0,0 +1,119 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// PortfolioAlgorithm creates the circuit for one algorithm in a portfolio.
+// It is invoked by the portfolio's worker goroutine, so that expensive constructors (e.g. NewSimulatedAnnealingFromCircuit) also run concurrently.
+// Each invocation must return a circuit that does not share mutable state with the other algorithms in the portfolio (e.g. use a copy of the vertex array).
+type PortfolioAlgorithm func() model.Circuit
+
+// PortfolioOptions configures how FindShortestPathPortfolio runs its algorithms.
+type PortfolioOptions struct {
+	// MaxWorkers is the maximum number of algorithms that are computed at the same time. If it is less than 1, runtime.GOMAXPROCS(0) workers are used.
+	MaxWorkers int
+	// TimeBudget is the overall time that the portfolio may run for, shared by all of its algorithms. If it is 0 or less, the portfolio is not time limited.
+	// Algorithms that are still running when the budget ends are completed by cheapest insertion (see FindShortestPathCircuitContext), and algorithms that have not started are skipped.
+	// The budget is only checked between updates of each circuit, so it is not honored while a PortfolioAlgorithm constructs its circuit:
+	// slow constructors (e.g. Christofides' matching, or the precursor that NewTwoOptFromCircuit and the other *FromCircuit constructors complete) run to completion and can overrun the budget,
+	// after which their circuit is completed immediately, and reported as truncated.
+	TimeBudget time.Duration
+	// TargetLength is the circuit length that is considered good enough. Once any algorithm produces a circuit at least this short, the remaining algorithms are cancelled.
+	// If it is 0 or less, the winner is only known once every algorithm has completed, so no algorithm is cancelled early: each runs until it completes, or until the time budget ends or the supplied context is done.
+	TargetLength float64
+}
+
+// PortfolioResult is the outcome of one algorithm in a portfolio.
+type PortfolioResult struct {
+	// Circuit is the completed circuit, or nil if the algorithm failed or was skipped.
+	Circuit model.Circuit
+	// Duration is the wall-clock time spent constructing and computing the circuit.
+	Duration time.Duration
+	// Err is set if the algorithm panicked, or was skipped because the portfolio was cancelled before it started.
+	Err error
+	// Length is the length of the completed circuit.
+	Length float64
+	// Truncated is true if the algorithm was cancelled before it completed, so its remaining vertices were attached by cheapest insertion.
+	Truncated bool
+}
+
+// FindShortestPathPortfolio computes each of the supplied algorithms in its own goroutine, using a bounded pool of workers, and returns the index of the shortest circuit along with the results of every algorithm.
+// The results are in the same order as the supplied algorithms. If no algorithm produced a circuit, the returned index is -1.
+// All algorithms stop once the supplied context is done, the time budget ends, or an algorithm reaches the target length; see PortfolioOptions for the limitations of each.
+func FindShortestPathPortfolio(ctx context.Context, algorithms []PortfolioAlgorithm, options PortfolioOptions) (best int, results []*PortfolioResult) {
+	if options.TimeBudget > 0 {
+		var cancelBudget context.CancelFunc
+		ctx, cancelBudget = context.WithTimeout(ctx, options.TimeBudget)
+		defer cancelBudget()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	numWorkers := options.MaxWorkers
+	if numWorkers < 1 {
+		numWorkers = runtime.GOMAXPROCS(0)
+	}
+	if numWorkers > len(algorithms) {
+		numWorkers = len(algorithms)
+	}
+
+	results = make([]*PortfolioResult, len(algorithms))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				result := runPortfolioAlgorithm(ctx, algorithms[i])
+				if result.Err == nil && options.TargetLength > 0 && result.Length <= options.TargetLength {
+					cancel()
+				}
+				// Each index is processed by exactly one worker, and results are only read after all workers finish, so this does not need to be synchronized.
+				results[i] = result
+			}
+		}()
+	}
+	for i := range algorithms {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	best = -1
+	for i, result := range results {
+		if result.Err == nil && (best < 0 || result.Length < results[best].Length) {
+			best = i
+		}
+	}
+	return best, results
+}
+
+// runPortfolioAlgorithm creates and computes a single algorithm's circuit, converting any panic into an error so that one failing algorithm does not terminate the others.
+func runPortfolioAlgorithm(ctx context.Context, algorithm PortfolioAlgorithm) (result *PortfolioResult) {
+	result = &PortfolioResult{}
+	if err := ctx.Err(); err != nil {
+		result.Err = fmt.Errorf("algorithm was not started: %w", err)
+		return result
+	}
+
+	start := time.Now()
+	defer func() {
+		result.Duration = time.Since(start)
+		if r := recover(); r != nil {
+			result.Circuit = nil
+			result.Err = fmt.Errorf("failed to compute circuit: %v", r)
+		}
+	}()
+
+	result.Circuit, result.Truncated = FindShortestPathCircuitContext(ctx, algorithm())
+	result.Length = result.Circuit.GetLength()
+	return result
+}
@@ -0,0 +1,182 @@
+package solver_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindShortestPathPortfolio(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+	copyVertices := func() []model.CircuitVertex {
+		return append([]model.CircuitVertex{}, vertices...)
+	}
+
+	algorithms := []solver.PortfolioAlgorithm{
+		func() model.Circuit {
+			return circuit.NewClosestGreedy(copyVertices(), model2d.BuildPerimiter, false)
+		},
+		func() model.Circuit {
+			return circuit.NewDisparityGreedy(copyVertices(), model2d.BuildPerimiter, false)
+		},
+		func() model.Circuit {
+			return circuit.NewSimulatedAnnealing(copyVertices(), 100, false)
+		},
+	}
+
+	best, results := solver.FindShortestPathPortfolio(context.Background(), algorithms, solver.PortfolioOptions{MaxWorkers: 2})
+	assert.Len(results, 3)
+	assert.GreaterOrEqual(best, 0)
+	for _, r := range results {
+		assert.Nil(r.Err)
+		assert.False(r.Truncated)
+		assert.Greater(r.Duration, time.Duration(0))
+		assert.Len(r.Circuit.GetAttachedVertices(), len(vertices))
+		assert.Len(r.Circuit.GetUnattachedVertices(), 0)
+		assert.InDelta(r.Circuit.GetLength(), r.Length, model.Threshold)
+		assert.LessOrEqual(results[best].Length, r.Length)
+	}
+}
+
+func TestFindShortestPathPortfolio_ShouldLimitWorkers(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(20))
+	var running, maxRunning int32
+	algorithms := make([]solver.PortfolioAlgorithm, 8)
+	for i := range algorithms {
+		algorithms[i] = func() model.Circuit {
+			current := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for previous := atomic.LoadInt32(&maxRunning); current > previous && !atomic.CompareAndSwapInt32(&maxRunning, previous, current); previous = atomic.LoadInt32(&maxRunning) {
+			}
+			time.Sleep(10 * time.Millisecond)
+			return circuit.NewClosestGreedy(append([]model.CircuitVertex{}, vertices...), model2d.BuildPerimiter, false)
+		}
+	}
+
+	best, results := solver.FindShortestPathPortfolio(context.Background(), algorithms, solver.PortfolioOptions{MaxWorkers: 3})
+	assert.GreaterOrEqual(best, 0)
+	assert.Len(results, 8)
+	assert.LessOrEqual(maxRunning, int32(3))
+	assert.Greater(maxRunning, int32(1))
+}
+
+func TestFindShortestPathPortfolio_ShouldStopAtTimeBudget(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(200))
+	algorithms := []solver.PortfolioAlgorithm{
+		func() model.Circuit {
+			return circuit.NewClonableCircuitSolver(circuit.NewClosestClonable(append([]model.CircuitVertex{}, vertices...), model2d.BuildPerimiter))
+		},
+		func() model.Circuit {
+			return circuit.NewSimulatedAnnealing(append([]model.CircuitVertex{}, vertices...), 1000000000, false)
+		},
+		func() model.Circuit {
+			// Only one worker is used, so this should be skipped since the budget ends before it starts.
+			return circuit.NewClosestGreedy(append([]model.CircuitVertex{}, vertices...), model2d.BuildPerimiter, false)
+		},
+	}
+
+	start := time.Now()
+	best, results := solver.FindShortestPathPortfolio(context.Background(), algorithms, solver.PortfolioOptions{
+		MaxWorkers: 1,
+		TimeBudget: 50 * time.Millisecond,
+	})
+	assert.Less(time.Since(start), 5*time.Second)
+	assert.Equal(0, best)
+
+	assert.Nil(results[0].Err)
+	assert.True(results[0].Truncated)
+	assert.Len(results[0].Circuit.GetAttachedVertices(), len(vertices))
+
+	assert.NotNil(results[1].Err)
+	assert.ErrorIs(results[1].Err, context.DeadlineExceeded)
+	assert.Nil(results[1].Circuit)
+	assert.NotNil(results[2].Err)
+}
+
+func TestFindShortestPathPortfolio_ShouldNotInterruptSlowConstructors(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+	algorithms := []solver.PortfolioAlgorithm{
+		func() model.Circuit {
+			// The budget is not checked during construction, so this overruns it, then its circuit is completed by cheapest insertion.
+			time.Sleep(100 * time.Millisecond)
+			return circuit.NewClosestGreedy(append([]model.CircuitVertex{}, vertices...), model2d.BuildPerimiter, false)
+		},
+		func() model.Circuit {
+			return circuit.NewClosestGreedy(append([]model.CircuitVertex{}, vertices...), model2d.BuildPerimiter, false)
+		},
+	}
+
+	best, results := solver.FindShortestPathPortfolio(context.Background(), algorithms, solver.PortfolioOptions{
+		MaxWorkers: 1,
+		TimeBudget: 20 * time.Millisecond,
+	})
+	assert.Equal(0, best)
+
+	assert.Nil(results[0].Err)
+	assert.True(results[0].Truncated)
+	assert.GreaterOrEqual(results[0].Duration, 100*time.Millisecond)
+	assert.Len(results[0].Circuit.GetAttachedVertices(), len(vertices))
+
+	assert.ErrorIs(results[1].Err, context.DeadlineExceeded)
+	assert.Nil(results[1].Circuit)
+}
+
+func TestFindShortestPathPortfolio_ShouldCancelLosersAtTargetLength(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+	algorithms := []solver.PortfolioAlgorithm{
+		func() model.Circuit {
+			return circuit.NewClosestGreedy(append([]model.CircuitVertex{}, vertices...), model2d.BuildPerimiter, false)
+		},
+		func() model.Circuit {
+			return circuit.NewSimulatedAnnealing(append([]model.CircuitVertex{}, vertices...), 1000000000, false)
+		},
+	}
+
+	start := time.Now()
+	best, results := solver.FindShortestPathPortfolio(context.Background(), algorithms, solver.PortfolioOptions{
+		MaxWorkers:   2,
+		TargetLength: 1e12,
+	})
+	assert.Less(time.Since(start), 5*time.Second)
+	assert.GreaterOrEqual(best, 0)
+	assert.False(results[0].Truncated)
+	// Annealing either had not started, or was truncated, once the greedy circuit reached the target length.
+	assert.True(results[1].Err != nil || results[1].Truncated)
+}
+
+func TestFindShortestPathPortfolio_ShouldReportPanics(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(10))
+	algorithms := []solver.PortfolioAlgorithm{
+		func() model.Circuit {
+			panic("bad data")
+		},
+		func() model.Circuit {
+			return circuit.NewClosestGreedy(append([]model.CircuitVertex{}, vertices...), model2d.BuildPerimiter, false)
+		},
+	}
+
+	best, results := solver.FindShortestPathPortfolio(context.Background(), algorithms, solver.PortfolioOptions{})
+	assert.Equal(1, best)
+	assert.EqualError(results[0].Err, "failed to compute circuit: bad data")
+	assert.Nil(results[0].Circuit)
+	assert.Nil(results[1].Err)
+}
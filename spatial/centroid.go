@@ -0,0 +1,35 @@
+package spatial
+
+import (
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+)
+
+// Centroid returns a new vertex at the average position of the supplied vertices.
+// The vertices must all be model2d.Vertex2D or all be model3d.Vertex3D; if they are not (e.g. they are graph vertices), or there are no vertices, this returns (nil, false).
+func Centroid(vertices []model.CircuitVertex) (model.CircuitVertex, bool) {
+	var sum []float64
+	for _, v := range vertices {
+		coordinates := getCoordinates(v)
+		if coordinates == nil || (sum != nil && len(coordinates) != len(sum)) {
+			return nil, false
+		}
+		if sum == nil {
+			sum = make([]float64, len(coordinates))
+		}
+		for i, coordinate := range coordinates {
+			sum[i] += coordinate
+		}
+	}
+
+	numVertices := float64(len(vertices))
+	switch len(sum) {
+	case 2:
+		return model2d.NewVertex2D(sum[0]/numVertices, sum[1]/numVertices), true
+	case 3:
+		return model3d.NewVertex3D(sum[0]/numVertices, sum[1]/numVertices, sum[2]/numVertices), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,49 @@
+package spatial_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/spatial"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCentroid(t *testing.T) {
+	assert := assert.New(t)
+
+	centroid, okay := spatial.Centroid([]model.CircuitVertex{
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(10, 0),
+		model2d.NewVertex2D(10, 20),
+		model2d.NewVertex2D(0, 20),
+	})
+	assert.True(okay)
+	assert.Equal(model2d.NewVertex2D(5, 10), centroid)
+
+	centroid, okay = spatial.Centroid([]model.CircuitVertex{
+		model3d.NewVertex3D(0, 0, 3),
+		model3d.NewVertex3D(6, -3, 0),
+		model3d.NewVertex3D(3, 9, 9),
+	})
+	assert.True(okay)
+	assert.Equal(model3d.NewVertex3D(3, 2, 4), centroid)
+}
+
+func TestCentroid_Unsupported(t *testing.T) {
+	assert := assert.New(t)
+
+	centroid, okay := spatial.Centroid([]model.CircuitVertex{})
+	assert.False(okay)
+	assert.Nil(centroid)
+
+	centroid, okay = spatial.Centroid([]model.CircuitVertex{model2d.NewVertex2D(1, 2), model3d.NewVertex3D(1, 2, 3)})
+	assert.False(okay)
+	assert.Nil(centroid)
+
+	centroid, okay = spatial.Centroid([]model.CircuitVertex{graph.NewGraphVertex("a"), graph.NewGraphVertex("b")})
+	assert.False(okay)
+	assert.Nil(centroid)
+}
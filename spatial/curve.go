@@ -0,0 +1,127 @@
+package spatial
+
+import (
+	"math"
+	"sort"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// HilbertOrder returns the supplied vertices sorted by their position along a [Hilbert curve](https://en.wikipedia.org/wiki/Hilbert_curve) through their bounding box.
+// Consecutive vertices along a Hilbert curve are always close to each other, so this is an O(n*log(n)) approximation of a short path through the vertices.
+// The vertices must all be model2d.Vertex2D or all be model3d.Vertex3D; if they are not (e.g. they are graph vertices), this returns (nil, false).
+func HilbertOrder(vertices []model.CircuitVertex) ([]model.CircuitVertex, bool) {
+	return sortByCurve(vertices, hilbertKey)
+}
+
+// MortonOrder returns the supplied vertices sorted by their position along a [Morton (Z-order) curve](https://en.wikipedia.org/wiki/Z-order_curve) through their bounding box.
+// This is cheaper to compute than HilbertOrder, but the Morton curve has long jumps between its quadrants, so the resulting path is typically longer.
+// The vertices must all be model2d.Vertex2D or all be model3d.Vertex3D; if they are not (e.g. they are graph vertices), this returns (nil, false).
+func MortonOrder(vertices []model.CircuitVertex) ([]model.CircuitVertex, bool) {
+	return sortByCurve(vertices, mortonKey)
+}
+
+// sortByCurve scales the vertices' coordinates to integers, computes each vertex's key with the supplied function, and sorts the vertices by their keys.
+// The coordinates are offset by the minimum of each axis, but scaled uniformly, so that the curve is not distorted when the bounding box is not a square (or cube).
+func sortByCurve(vertices []model.CircuitVertex, keyFunc func(coordinates []uint32, numBits uint) uint64) ([]model.CircuitVertex, bool) {
+	coordinates := make([][]float64, len(vertices))
+	var min, max []float64
+	for i, v := range vertices {
+		if coordinates[i] = getCoordinates(v); coordinates[i] == nil || (min != nil && len(coordinates[i]) != len(min)) {
+			return nil, false
+		}
+		if min == nil {
+			min = append([]float64{}, coordinates[i]...)
+			max = append([]float64{}, coordinates[i]...)
+		}
+		for axis, c := range coordinates[i] {
+			min[axis] = math.Min(min[axis], c)
+			max[axis] = math.Max(max[axis], c)
+		}
+	}
+	numDimensions := len(min)
+	maxRange := 0.0
+	for axis := range min {
+		maxRange = math.Max(maxRange, max[axis]-min[axis])
+	}
+
+	// Each key must fit in 64 bits, so 2D vertices use 31 bits per axis, and 3D vertices use 21 bits per axis.
+	numBits := uint(31)
+	if numDimensions == 3 {
+		numBits = 21
+	}
+	scale := 0.0
+	if maxRange > 0 {
+		scale = float64(uint32(1)<<numBits-1) / maxRange
+	}
+
+	keys := make([]uint64, len(vertices))
+	order := make([]int, len(vertices))
+	scaled := make([]uint32, numDimensions)
+	for i, vertexCoordinates := range coordinates {
+		for axis, c := range vertexCoordinates {
+			scaled[axis] = uint32((c - min[axis]) * scale)
+		}
+		keys[i] = keyFunc(scaled, numBits)
+		order[i] = i
+	}
+	// A stable sort ensures that vertices with the same key (e.g. duplicates) remain in the order they were supplied.
+	sort.SliceStable(order, func(i, j int) bool {
+		return keys[order[i]] < keys[order[j]]
+	})
+
+	sorted := make([]model.CircuitVertex, len(vertices))
+	for i, index := range order {
+		sorted[i] = vertices[index]
+	}
+	return sorted, true
+}
+
+// hilbertKey returns the distance along the Hilbert curve of the supplied coordinates, each of which has the supplied number of bits.
+// This uses John Skilling's algorithm ("Programming the Hilbert curve", 2004) to transpose the coordinates into the Hilbert index, then interleaves its bits.
+// The supplied coordinates are modified.
+func hilbertKey(coordinates []uint32, numBits uint) uint64 {
+	numDimensions := len(coordinates)
+	highBit := uint32(1) << (numBits - 1)
+
+	// Inverse undo excess work.
+	for q := highBit; q > 1; q >>= 1 {
+		p := q - 1
+		for i := 0; i < numDimensions; i++ {
+			if coordinates[i]&q != 0 {
+				coordinates[0] ^= p
+			} else {
+				t := (coordinates[0] ^ coordinates[i]) & p
+				coordinates[0] ^= t
+				coordinates[i] ^= t
+			}
+		}
+	}
+
+	// Gray encode.
+	for i := 1; i < numDimensions; i++ {
+		coordinates[i] ^= coordinates[i-1]
+	}
+	t := uint32(0)
+	for q := highBit; q > 1; q >>= 1 {
+		if coordinates[numDimensions-1]&q != 0 {
+			t ^= q - 1
+		}
+	}
+	for i := range coordinates {
+		coordinates[i] ^= t
+	}
+
+	return mortonKey(coordinates, numBits)
+}
+
+// mortonKey interleaves the bits of the supplied coordinates, from the most significant bit to the least, with the first coordinate's bit first.
+func mortonKey(coordinates []uint32, numBits uint) uint64 {
+	key := uint64(0)
+	for bit := int(numBits) - 1; bit >= 0; bit-- {
+		for _, c := range coordinates {
+			key = key<<1 | uint64((c>>uint(bit))&1)
+		}
+	}
+	return key
+}
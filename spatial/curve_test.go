@@ -0,0 +1,100 @@
+package spatial_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/spatial"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHilbertOrder_2D(t *testing.T) {
+	assert := assert.New(t)
+
+	// Every step along a Hilbert curve through a grid moves to an adjacent cell.
+	vertices := []model.CircuitVertex{}
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			vertices = append(vertices, model2d.NewVertex2D(float64(x)*1.5+10, float64(y)*1.5-20))
+		}
+	}
+	sorted, okay := spatial.HilbertOrder(vertices)
+	assert.True(okay)
+	assert.Len(sorted, len(vertices))
+	assert.ElementsMatch(vertices, sorted)
+	for i := 1; i < len(sorted); i++ {
+		assert.InDelta(1.5, sorted[i-1].DistanceTo(sorted[i]), model.Threshold, i)
+	}
+	// The curve starts and ends in adjacent corners of the bounding box.
+	assert.Equal(vertices[0], sorted[0])
+	assert.InDelta(10.5, sorted[0].DistanceTo(sorted[len(sorted)-1]), model.Threshold)
+}
+
+func TestHilbertOrder_3D(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{}
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			for z := 0; z < 4; z++ {
+				vertices = append(vertices, model3d.NewVertex3D(float64(x), float64(y), float64(z)))
+			}
+		}
+	}
+	sorted, okay := spatial.HilbertOrder(vertices)
+	assert.True(okay)
+	assert.ElementsMatch(vertices, sorted)
+	for i := 1; i < len(sorted); i++ {
+		assert.InDelta(1.0, sorted[i-1].DistanceTo(sorted[i]), model.Threshold, i)
+	}
+}
+
+func TestMortonOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := []model.CircuitVertex{
+		model2d.NewVertex2D(1, 1),
+		model2d.NewVertex2D(0, 1),
+		model2d.NewVertex2D(1, 0),
+		model2d.NewVertex2D(0, 0),
+	}
+	sorted, okay := spatial.MortonOrder(vertices)
+	assert.True(okay)
+	assert.Equal([]model.CircuitVertex{vertices[3], vertices[1], vertices[2], vertices[0]}, sorted)
+
+	vertices3D := []model.CircuitVertex{
+		model3d.NewVertex3D(1, 1, 1),
+		model3d.NewVertex3D(0, 0, 1),
+		model3d.NewVertex3D(1, 0, 0),
+		model3d.NewVertex3D(0, 0, 0),
+	}
+	sorted, okay = spatial.MortonOrder(vertices3D)
+	assert.True(okay)
+	assert.Equal([]model.CircuitVertex{vertices3D[3], vertices3D[1], vertices3D[2], vertices3D[0]}, sorted)
+}
+
+func TestCurveOrder_Unsupported(t *testing.T) {
+	assert := assert.New(t)
+
+	sorted, okay := spatial.HilbertOrder([]model.CircuitVertex{})
+	assert.True(okay)
+	assert.Len(sorted, 0)
+
+	// Identical vertices keep the order they were supplied in.
+	a, b := model2d.NewVertex2D(3, 3), model2d.NewVertex2D(3, 3)
+	sorted, okay = spatial.HilbertOrder([]model.CircuitVertex{a, b})
+	assert.True(okay)
+	assert.Same(a, sorted[0])
+	assert.Same(b, sorted[1])
+
+	sorted, okay = spatial.MortonOrder([]model.CircuitVertex{model2d.NewVertex2D(1, 2), model3d.NewVertex3D(1, 2, 3)})
+	assert.False(okay)
+	assert.Nil(sorted)
+
+	sorted, okay = spatial.HilbertOrder([]model.CircuitVertex{graph.NewGraphVertex("a"), graph.NewGraphVertex("b")})
+	assert.False(okay)
+	assert.Nil(sorted)
+}
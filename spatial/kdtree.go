@@ -0,0 +1,251 @@
+// Package spatial contains spatial indexes, which accelerate proximity queries (e.g. finding the nearest point) on 2D and 3D vertices.
+package spatial
+
+import (
+	"math"
+	"sort"
+
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+)
+
+// KDTree is a [k-d tree](https://en.wikipedia.org/wiki/K-d_tree) over 2D or 3D vertices.
+// It is built once, in O(n*log(n)^2), then supports nearest neighbor queries in O(log(n)) on average.
+// Vertices can be removed from the tree, so that it can track which vertices remain (e.g. which vertices have not been visited yet), without being rebuilt.
+//
+// A KDTree is not safe for concurrent use if vertices are being removed from it.
+type KDTree struct {
+	indices map[model.CircuitVertex]int
+	nodes   []*kdNode
+	root    int
+}
+
+type kdNode struct {
+	axis        int
+	coordinates []float64
+	left        int
+	// numRemaining is the number of vertices in this node's subtree, including this node, that have not been removed.
+	numRemaining int
+	parent       int
+	removed      bool
+	right        int
+	vertex       model.CircuitVertex
+}
+
+// NewKDTree creates a k-d tree containing the supplied vertices.
+// The vertices must all be model2d.Vertex2D or all be model3d.Vertex3D; if they are not (e.g. they are graph vertices), this returns (nil, false).
+func NewKDTree(vertices []model.CircuitVertex) (*KDTree, bool) {
+	tree := &KDTree{
+		indices: make(map[model.CircuitVertex]int, len(vertices)),
+		nodes:   make([]*kdNode, 0, len(vertices)),
+		root:    -1,
+	}
+
+	numDimensions := 0
+	for _, v := range vertices {
+		if _, isDuplicate := tree.indices[v]; isDuplicate {
+			continue
+		}
+		coordinates := getCoordinates(v)
+		if coordinates == nil || (numDimensions > 0 && len(coordinates) != numDimensions) {
+			return nil, false
+		}
+		numDimensions = len(coordinates)
+		tree.indices[v] = len(tree.nodes)
+		tree.nodes = append(tree.nodes, &kdNode{
+			coordinates: coordinates,
+			left:        -1,
+			parent:      -1,
+			right:       -1,
+			vertex:      v,
+		})
+	}
+
+	order := make([]int, len(tree.nodes))
+	for i := range order {
+		order[i] = i
+	}
+	tree.root = tree.build(order, 0, numDimensions, -1)
+	return tree, true
+}
+
+// Len returns the number of vertices in the tree that have not been removed.
+func (t *KDTree) Len() int {
+	if t.root < 0 {
+		return 0
+	}
+	return t.nodes[t.root].numRemaining
+}
+
+// Nearest returns the closest vertex to the supplied vertex that has not been removed from the tree, or nil if every vertex has been removed.
+// The supplied vertex does not need to be in the tree; if it is in the tree and has not been removed, it is its own nearest vertex.
+func (t *KDTree) Nearest(v model.CircuitVertex) model.CircuitVertex {
+	if t.Len() == 0 {
+		return nil
+	}
+	search := &kdSearch{
+		bestDistance: math.MaxFloat64,
+		bestIndex:    -1,
+		target:       getCoordinates(v),
+	}
+	t.nearest(t.root, search)
+	return t.nodes[search.bestIndex].vertex
+}
+
+// KNearest returns up to k vertices that have not been removed from the tree, ordered from closest to farthest from the supplied vertex.
+// As with Nearest, if the supplied vertex is in the tree and has not been removed, it is included in the result.
+func (t *KDTree) KNearest(v model.CircuitVertex, k int) []model.CircuitVertex {
+	if k <= 0 || t.Len() == 0 {
+		return []model.CircuitVertex{}
+	}
+	search := &kdKNearestSearch{
+		k:      k,
+		target: getCoordinates(v),
+	}
+	t.kNearest(t.root, search)
+	nearest := make([]model.CircuitVertex, len(search.indices))
+	for i, index := range search.indices {
+		nearest[i] = t.nodes[index].vertex
+	}
+	return nearest
+}
+
+// Remove removes the supplied vertex from the tree, so that it is not returned by future queries. It returns false if the vertex is not in the tree, or was already removed.
+func (t *KDTree) Remove(v model.CircuitVertex) bool {
+	index, okay := t.indices[v]
+	if !okay || t.nodes[index].removed {
+		return false
+	}
+	t.nodes[index].removed = true
+	for ; index >= 0; index = t.nodes[index].parent {
+		t.nodes[index].numRemaining--
+	}
+	return true
+}
+
+// build recursively splits the supplied nodes at their median along the current axis, and returns the index of the median node.
+func (t *KDTree) build(order []int, depth int, numDimensions int, parent int) int {
+	if len(order) == 0 {
+		return -1
+	}
+	axis := depth % numDimensions
+	sort.Slice(order, func(i, j int) bool {
+		return t.nodes[order[i]].coordinates[axis] < t.nodes[order[j]].coordinates[axis]
+	})
+	median := len(order) / 2
+	index := order[median]
+	node := t.nodes[index]
+	node.axis = axis
+	node.numRemaining = len(order)
+	node.parent = parent
+	node.left = t.build(order[:median], depth+1, numDimensions, index)
+	node.right = t.build(order[median+1:], depth+1, numDimensions, index)
+	return index
+}
+
+// kdSearch tracks the closest node found so far during a nearest neighbor search.
+type kdSearch struct {
+	bestDistance float64
+	bestIndex    int
+	target       []float64
+}
+
+func (t *KDTree) nearest(index int, search *kdSearch) {
+	if index < 0 || t.nodes[index].numRemaining == 0 {
+		return
+	}
+	node := t.nodes[index]
+	if !node.removed {
+		if distance := distanceSquared(node.coordinates, search.target); distance < search.bestDistance {
+			search.bestDistance = distance
+			search.bestIndex = index
+		}
+	}
+
+	// Search the side of the split containing the target first, then only search the other side if it could contain a closer vertex.
+	delta := search.target[node.axis] - node.coordinates[node.axis]
+	near, far := node.left, node.right
+	if delta > 0 {
+		near, far = far, near
+	}
+	t.nearest(near, search)
+	if delta*delta < search.bestDistance {
+		t.nearest(far, search)
+	}
+}
+
+// kdKNearestSearch tracks the k closest nodes found so far during a k-nearest neighbors search, sorted from closest to farthest.
+// k is expected to be small, so the nodes are kept in a sorted slice rather than a heap.
+type kdKNearestSearch struct {
+	distances []float64
+	indices   []int
+	k         int
+	target    []float64
+}
+
+// add inserts the node into the sorted results, if it is closer than the farthest result or there are fewer than k results.
+func (s *kdKNearestSearch) add(index int, distance float64) {
+	numResults := len(s.indices)
+	if numResults == s.k && distance >= s.distances[numResults-1] {
+		return
+	}
+	position := sort.SearchFloat64s(s.distances, distance)
+	if numResults < s.k {
+		s.distances = append(s.distances, 0)
+		s.indices = append(s.indices, 0)
+	}
+	copy(s.distances[position+1:], s.distances[position:])
+	copy(s.indices[position+1:], s.indices[position:])
+	s.distances[position] = distance
+	s.indices[position] = index
+}
+
+// maxDistance returns the squared distance that a node must be closer than to be added to the results.
+func (s *kdKNearestSearch) maxDistance() float64 {
+	if len(s.indices) < s.k {
+		return math.MaxFloat64
+	}
+	return s.distances[len(s.distances)-1]
+}
+
+func (t *KDTree) kNearest(index int, search *kdKNearestSearch) {
+	if index < 0 || t.nodes[index].numRemaining == 0 {
+		return
+	}
+	node := t.nodes[index]
+	if !node.removed {
+		search.add(index, distanceSquared(node.coordinates, search.target))
+	}
+
+	delta := search.target[node.axis] - node.coordinates[node.axis]
+	near, far := node.left, node.right
+	if delta > 0 {
+		near, far = far, near
+	}
+	t.kNearest(near, search)
+	if delta*delta < search.maxDistance() {
+		t.kNearest(far, search)
+	}
+}
+
+// getCoordinates returns the coordinates of the supplied vertex, or nil if it is not a 2D or 3D vertex.
+func getCoordinates(v model.CircuitVertex) []float64 {
+	switch vertex := v.(type) {
+	case *model2d.Vertex2D:
+		return []float64{vertex.X, vertex.Y}
+	case *model3d.Vertex3D:
+		return []float64{vertex.X, vertex.Y, vertex.Z}
+	default:
+		return nil
+	}
+}
+
+func distanceSquared(a []float64, b []float64) float64 {
+	distance := 0.0
+	for i, coordinate := range a {
+		delta := coordinate - b[i]
+		distance += delta * delta
+	}
+	return distance
+}
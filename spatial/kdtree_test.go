@@ -0,0 +1,163 @@
+package spatial_test
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/graph"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/model3d"
+	"github.com/heustis/tsp-solver-go/spatial"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKDTree(t *testing.T) {
+	assert := assert.New(t)
+
+	tree, okay := spatial.NewKDTree(model2d.GenerateVertices(50))
+	assert.True(okay)
+	assert.Equal(50, tree.Len())
+
+	tree, okay = spatial.NewKDTree(model3d.GenerateVertices(50))
+	assert.True(okay)
+	assert.Equal(50, tree.Len())
+
+	tree, okay = spatial.NewKDTree([]model.CircuitVertex{})
+	assert.True(okay)
+	assert.Equal(0, tree.Len())
+	assert.Nil(tree.Nearest(model2d.NewVertex2D(1, 2)))
+
+	// Duplicate references to the same vertex are only included once.
+	v := model2d.NewVertex2D(1, 2)
+	tree, okay = spatial.NewKDTree([]model.CircuitVertex{v, model2d.NewVertex2D(3, 4), v})
+	assert.True(okay)
+	assert.Equal(2, tree.Len())
+}
+
+func TestNewKDTree_ShouldRejectUnsupportedVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	gen := &graph.GraphGenerator{
+		MaxEdges:    5,
+		MinEdges:    2,
+		NumVertices: 10,
+	}
+	g := gen.Create()
+	defer g.Delete()
+
+	tree, okay := spatial.NewKDTree(graph.ToCircuitVertexArray(g.GetVertices()))
+	assert.False(okay)
+	assert.Nil(tree)
+
+	tree, okay = spatial.NewKDTree([]model.CircuitVertex{model2d.NewVertex2D(1, 2), model3d.NewVertex3D(1, 2, 3)})
+	assert.False(okay)
+	assert.Nil(tree)
+}
+
+func TestNearest_ShouldMatchBruteForce(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, vertices := range [][]model.CircuitVertex{model2d.GenerateVertices(500), model3d.GenerateVertices(500)} {
+		tree, okay := spatial.NewKDTree(vertices)
+		assert.True(okay)
+
+		remaining := make(map[model.CircuitVertex]bool)
+		for _, v := range vertices {
+			remaining[v] = true
+		}
+
+		for i, v := range vertices {
+			nearest := tree.Nearest(v)
+			expected := findNearest(v, remaining)
+			assert.InDelta(v.DistanceTo(expected), v.DistanceTo(nearest), model.Threshold)
+			assert.True(remaining[nearest])
+
+			// Remove every other vertex, so that later queries must skip removed vertices.
+			if i%2 == 0 {
+				assert.True(tree.Remove(v))
+				assert.False(tree.Remove(v))
+				delete(remaining, v)
+				assert.Equal(len(remaining), tree.Len())
+			}
+		}
+	}
+}
+
+func TestKNearest_ShouldMatchBruteForce(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, vertices := range [][]model.CircuitVertex{model2d.GenerateVertices(300), model3d.GenerateVertices(300)} {
+		tree, _ := spatial.NewKDTree(vertices)
+		for i, v := range vertices {
+			if i%3 == 0 {
+				tree.Remove(v)
+			}
+		}
+
+		for _, v := range vertices {
+			nearest := tree.KNearest(v, 8)
+			assert.Len(nearest, 8)
+
+			// The results are sorted, and match the 8 closest remaining vertices.
+			expected := make([]float64, 0, len(vertices))
+			for i, other := range vertices {
+				if i%3 != 0 {
+					expected = append(expected, v.DistanceTo(other))
+				}
+			}
+			sort.Float64s(expected)
+			for i, other := range nearest {
+				assert.InDelta(expected[i], v.DistanceTo(other), model.Threshold)
+			}
+		}
+	}
+}
+
+func TestKNearest_FewVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	a := model2d.NewVertex2D(0, 0)
+	b := model2d.NewVertex2D(5, 0)
+	c := model2d.NewVertex2D(10, 0)
+	tree, _ := spatial.NewKDTree([]model.CircuitVertex{a, b, c})
+
+	assert.Equal([]model.CircuitVertex{c, b, a}, tree.KNearest(model2d.NewVertex2D(9, 1), 5))
+	assert.Equal([]model.CircuitVertex{a}, tree.KNearest(a, 1))
+	assert.Equal([]model.CircuitVertex{}, tree.KNearest(a, 0))
+	tree.Remove(a)
+	assert.Equal([]model.CircuitVertex{b, c}, tree.KNearest(a, 2))
+}
+
+func TestRemove(t *testing.T) {
+	assert := assert.New(t)
+
+	a := model2d.NewVertex2D(0, 0)
+	b := model2d.NewVertex2D(5, 0)
+	c := model2d.NewVertex2D(10, 0)
+	tree, _ := spatial.NewKDTree([]model.CircuitVertex{a, b, c})
+
+	assert.Equal(a, tree.Nearest(model2d.NewVertex2D(1, 1)))
+	assert.True(tree.Remove(a))
+	assert.Equal(b, tree.Nearest(model2d.NewVertex2D(1, 1)))
+	assert.True(tree.Remove(b))
+	assert.Equal(c, tree.Nearest(model2d.NewVertex2D(1, 1)))
+	assert.True(tree.Remove(c))
+	assert.Nil(tree.Nearest(model2d.NewVertex2D(1, 1)))
+	assert.Equal(0, tree.Len())
+
+	assert.False(tree.Remove(model2d.NewVertex2D(0, 0)))
+}
+
+func findNearest(v model.CircuitVertex, remaining map[model.CircuitVertex]bool) model.CircuitVertex {
+	var nearest model.CircuitVertex
+	nearestDistance := math.MaxFloat64
+	for other := range remaining {
+		if d := v.DistanceTo(other); d < nearestDistance {
+			nearest = other
+			nearestDistance = d
+		}
+	}
+	return nearest
+}
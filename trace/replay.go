@@ -0,0 +1,171 @@
+package trace
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+// Replayed is the state of a traced circuit after replaying some of the steps in its trace.
+type Replayed struct {
+	// Edges are the edges of the circuit, in circuit order.
+	Edges []model.CircuitEdge
+	// Step is the number of steps that were replayed, which is less than the requested step if the trace has fewer steps.
+	Step int
+	// Unattached is the set of vertices that were not yet attached to the circuit.
+	Unattached map[model.CircuitVertex]bool
+}
+
+// GetAttachedVertices returns the vertices of the replayed circuit, in circuit order.
+func (r *Replayed) GetAttachedVertices() []model.CircuitVertex {
+	vertices := make([]model.CircuitVertex, len(r.Edges))
+	for i, e := range r.Edges {
+		vertices[i] = e.GetStart()
+	}
+	return vertices
+}
+
+// PerimeterBuilder returns a perimeter builder that ignores the vertices it is supplied, and instead returns copies of the replayed edges and unattached vertices.
+// This allows another algorithm to continue solving the circuit from the replayed step.
+func (r *Replayed) PerimeterBuilder() model.PerimeterBuilder {
+	return func(ignoredVertices []model.CircuitVertex) ([]model.CircuitEdge, map[model.CircuitVertex]bool) {
+		edges := make([]model.CircuitEdge, len(r.Edges))
+		copy(edges, r.Edges)
+		unattached := make(map[model.CircuitVertex]bool, len(r.Unattached))
+		for v := range r.Unattached {
+			unattached[v] = true
+		}
+		return edges, unattached
+	}
+}
+
+// Replay reads a trace, created by a Recorder, and rebuilds the circuit as it was after the specified number of steps.
+// A step of 0 returns the perimeter, and a negative step replays every step in the trace.
+// The supplied vertices must be the same vertices, in the same order, that were supplied to the Recorder.
+// Each step splits its edge with its vertex; if the vertex is already attached (e.g. because ClosestGreedy detached it to reattach it elsewhere) it is moved to the edge instead.
+// Steps without an edge (e.g. from SimulatedAnnealing) cannot be replayed, and return an error.
+func Replay(r io.Reader, vertices []model.CircuitVertex, step int) (*Replayed, error) {
+	replayed := &Replayed{
+		Edges:      []model.CircuitEdge{},
+		Unattached: make(map[model.CircuitVertex]bool),
+	}
+
+	reader := bufio.NewReader(r)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		} else if err == io.EOF && len(line) == 0 {
+			break
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if lineNumber == 1 {
+			if strings.TrimSpace(line) != formatHeader {
+				return nil, fmt.Errorf("line 1: expected %q", formatHeader)
+			}
+			continue
+		}
+
+		indices, err := parseIntegers(fields[1:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+
+		switch fields[0] {
+		case "vertices":
+			if len(indices) != 1 || indices[0] != len(vertices) {
+				return nil, fmt.Errorf("line %d: the trace has %s vertices, but %d vertices were supplied", lineNumber, strings.Join(fields[1:], " "), len(vertices))
+			}
+		case "perimeter":
+			if err := checkIndices(indices, len(vertices)); err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+			}
+			replayed.Edges = make([]model.CircuitEdge, len(indices))
+			for i, index := range indices {
+				replayed.Edges[i] = vertices[index].EdgeTo(vertices[indices[(i+1)%len(indices)]])
+			}
+		case "unattached":
+			if err := checkIndices(indices, len(vertices)); err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+			}
+			for _, index := range indices {
+				replayed.Unattached[vertices[index]] = true
+			}
+		case "step":
+			if step >= 0 && replayed.Step >= step {
+				return replayed, nil
+			}
+			if err := replayed.applyStep(vertices, indices); err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+			}
+		default:
+			return nil, fmt.Errorf("line %d: unrecognized entry %q", lineNumber, fields[0])
+		}
+	}
+
+	return replayed, nil
+}
+
+func (r *Replayed) applyStep(vertices []model.CircuitVertex, indices []int) error {
+	if len(indices) < 1 || indices[0] != r.Step+1 {
+		return fmt.Errorf("expected step %d", r.Step+1)
+	}
+	r.Step++
+	if len(indices) != 4 {
+		return fmt.Errorf("step %d does not include an edge, so it cannot be replayed", r.Step)
+	}
+	if err := checkIndices(indices[1:], len(vertices)); err != nil {
+		return err
+	}
+
+	vertex := vertices[indices[1]]
+	edge := vertices[indices[2]].EdgeTo(vertices[indices[3]])
+	if r.Unattached[vertex] {
+		var edgeIndex int
+		if r.Edges, edgeIndex = model.SplitEdge(r.Edges, edge, vertex); edgeIndex < 0 {
+			return fmt.Errorf("step %d splits edge %d->%d, which is not in the circuit", r.Step, indices[2], indices[3])
+		}
+		delete(r.Unattached, vertex)
+	} else {
+		var mergedEdge model.CircuitEdge
+		if r.Edges, mergedEdge, _, _ = model.MoveVertex(r.Edges, vertex, edge); mergedEdge == nil {
+			return fmt.Errorf("step %d moves vertex %d to edge %d->%d, but either is not in the circuit", r.Step, indices[1], indices[2], indices[3])
+		}
+	}
+	return nil
+}
+
+// parseIntegers converts each field into an integer, omitting "-" (used for steps without an edge).
+func parseIntegers(fields []string) ([]int, error) {
+	integers := make([]int, 0, len(fields))
+	for _, f := range fields {
+		if f == "-" {
+			continue
+		}
+		i, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", f)
+		}
+		integers = append(integers, i)
+	}
+	return integers, nil
+}
+
+// checkIndices returns an error if any of the indices is not a valid index in the array of vertices.
+func checkIndices(indices []int, numVertices int) error {
+	for _, index := range indices {
+		if index < 0 || index >= numVertices {
+			return fmt.Errorf("vertex index %d is out of range [0,%d)", index, numVertices)
+		}
+	}
+	return nil
+}
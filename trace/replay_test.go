@@ -0,0 +1,97 @@
+package trace_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/heustis/tsp-solver-go/trace"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplay(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(50))
+	var buffer bytes.Buffer
+	recorder := trace.NewRecorder(&buffer, vertices)
+	c := recorder.Circuit(circuit.NewClosestGreedy(append([]model.CircuitVertex{}, vertices...), recorder.PerimeterBuilder(model2d.BuildPerimiter), false))
+
+	// Track the state of the circuit after each step, to compare with the replayed circuits.
+	expected := [][]model.CircuitVertex{append([]model.CircuitVertex{}, c.GetAttachedVertices()...)}
+	for next, edge := c.FindNextVertexAndEdge(); next != nil; next, edge = c.FindNextVertexAndEdge() {
+		c.Update(next, edge)
+		expected = append(expected, append([]model.CircuitVertex{}, c.GetAttachedVertices()...))
+	}
+	assert.Nil(recorder.Flush())
+
+	for step, expectedVertices := range expected {
+		replayed, err := trace.Replay(strings.NewReader(buffer.String()), vertices, step)
+		assert.Nil(err)
+		assert.Equal(step, replayed.Step)
+		assert.Equal(expectedVertices, replayed.GetAttachedVertices())
+		assert.Len(replayed.Unattached, len(vertices)-len(expectedVertices))
+	}
+
+	replayed, err := trace.Replay(strings.NewReader(buffer.String()), vertices, -1)
+	assert.Nil(err)
+	assert.Equal(len(expected)-1, replayed.Step)
+	assert.Equal(c.GetAttachedVertices(), replayed.GetAttachedVertices())
+
+	replayed, err = trace.Replay(strings.NewReader(buffer.String()), vertices, len(expected)+10)
+	assert.Nil(err)
+	assert.Equal(len(expected)-1, replayed.Step)
+}
+
+func TestReplay_ShouldContinueWithAnotherAlgorithm(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := model2d.DeduplicateVertices(model2d.GenerateVertices(30))
+	var buffer bytes.Buffer
+	recorder := trace.NewRecorder(&buffer, vertices)
+	solver.FindShortestPathCircuit(recorder.Circuit(circuit.NewClosestGreedy(append([]model.CircuitVertex{}, vertices...), recorder.PerimeterBuilder(model2d.BuildPerimiter), false)))
+	assert.Nil(recorder.Flush())
+
+	replayed, err := trace.Replay(strings.NewReader(buffer.String()), vertices, 3)
+	assert.Nil(err)
+	numUnattached := len(replayed.Unattached)
+
+	c := circuit.NewDisparityGreedy(vertices, replayed.PerimeterBuilder(), false)
+	assert.Len(c.GetUnattachedVertices(), numUnattached)
+	solver.FindShortestPathCircuit(c)
+	assert.Len(c.GetAttachedVertices(), len(vertices))
+
+	// The replayed state must not be modified by the other algorithm.
+	assert.Len(replayed.Unattached, numUnattached)
+}
+
+func TestReplay_ShouldReturnErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := createTraceVertices()
+
+	testCases := []struct {
+		trace    string
+		expected string
+	}{
+		{"trace v2\n", `line 1: expected "trace v1"`},
+		{"trace v1\nvertices 7\n", "line 2: the trace has 7 vertices, but 8 vertices were supplied"},
+		{"trace v1\nvertices 8\nperimeter 0 2 8\n", "line 3: vertex index 8 is out of range [0,8)"},
+		{"trace v1\nvertices 8\nperimeter 0 2 a\n", `line 3: invalid integer "a"`},
+		{"trace v1\nvertices 8\nedges 0 2\n", `line 3: unrecognized entry "edges"`},
+		{"trace v1\nvertices 8\nperimeter 0 2 6 4 7\nunattached 1 3 5\nstep 2 5 2 6\n", "line 5: expected step 1"},
+		{"trace v1\nvertices 8\nperimeter 0 2 6 4 7\nunattached 1 3 5\nstep 1 5 -\n", "line 5: step 1 does not include an edge, so it cannot be replayed"},
+		{"trace v1\nvertices 8\nperimeter 0 2 6 4 7\nunattached 1 3 5\nstep 1 5 2 4\n", "line 5: step 1 splits edge 2->4, which is not in the circuit"},
+		{"trace v1\nvertices 8\nperimeter 0 2 6 4 7\nunattached 1 3 5\nstep 1 6 2 1\n", "line 5: step 1 moves vertex 6 to edge 2->1, but either is not in the circuit"},
+	}
+
+	for _, tc := range testCases {
+		replayed, err := trace.Replay(strings.NewReader(tc.trace), vertices, -1)
+		assert.Nil(replayed, tc.trace)
+		assert.EqualError(err, tc.expected, tc.trace)
+	}
+}
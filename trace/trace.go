@@ -0,0 +1,153 @@
+// Package trace records the steps taken by a model.Circuit while it is solved, and replays those steps to rebuild the circuit at any point in the trace.
+//
+// Traces are line-based text, and reference vertices by their index in the vertices supplied to the Recorder, so that traces of different algorithms on the same input can be compared with standard diff tools:
+//
+//	trace v1
+//	vertices <number of vertices>
+//	perimeter <index of each perimeter vertex, in circuit order>
+//	unattached <index of each unattached vertex, in ascending order>
+//	step <step number> <vertex index> <edge start index> <edge end index>
+//	...
+//
+// Steps that do not supply an edge (e.g. SimulatedAnnealing and GeneticAlgorithm) record "-" instead of the edge's indices.
+package trace
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/heustis/tsp-solver-go/model"
+)
+
+const formatHeader = "trace v1"
+
+// Recorder writes the perimeter and steps of a circuit to a trace.
+// Typical usage wraps both the perimeter builder and the circuit:
+//
+//	recorder := trace.NewRecorder(file, vertices)
+//	c := recorder.Circuit(circuit.NewClosestGreedy(vertices, recorder.PerimeterBuilder(model2d.BuildPerimiter), false))
+//	solver.FindShortestPathCircuit(c)
+//	err := recorder.Flush()
+type Recorder struct {
+	err      error
+	indices  map[model.CircuitVertex]int
+	numSteps int
+	writer   *bufio.Writer
+}
+
+// NewRecorder creates a Recorder that writes to the supplied writer, referencing vertices by their index in the supplied array.
+// The array is copied, so it is safe for algorithms to reorder the supplied array after the Recorder is created.
+func NewRecorder(w io.Writer, vertices []model.CircuitVertex) *Recorder {
+	r := &Recorder{
+		indices: make(map[model.CircuitVertex]int, len(vertices)),
+		writer:  bufio.NewWriter(w),
+	}
+	for i, v := range vertices {
+		if _, isDuplicate := r.indices[v]; !isDuplicate {
+			r.indices[v] = i
+		}
+	}
+	r.writeLine(formatHeader)
+	r.writeLine("vertices " + strconv.Itoa(len(vertices)))
+	return r
+}
+
+// Circuit wraps the supplied circuit so that each call to Update is recorded as a step in the trace.
+// Only the methods of model.Circuit are available on the returned circuit.
+func (r *Recorder) Circuit(c model.Circuit) model.Circuit {
+	return &tracedCircuit{
+		Circuit:  c,
+		recorder: r,
+	}
+}
+
+// Flush writes any buffered data to the underlying writer, and returns the first error that occurred while recording the trace.
+func (r *Recorder) Flush() error {
+	if r.err == nil {
+		r.err = r.writer.Flush()
+	}
+	return r.err
+}
+
+// PerimeterBuilder wraps the supplied perimeter builder so that the perimeter and unattached vertices it produces are recorded in the trace.
+func (r *Recorder) PerimeterBuilder(perimeterBuilder model.PerimeterBuilder) model.PerimeterBuilder {
+	return func(vertices []model.CircuitVertex) ([]model.CircuitEdge, map[model.CircuitVertex]bool) {
+		edges, unattached := perimeterBuilder(vertices)
+
+		perimeter := make([]string, 0, len(edges)+1)
+		perimeter = append(perimeter, "perimeter")
+		for _, e := range edges {
+			perimeter = append(perimeter, r.indexOf(e.GetStart()))
+		}
+		r.writeLine(strings.Join(perimeter, " "))
+
+		unattachedIndices := make([]int, 0, len(unattached))
+		for v := range unattached {
+			if index, okay := r.indices[v]; okay {
+				unattachedIndices = append(unattachedIndices, index)
+			} else {
+				r.setError(fmt.Errorf("vertex %v is not in the traced vertices", v))
+			}
+		}
+		sort.Ints(unattachedIndices)
+		unattachedLine := make([]string, 0, len(unattachedIndices)+1)
+		unattachedLine = append(unattachedLine, "unattached")
+		for _, index := range unattachedIndices {
+			unattachedLine = append(unattachedLine, strconv.Itoa(index))
+		}
+		r.writeLine(strings.Join(unattachedLine, " "))
+
+		return edges, unattached
+	}
+}
+
+func (r *Recorder) indexOf(v model.CircuitVertex) string {
+	if index, okay := r.indices[v]; okay {
+		return strconv.Itoa(index)
+	}
+	r.setError(fmt.Errorf("vertex %v is not in the traced vertices", v))
+	return "?"
+}
+
+func (r *Recorder) recordStep(vertex model.CircuitVertex, edge model.CircuitEdge) {
+	r.numSteps++
+	step := "step " + strconv.Itoa(r.numSteps) + " " + r.indexOf(vertex)
+	if edge == nil {
+		step += " -"
+	} else {
+		step += " " + r.indexOf(edge.GetStart()) + " " + r.indexOf(edge.GetEnd())
+	}
+	r.writeLine(step)
+}
+
+func (r *Recorder) setError(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *Recorder) writeLine(line string) {
+	if r.err != nil {
+		return
+	}
+	if _, err := r.writer.WriteString(line + "\n"); err != nil {
+		r.setError(err)
+	}
+}
+
+// tracedCircuit records each update to the circuit before delegating it to the wrapped circuit.
+type tracedCircuit struct {
+	model.Circuit
+	recorder *Recorder
+}
+
+func (c *tracedCircuit) Update(vertexToAdd model.CircuitVertex, edgeToSplit model.CircuitEdge) {
+	c.recorder.recordStep(vertexToAdd, edgeToSplit)
+	c.Circuit.Update(vertexToAdd, edgeToSplit)
+}
+
+var _ model.Circuit = (*tracedCircuit)(nil)
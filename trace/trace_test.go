@@ -0,0 +1,119 @@
+package trace_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/model2d"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/heustis/tsp-solver-go/trace"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTraceVertices() []model.CircuitVertex {
+	return []model.CircuitVertex{
+		model2d.NewVertex2D(-15, -15),
+		model2d.NewVertex2D(0, 0),
+		model2d.NewVertex2D(15, -15),
+		model2d.NewVertex2D(3, 0),
+		model2d.NewVertex2D(3, 13),
+		model2d.NewVertex2D(8, 5),
+		model2d.NewVertex2D(9, 6),
+		model2d.NewVertex2D(-7, 6),
+	}
+}
+
+func TestRecorder_ClosestGreedy(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := createTraceVertices()
+	var buffer bytes.Buffer
+	recorder := trace.NewRecorder(&buffer, vertices)
+	c := recorder.Circuit(circuit.NewClosestGreedy(append([]model.CircuitVertex{}, vertices...), recorder.PerimeterBuilder(model2d.BuildPerimiter), false))
+	solver.FindShortestPathCircuit(c)
+
+	assert.Nil(recorder.Flush())
+	assert.Equal(`trace v1
+vertices 8
+perimeter 0 2 6 4 7
+unattached 1 3 5
+step 1 5 2 6
+step 2 3 2 5
+step 3 1 2 3
+`, buffer.String())
+	assert.Len(c.GetAttachedVertices(), 8)
+	assert.Len(c.GetUnattachedVertices(), 0)
+}
+
+func TestRecorder_DisparityGreedy(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := createTraceVertices()
+	var buffer bytes.Buffer
+	recorder := trace.NewRecorder(&buffer, vertices)
+	c := recorder.Circuit(circuit.NewDisparityGreedy(append([]model.CircuitVertex{}, vertices...), recorder.PerimeterBuilder(model2d.BuildPerimiter), false))
+	solver.FindShortestPathCircuit(c)
+
+	assert.Nil(recorder.Flush())
+	// The perimeter matches the ClosestGreedy trace, so only the steps differ.
+	assert.Equal(`trace v1
+vertices 8
+perimeter 0 2 6 4 7
+unattached 1 3 5
+step 1 3 2 6
+step 2 5 3 6
+step 3 1 2 3
+`, buffer.String())
+}
+
+func TestRecorder_SimulatedAnnealing(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := createTraceVertices()
+	var buffer bytes.Buffer
+	recorder := trace.NewRecorder(&buffer, vertices)
+	c := recorder.Circuit(circuit.NewSimulatedAnnealing(append([]model.CircuitVertex{}, vertices...), 2, false))
+	solver.FindShortestPathCircuit(c)
+
+	assert.Nil(recorder.Flush())
+	// SimulatedAnnealing neither builds a perimeter nor supplies edges to Update.
+	lines := strings.Split(buffer.String(), "\n")
+	assert.Len(lines, 5)
+	assert.Equal("trace v1", lines[0])
+	assert.Equal("vertices 8", lines[1])
+	assert.Equal("step 1 0 -", lines[2])
+	assert.Regexp(`^step 2 \d -$`, lines[3])
+}
+
+func TestRecorder_ShouldReportUnknownVertices(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := createTraceVertices()
+	var buffer bytes.Buffer
+	recorder := trace.NewRecorder(&buffer, vertices[1:])
+	recorder.PerimeterBuilder(model2d.BuildPerimiter)(vertices)
+
+	assert.EqualError(recorder.Flush(), `vertex {"x":-15,"y":-15} is not in the traced vertices`)
+}
+
+type failingWriter struct{}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestRecorder_ShouldReportWriteErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	vertices := createTraceVertices()
+	recorder := trace.NewRecorder(&failingWriter{}, vertices)
+	c := recorder.Circuit(circuit.NewClosestGreedy(vertices, recorder.PerimeterBuilder(model2d.BuildPerimiter), false))
+	solver.FindShortestPathCircuit(c)
+
+	assert.EqualError(recorder.Flush(), "disk full")
+	assert.Len(c.GetUnattachedVertices(), 0)
+}
@@ -0,0 +1,100 @@
+package tsplib_test
+
+import (
+	"testing"
+
+	"github.com/heustis/tsp-solver-go/circuit"
+	"github.com/heustis/tsp-solver-go/model"
+	"github.com/heustis/tsp-solver-go/solver"
+	"github.com/heustis/tsp-solver-go/tsplib"
+)
+
+// usa13509OptimalLength is the length of the optimal circuit through usa13509, which does not have an ".opt.tour" file in the test data.
+const usa13509OptimalLength = 19982859.0
+
+// BenchmarkUsa13509 compares the constructions (and local searches) that are fast enough for large data sets, reporting the length of each circuit as a percentage above the optimal length.
+// Run with: go test ./tsplib -run ^$ -bench Usa13509 -benchtime 3x
+func BenchmarkUsa13509(b *testing.B) {
+	data, err := tsplib.NewData("../test-data/tsplib/usa13509.tsp")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	constructions := []struct {
+		name        string
+		circuitFunc func([]model.CircuitVertex) model.Circuit
+	}{
+		{"Hilbert", func(cv []model.CircuitVertex) model.Circuit { return circuit.NewHilbertCurve(cv) }},
+		{"Morton", func(cv []model.CircuitVertex) model.Circuit { return circuit.NewMortonCurve(cv) }},
+		{"NearestNeighbor", func(cv []model.CircuitVertex) model.Circuit { return circuit.NewNearestNeighbor(cv) }},
+		{"DoubleTree", func(cv []model.CircuitVertex) model.Circuit { return circuit.NewDoubleTree(cv) }},
+		{"GreedyEdge", func(cv []model.CircuitVertex) model.Circuit { return circuit.NewGreedyEdge(cv) }},
+		{"Savings", func(cv []model.CircuitVertex) model.Circuit { return circuit.NewSavings(cv, nil) }},
+		{"GreedyEdge+TwoOpt", func(cv []model.CircuitVertex) model.Circuit {
+			return circuit.NewTwoOptFromCircuit(circuit.NewGreedyEdge(cv), circuit.LocalSearchDefaultNeighbors)
+		}},
+		{"Hilbert+TwoOpt", func(cv []model.CircuitVertex) model.Circuit {
+			return circuit.NewTwoOptFromCircuit(circuit.NewHilbertCurve(cv), circuit.LocalSearchDefaultNeighbors)
+		}},
+		{"GreedyEdge+TwoOpt+OrOpt", func(cv []model.CircuitVertex) model.Circuit {
+			return circuit.NewPipeline(circuit.NewGreedyEdge(cv), func(precursor model.Circuit) model.Circuit {
+				return circuit.NewTwoOptFromCircuit(precursor, circuit.LocalSearchDefaultNeighbors)
+			}, func(precursor model.Circuit) model.Circuit {
+				return circuit.NewOrOptFromCircuit(precursor, circuit.LocalSearchDefaultNeighbors, circuit.OrOptDefaultMaxSegmentLength)
+			})
+		}},
+	}
+
+	for _, construction := range constructions {
+		b.Run(construction.name, func(b *testing.B) {
+			var c model.Circuit
+			for i := 0; i < b.N; i++ {
+				c = construction.circuitFunc(data.GetVertices())
+				solver.FindShortestPathCircuit(c)
+			}
+			b.ReportMetric(100*(c.GetLength()/usa13509OptimalLength-1), "%above_optimal")
+		})
+	}
+}
+
+// BenchmarkOptimalTours compares the local searches on the data sets that have an ".opt.tour" file, reporting the length of each circuit as a percentage above the optimal length.
+// Run with: go test ./tsplib -run ^$ -bench OptimalTours -benchtime 3x
+func BenchmarkOptimalTours(b *testing.B) {
+	improvements := []struct {
+		name        string
+		circuitFunc func([]model.CircuitVertex) model.Circuit
+	}{
+		{"GreedyEdge+TwoOpt+OrOpt", func(cv []model.CircuitVertex) model.Circuit {
+			return circuit.NewPipeline(circuit.NewGreedyEdge(cv), func(precursor model.Circuit) model.Circuit {
+				return circuit.NewTwoOptFromCircuit(precursor, circuit.LocalSearchDefaultNeighbors)
+			}, func(precursor model.Circuit) model.Circuit {
+				return circuit.NewOrOptFromCircuit(precursor, circuit.LocalSearchDefaultNeighbors, circuit.OrOptDefaultMaxSegmentLength)
+			})
+		}},
+		{"GreedyEdge+LinKernighan(0 trials)", func(cv []model.CircuitVertex) model.Circuit {
+			return circuit.NewLinKernighanFromCircuit(circuit.NewGreedyEdge(cv), circuit.LocalSearchDefaultNeighbors, 0)
+		}},
+		{"GreedyEdge+LinKernighan", func(cv []model.CircuitVertex) model.Circuit {
+			c := circuit.NewLinKernighanFromCircuit(circuit.NewGreedyEdge(cv), circuit.LocalSearchDefaultNeighbors, -1)
+			c.SetSeed(1)
+			return c
+		}},
+	}
+
+	for _, name := range []string{"pcb442", "pr2392"} {
+		data, err := tsplib.NewData("../test-data/tsplib/" + name + ".tsp")
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, improvement := range improvements {
+			b.Run(name+"/"+improvement.name, func(b *testing.B) {
+				var c model.Circuit
+				for i := 0; i < b.N; i++ {
+					c = improvement.circuitFunc(data.GetVertices())
+					solver.FindShortestPathCircuit(c)
+				}
+				b.ReportMetric(100*(c.GetLength()/data.GetBestRouteLength()-1), "%above_optimal")
+			})
+		}
+	}
+}